@@ -9,8 +9,10 @@ import (
 	"git.zc0901.com/go/god/api/handler"
 	"git.zc0901.com/go/god/api/internal"
 	"git.zc0901.com/go/god/api/router"
+	"git.zc0901.com/go/god/api/stream"
 	"git.zc0901.com/go/god/lib/codec"
 	"git.zc0901.com/go/god/lib/load"
+	"git.zc0901.com/go/god/lib/proc"
 	"git.zc0901.com/go/god/lib/stat"
 	"github.com/justinas/alice"
 )
@@ -29,11 +31,18 @@ type engine struct {
 	unsignedCallback     handler.UnsignedCallback
 	shedder              load.Shedder
 	priorityShedder      load.Shedder
+	streams              *stream.Group
+	notFound             http.Handler
+	notAllowed           http.Handler
+	statics              []alice.Constructor
 }
 
 // 新建 API 引擎
 func newEngine(c Conf) *engine {
-	e := &engine{conf: c}
+	e := &engine{
+		conf:    c,
+		streams: stream.NewGroup(),
+	}
 
 	// 启用cpu负载均衡
 	if c.CpuThreshold > 0 {
@@ -71,6 +80,10 @@ func (e *engine) StartWithRouter(router router.Router) error {
 	if err := e.bindRoutes(router); err != nil {
 		return err
 	}
+	e.bindFallbacks(router)
+
+	// 先关闭长连接，否则 http.Server.Shutdown 会一直等待 SSE 请求结束
+	proc.AddWrapUpListener(e.streams.Close)
 
 	if len(e.conf.CertFile) == 0 && len(e.conf.KeyFile) == 0 {
 		return internal.StartHttp(e.conf.Host, e.conf.Port, router)
@@ -92,21 +105,48 @@ func (e *engine) bindRoutes(router router.Router) error {
 }
 
 func (e *engine) bindRoute(fr featuredRoutes, router router.Router, metrics *stat.Metrics,
+	route Route, verifier func(chain alice.Chain) alice.Chain) error {
+	if fr.stream {
+		return e.bindStreamRoute(fr, router, metrics, route, verifier)
+	}
+
+	chain := alice.New(
+		handler.TraceHandler,                                      // 链路追踪
+		e.getLogHandler(),                                         // 日志记录
+		handler.PrometheusHandler(route.Path),                     // 请求时长和响应码监控
+		handler.MaxConns(e.conf.MaxConns),                         // 最大请求连接数
+		handler.BreakerHandler(route.Method, route.Path, metrics), // 自动熔断
+		handler.ShedderHandler(e.getShedder(fr), metrics),         // 负载均衡
+		handler.TimeoutHandler(e.getTimeout(fr)),                  // 超时控制
+		handler.RecoverHandler,                                    // 异常捕获
+		handler.MetricHandler(metrics),                            // 耗时监控
+		handler.MaxBytesHandler(e.getMaxBytes(fr)),                // 最大字节码
+		handler.GzipHandler,                                       // Gzip压缩
+	)
+	chain = e.appendAuthHandler(fr, chain, verifier) // JWT鉴权
+	chain = e.appendLimitHandler(fr, chain, metrics) // 分布式限流
+
+	for _, middleware := range e.middlewares {
+		chain = chain.Append(convertMiddleware(middleware)) // 自定义中间件
+	}
+	handle := chain.ThenFunc(route.Handler)
+
+	return router.Handle(route.Method, route.Path, handle)
+}
+
+// bindStreamRoute 绑定 WebSocket、SSE 等长连接路由，
+// 跳过超时、熔断、降载、耗时统计、最大字节和 Gzip 等只适用于短请求的中间件。
+func (e *engine) bindStreamRoute(fr featuredRoutes, router router.Router, metrics *stat.Metrics,
 	route Route, verifier func(chain alice.Chain) alice.Chain) error {
 	chain := alice.New(
-		handler.TraceHandler,                                                   // 链路追踪
-		e.getLogHandler(),                                                      // 日志记录
-		handler.PrometheusHandler(route.Path),                                  // 请求时长和响应码监控
-		handler.MaxConns(e.conf.MaxConns),                                      // 最大请求连接数
-		handler.BreakerHandler(route.Method, route.Path, metrics),              // 自动熔断
-		handler.ShedderHandler(e.getShedder(fr.priority), metrics),             // 负载均衡
-		handler.TimeoutHandler(time.Duration(e.conf.Timeout)*time.Millisecond), // 超时控制
-		handler.RecoverHandler,                                                 // 异常捕获
-		handler.MetricHandler(metrics),                                         // 耗时监控
-		handler.MaxBytesHandler(e.conf.MaxBytes),                               // 最大字节码
-		handler.GzipHandler,                                                    // Gzip压缩
+		handler.TraceHandler,              // 链路追踪
+		handler.StreamLogHandler,          // 日志记录
+		handler.MaxConns(e.conf.MaxConns), // 最大请求连接数
+		handler.RecoverHandler,            // 异常捕获
+		e.streams.Handle,                  // 服务停止时关闭长连接
 	)
 	chain = e.appendAuthHandler(fr, chain, verifier) // JWT鉴权
+	chain = e.appendLimitHandler(fr, chain, metrics) // 分布式限流
 
 	for _, middleware := range e.middlewares {
 		chain = chain.Append(convertMiddleware(middleware)) // 自定义中间件
@@ -116,6 +156,32 @@ func (e *engine) bindRoute(fr featuredRoutes, router router.Router, metrics *sta
 	return router.Handle(route.Method, route.Path, handle)
 }
 
+// bindFallbacks 设置资源未找到和不允许访问处理器，
+// 未匹配任何路由的请求先尝试静态文件，静态文件不存在时再交给资源未找到处理器。
+func (e *engine) bindFallbacks(router router.Router) {
+	if e.notAllowed != nil {
+		router.SetNotAllowedHandler(e.notAllowed)
+	}
+
+	if len(e.statics) == 0 {
+		if e.notFound != nil {
+			router.SetNotFoundHandler(e.notFound)
+		}
+		return
+	}
+
+	notFound := e.notFound
+	if notFound == nil {
+		notFound = http.NotFoundHandler()
+	}
+	chain := alice.New(
+		handler.TraceHandler,   // 链路追踪
+		e.getLogHandler(),      // 日志记录
+		handler.RecoverHandler, // 异常捕获
+	).Append(e.statics...)
+	router.SetNotFoundHandler(chain.Then(notFound))
+}
+
 // 创建 API 引擎统计指标。
 func (e *engine) createMetrics() *stat.Metrics {
 	var metrics *stat.Metrics
@@ -188,19 +254,37 @@ func (e *engine) signatureVerifier(signature signatureSetting) (func(chain alice
 // 添加JWT鉴权中间件
 func (e *engine) appendAuthHandler(fr featuredRoutes, chain alice.Chain, verifier func(chain alice.Chain) alice.Chain) alice.Chain {
 	if fr.jwt.enabled {
-		if len(fr.jwt.prevSecret) == 0 {
-			chain = chain.Append(handler.Authorize(fr.jwt.secret,
-				handler.WithUnauthorizedCallback(e.unauthorizedCallback)))
-		} else {
-			chain = chain.Append(handler.Authorize(fr.jwt.secret,
-				handler.WithPrevSecret(fr.jwt.prevSecret),
-				handler.WithUnauthorizedCallback(e.unauthorizedCallback)))
+		opts := []handler.AuthorizeOption{
+			handler.WithUnauthorizedCallback(e.unauthorizedCallback),
+			handler.WithAudience(fr.jwt.audiences...),
+			handler.WithIssuer(fr.jwt.issuers...),
+			handler.WithClockSkew(fr.jwt.clockSkew),
+			handler.WithRevokedCheck(fr.jwt.revoked),
+		}
+		if len(fr.jwt.prevSecret) > 0 {
+			opts = append(opts, handler.WithPrevSecret(fr.jwt.prevSecret))
 		}
+		if fr.jwt.keys != nil {
+			opts = append(opts, handler.WithKeys(fr.jwt.keys))
+		}
+		chain = chain.Append(handler.Authorize(fr.jwt.secret, opts...))
 	}
 
 	return verifier(chain)
 }
 
+// 添加分布式限流中间件，放在鉴权之后以便按用户限流
+func (e *engine) appendLimitHandler(fr featuredRoutes, chain alice.Chain, metrics *stat.Metrics) alice.Chain {
+	if fr.limit.period != nil {
+		chain = chain.Append(handler.PeriodLimitHandler(fr.limit.period, fr.limit.keyFunc, metrics))
+	}
+	if fr.limit.token != nil {
+		chain = chain.Append(handler.TokenLimitHandler(fr.limit.token, metrics))
+	}
+
+	return chain
+}
+
 // 获取日志记录器
 func (e *engine) getLogHandler() alice.Constructor {
 	if e.conf.Verbose {
@@ -211,14 +295,35 @@ func (e *engine) getLogHandler() alice.Constructor {
 }
 
 // 获取负载均衡泄流器
-func (e *engine) getShedder(priority bool) load.Shedder {
-	if priority && e.priorityShedder != nil {
+func (e *engine) getShedder(fr featuredRoutes) load.Shedder {
+	if fr.noShedding {
+		return nil
+	}
+	if fr.priority && e.priorityShedder != nil {
 		return e.priorityShedder
 	}
 
 	return e.shedder
 }
 
+// 获取路由超时时间，路由未指定时使用全局配置
+func (e *engine) getTimeout(fr featuredRoutes) time.Duration {
+	if fr.timeout != nil {
+		return *fr.timeout
+	}
+
+	return time.Duration(e.conf.Timeout) * time.Millisecond
+}
+
+// 获取路由最大请求字节数，路由未指定时使用全局配置
+func (e *engine) getMaxBytes(fr featuredRoutes) int64 {
+	if fr.maxBytes != nil {
+		return *fr.maxBytes
+	}
+
+	return e.conf.MaxBytes
+}
+
 func (e *engine) use(middleware Middleware) {
 	e.middlewares = append(e.middlewares, middleware)
 }
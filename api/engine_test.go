@@ -0,0 +1,32 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteOverrides(t *testing.T) {
+	e := newEngine(Conf{
+		Timeout:      3000,
+		MaxBytes:     1 << 20,
+		CpuThreshold: 900,
+	})
+
+	var fr featuredRoutes
+	assert.Equal(t, 3*time.Second, e.getTimeout(fr))
+	assert.Equal(t, int64(1<<20), e.getMaxBytes(fr))
+	assert.Equal(t, e.shedder, e.getShedder(fr))
+
+	for _, opt := range []RouteOption{WithTimeout(time.Minute), WithMaxBytes(0), WithoutShedding()} {
+		opt(&fr)
+	}
+	assert.Equal(t, time.Minute, e.getTimeout(fr))
+	assert.Equal(t, int64(0), e.getMaxBytes(fr))
+	assert.Nil(t, e.getShedder(fr))
+
+	fr = featuredRoutes{}
+	WithPriority()(&fr)
+	assert.Equal(t, e.priorityShedder, e.getShedder(fr))
+}
@@ -0,0 +1,67 @@
+package api
+
+import "path"
+
+// Group 路由组，组内路由共享路径前缀、路由选项（JWT、签名、优先级等）和中间件，
+// 子路由组继承父路由组创建时的全部设置。
+type Group struct {
+	server      *Server
+	prefix      string
+	opts        []RouteOption
+	middlewares []Middleware
+}
+
+// Group 新建路径前缀为 prefix 的路由组，opts 应用于组内所有路由
+func (s *Server) Group(prefix string, opts ...RouteOption) *Group {
+	return &Group{
+		server: s,
+		prefix: joinPath("/", prefix),
+		opts:   opts,
+	}
+}
+
+// Group 新建子路由组，路径前缀拼接在父路由组之后，父路由组的选项先于 opts 应用
+func (g *Group) Group(prefix string, opts ...RouteOption) *Group {
+	return &Group{
+		server:      g.server,
+		prefix:      joinPath(g.prefix, prefix),
+		opts:        append(append([]RouteOption(nil), g.opts...), opts...),
+		middlewares: append([]Middleware(nil), g.middlewares...),
+	}
+}
+
+// Use 添加组内中间件，按添加顺序执行，只对之后添加的路由和子路由组生效
+func (g *Group) Use(middlewares ...Middleware) *Group {
+	g.middlewares = append(g.middlewares, middlewares...)
+	return g
+}
+
+// Prefix 返回路由组的路径前缀
+func (g *Group) Prefix() string {
+	return g.prefix
+}
+
+// AddRoutes 添加一批组内路由，路径相对于路由组前缀，opts 在路由组的选项之后应用
+func (g *Group) AddRoutes(rs []Route, opts ...RouteOption) {
+	routes := make([]Route, len(rs))
+	for i, r := range rs {
+		routes[i] = Route{
+			Method:  r.Method,
+			Path:    joinPath(g.prefix, r.Path),
+			Handler: r.Handler,
+		}
+	}
+	routes = WithMiddlewares(g.middlewares, routes...)
+
+	g.server.AddRoutes(routes, append(append([]RouteOption(nil), g.opts...), opts...)...)
+}
+
+// AddRoute 添加一个组内路由
+func (g *Group) AddRoute(r Route, opts ...RouteOption) {
+	g.AddRoutes([]Route{r}, opts...)
+}
+
+// joinPath 拼接路径前缀，保证以 / 开头且不以 / 结尾（根路径除外）
+func joinPath(prefix, p string) string {
+	return path.Join("/", prefix, p)
+}
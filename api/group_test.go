@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	server := &Server{engine: newEngine(Conf{})}
+	var trace []string
+	middleware := func(name string) Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				trace = append(trace, name)
+				next(w, r)
+			}
+		}
+	}
+	handle := func(w http.ResponseWriter, r *http.Request) {
+		trace = append(trace, "handler")
+	}
+
+	v1 := server.Group("api/v1/", WithJwt("12345678"), WithPriority()).Use(middleware("v1"))
+	v1.AddRoute(Route{Method: http.MethodGet, Path: "/users", Handler: handle})
+
+	admin := v1.Group("/admin", WithJwtIssuer("god")).Use(middleware("admin"))
+	admin.AddRoutes([]Route{
+		{Method: http.MethodGet, Path: "/", Handler: handle},
+		{Method: http.MethodPost, Path: "/users/:id", Handler: handle},
+	}, WithSignature(SignatureConf{Strict: true}))
+
+	// 子路由组的中间件不影响父路由组
+	v1.AddRoute(Route{Method: http.MethodGet, Path: "/items", Handler: handle})
+
+	routes := server.engine.routes
+	assert.Len(t, routes, 3)
+	assert.Equal(t, "/api/v1", v1.Prefix())
+	assert.Equal(t, "/api/v1/admin", admin.Prefix())
+
+	assert.Equal(t, "/api/v1/users", routes[0].routes[0].Path)
+	assert.True(t, routes[0].jwt.enabled)
+	assert.True(t, routes[0].priority)
+	assert.Empty(t, routes[0].jwt.issuers)
+
+	assert.Equal(t, "/api/v1/admin", routes[1].routes[0].Path)
+	assert.Equal(t, "/api/v1/admin/users/:id", routes[1].routes[1].Path)
+	assert.Equal(t, "12345678", routes[1].jwt.secret)
+	assert.True(t, routes[1].priority)
+	assert.Equal(t, []string{"god"}, routes[1].jwt.issuers)
+	assert.True(t, routes[1].signature.enabled)
+	assert.False(t, routes[0].signature.enabled)
+
+	routes[1].routes[1].Handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, []string{"v1", "admin", "handler"}, trace)
+
+	trace = nil
+	routes[2].routes[0].Handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, []string{"v1", "handler"}, trace)
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"time"
 
 	"git.zc0901.com/go/god/api/token"
 	"git.zc0901.com/go/god/lib/logx"
@@ -25,16 +26,27 @@ const (
 )
 
 var (
-	errInvalidToken = errors.New("无效的鉴权令牌")
-	errNoClaims     = errors.New("鉴权参数未提供")
+	errInvalidToken    = errors.New("无效的鉴权令牌")
+	errNoClaims        = errors.New("鉴权参数未提供")
+	errInvalidAudience = errors.New("令牌受众不匹配")
+	errInvalidIssuer   = errors.New("令牌签发者不匹配")
+	errRevokedToken    = errors.New("令牌已被吊销")
 )
 
 type (
 	AuthorizeOptions struct {
 		PrevSecret string
 		Callback   UnauthorizedCallback
+		Keys       token.KeyProvider // 非空时使用公钥验证非对称算法签名的令牌，忽略秘钥
+		Audiences  []string          // 允许的受众，令牌 aud 包含其一即可
+		Issuers    []string          // 允许的签发者
+		ClockSkew  time.Duration     // 校验令牌时间时允许的时钟偏差
+		Revoked    RevokedCheck      // 检查令牌 jti 是否已被吊销
 	}
 
+	// RevokedCheck 检查令牌 jti 是否已被吊销，如 token.Issuer.IsRevoked
+	RevokedCheck func(jti string) (bool, error)
+
 	UnauthorizedCallback func(w http.ResponseWriter, r *http.Request, err error)
 	AuthorizeOption      func(opts *AuthorizeOptions)
 )
@@ -46,10 +58,16 @@ func Authorize(secret string, opts ...AuthorizeOption) func(http.Handler) http.H
 		opt(&authOpts)
 	}
 
-	parser := token.NewTokenParser()
+	parser := token.NewTokenParser(token.WithLeeway(authOpts.ClockSkew))
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			jwtToken, err := parser.ParseToken(r, secret, authOpts.PrevSecret)
+			var jwtToken *jwt.Token
+			var err error
+			if authOpts.Keys != nil {
+				jwtToken, err = parser.ParseTokenWithKeys(r, authOpts.Keys)
+			} else {
+				jwtToken, err = parser.ParseToken(r, secret, authOpts.PrevSecret)
+			}
 			if err != nil {
 				unauthorized(w, r, err, authOpts.Callback)
 				return
@@ -66,6 +84,11 @@ func Authorize(secret string, opts ...AuthorizeOption) func(http.Handler) http.H
 				return
 			}
 
+			if err = verifyClaims(claims, authOpts); err != nil {
+				unauthorized(w, r, err, authOpts.Callback)
+				return
+			}
+
 			ctx := r.Context()
 			for k, v := range claims {
 				switch k {
@@ -93,6 +116,95 @@ func WithUnauthorizedCallback(callback UnauthorizedCallback) AuthorizeOption {
 	}
 }
 
+// WithKeys 使用公钥（PEM 或 JWKS）验证 RS256、ES256、EdDSA 等算法签名的令牌
+func WithKeys(keys token.KeyProvider) AuthorizeOption {
+	return func(opts *AuthorizeOptions) {
+		opts.Keys = keys
+	}
+}
+
+// WithAudience 要求令牌的 aud 包含指定受众之一
+func WithAudience(audiences ...string) AuthorizeOption {
+	return func(opts *AuthorizeOptions) {
+		opts.Audiences = append(opts.Audiences, audiences...)
+	}
+}
+
+// WithIssuer 要求令牌的 iss 为指定签发者之一
+func WithIssuer(issuers ...string) AuthorizeOption {
+	return func(opts *AuthorizeOptions) {
+		opts.Issuers = append(opts.Issuers, issuers...)
+	}
+}
+
+// WithClockSkew 自定义校验 exp、nbf、iat 时允许的时钟偏差
+func WithClockSkew(skew time.Duration) AuthorizeOption {
+	return func(opts *AuthorizeOptions) {
+		opts.ClockSkew = skew
+	}
+}
+
+// WithRevokedCheck 拒绝 jti 已被吊销的令牌
+func WithRevokedCheck(check RevokedCheck) AuthorizeOption {
+	return func(opts *AuthorizeOptions) {
+		opts.Revoked = check
+	}
+}
+
+func verifyClaims(claims jwt.MapClaims, opts AuthorizeOptions) error {
+	if len(opts.Audiences) > 0 && !containsAny(claimStrings(claims[jwtAudience]), opts.Audiences) {
+		return errInvalidAudience
+	}
+	if len(opts.Issuers) > 0 && !containsAny(claimStrings(claims[jwtIssuer]), opts.Issuers) {
+		return errInvalidIssuer
+	}
+
+	// 没有 jti 的令牌无法吊销；检查出错时拒绝访问，宁可误拒也不放行已吊销的令牌
+	if jti, ok := claims[jwtId].(string); ok && len(jti) > 0 && opts.Revoked != nil {
+		revoked, err := opts.Revoked(jti)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return errRevokedToken
+		}
+	}
+
+	return nil
+}
+
+// claimStrings 获取字符串或字符串数组类型的声明，如 aud
+func claimStrings(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var vals []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				vals = append(vals, s)
+			}
+		}
+		return vals
+	default:
+		return nil
+	}
+}
+
+func containsAny(vals, expected []string) bool {
+	for _, val := range vals {
+		for _, e := range expected {
+			if val == e {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func detailAuthLog(r *http.Request, reason string) {
 	// discard dump error, only for debug purpose
 	details, _ := httputil.DumpRequest(r, true)
@@ -1,10 +1,14 @@
 package handler
 
 import (
-	"github.com/stretchr/testify/assert"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAuthHandlerFailed(t *testing.T) {
@@ -41,3 +45,94 @@ func TestAuthHandlerFailed2(t *testing.T) {
 	handler.ServeHTTP(resp, req)
 	assert.Equal(t, http.StatusUnauthorized, resp.Code)
 }
+
+func TestAuthHandlerClaims(t *testing.T) {
+	const secret = "B63F477D-BBA3-4E52-96D3-C0034C27694A"
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		code   int
+	}{
+		{
+			name:   "ok",
+			claims: jwt.MapClaims{"aud": "api", "iss": "idp", "exp": time.Now().Add(time.Hour).Unix()},
+			code:   http.StatusOK,
+		},
+		{
+			name:   "audience array",
+			claims: jwt.MapClaims{"aud": []string{"web", "api"}, "iss": "idp"},
+			code:   http.StatusOK,
+		},
+		{
+			name:   "within clock skew",
+			claims: jwt.MapClaims{"aud": "api", "iss": "idp", "exp": time.Now().Add(-5 * time.Second).Unix()},
+			code:   http.StatusOK,
+		},
+		{
+			name:   "expired",
+			claims: jwt.MapClaims{"aud": "api", "iss": "idp", "exp": time.Now().Add(-time.Hour).Unix()},
+			code:   http.StatusUnauthorized,
+		},
+		{
+			name:   "wrong audience",
+			claims: jwt.MapClaims{"aud": "web", "iss": "idp"},
+			code:   http.StatusUnauthorized,
+		},
+		{
+			name:   "wrong issuer",
+			claims: jwt.MapClaims{"aud": "api", "iss": "other"},
+			code:   http.StatusUnauthorized,
+		},
+	}
+
+	handler := Authorize(secret, WithAudience("api"), WithIssuer("idp"), WithClockSkew(time.Minute))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, test.claims).SignedString([]byte(secret))
+			assert.Nil(t, err)
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+			assert.Equal(t, test.code, resp.Code)
+		})
+	}
+}
+
+func TestAuthHandlerRevoked(t *testing.T) {
+	const secret = "B63F477D-BBA3-4E52-96D3-C0034C27694A"
+	handler := Authorize(secret, WithRevokedCheck(func(jti string) (bool, error) {
+		switch jti {
+		case "revoked":
+			return true, nil
+		case "error":
+			return false, errors.New("redis down")
+		default:
+			return false, nil
+		}
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for jti, code := range map[string]int{
+		"":        http.StatusOK,
+		"valid":   http.StatusOK,
+		"revoked": http.StatusUnauthorized,
+		"error":   http.StatusUnauthorized,
+	} {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"jti": jti}).
+			SignedString([]byte(secret))
+		assert.Nil(t, err)
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, code, resp.Code, jti)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"git.zc0901.com/go/god/api/httpx"
+	"git.zc0901.com/go/god/api/internal"
+	"git.zc0901.com/go/god/lib/limit"
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/stat"
+)
+
+// LimitKeyFunc 从请求中提取限流键，如用户ID或客户端IP
+type LimitKeyFunc func(r *http.Request) string
+
+// PeriodLimitHandler API 周期限流中间件，超出配额返回 429。
+// keyFunc 为空时按客户端地址限流，限流器出错时放行。
+func PeriodLimitHandler(limiter *limit.PeriodLimit, keyFunc LimitKeyFunc,
+	metrics *stat.Metrics) func(http.Handler) http.Handler {
+	if limiter == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	if keyFunc == nil {
+		keyFunc = httpx.GetRemoteAddr
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			code, err := limiter.Take(keyFunc(r))
+			if err != nil {
+				logx.Errorf("[http] 周期限流器出错，放行请求：%s", err)
+			} else if code == limit.OverQuota {
+				metrics.AddDrop()
+				internal.Errorf(r, "超出限流配额，错误码：%d", http.StatusTooManyRequests)
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TokenLimitHandler API 令牌桶限流中间件，令牌不足返回 429。
+func TokenLimitHandler(limiter *limit.TokenLimiter, metrics *stat.Metrics) func(http.Handler) http.Handler {
+	if limiter == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.Allow() {
+				metrics.AddDrop()
+				internal.Errorf(r, "令牌桶限流，错误码：%d", http.StatusTooManyRequests)
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
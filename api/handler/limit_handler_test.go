@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.zc0901.com/go/god/lib/limit"
+	"git.zc0901.com/go/god/lib/stat"
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodLimitHandler(t *testing.T) {
+	store, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	limiter := limit.NewPeriodLimit(60, 2, store, "api:limit:")
+	handler := PeriodLimitHandler(limiter, func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	}, stat.NewMetrics("limit"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	codes := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.Header.Set("X-User", "kevin")
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		codes = append(codes, resp.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("X-User", "other")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestTokenLimitHandler(t *testing.T) {
+	store, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	limiter := limit.NewTokenLimiter(1, 1, store, "api:token")
+	handler := TokenLimitHandler(limiter, stat.NewMetrics("limit"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
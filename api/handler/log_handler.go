@@ -74,6 +74,24 @@ func LogHandler(next http.Handler) http.Handler {
 	})
 }
 
+// StreamLogHandler API 长连接日志记录中间件，连接建立和断开时各记录一次，长连接不记为慢调用
+func StreamLogHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timer := utils.NewElapsedTimer()
+		lrw := loggedResponseWriter{
+			w:    w,
+			r:    r,
+			code: http.StatusOK,
+		}
+
+		logx.WithContext(r.Context()).Infof("[HTTP] 长连接建立 - %s - %s - %s",
+			r.RequestURI, httpx.GetRemoteAddr(r), r.UserAgent())
+		next.ServeHTTP(&lrw, r)
+		logx.WithContext(r.Context()).Infof("[HTTP] 长连接断开 - %d - %s - %s - %s",
+			lrw.code, r.RequestURI, httpx.GetRemoteAddr(r), timex.ReprOfDuration(timer.Duration()))
+	})
+}
+
 // DetailedLogHandler API 详细日志记录中间件
 func DetailedLogHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
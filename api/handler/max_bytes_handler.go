@@ -4,9 +4,11 @@ import (
 	"net/http"
 
 	"git.zc0901.com/go/god/api/internal"
+	"git.zc0901.com/go/god/api/internal/context"
 )
 
-// MaxBytesHandler API 最大请求字节中间件
+// MaxBytesHandler API 最大请求字节中间件，
+// 同时将限制放入请求上下文，供 httpx 解析分块上传的请求体时使用。
 func MaxBytesHandler(max int64) func(http.Handler) http.Handler {
 	if max <= 0 {
 		return func(next http.Handler) http.Handler {
@@ -20,7 +22,7 @@ func MaxBytesHandler(max int64) func(http.Handler) http.Handler {
 				internal.Errorf(r, "请求实体过大，限制为：%d，接收到：%d，错误码：%d",
 					max, r.ContentLength, http.StatusRequestEntityTooLarge)
 			} else {
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(w, context.WithMaxBytes(r, max))
 			}
 		})
 	}
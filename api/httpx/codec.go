@@ -0,0 +1,281 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"git.zc0901.com/go/god/lib/encoding/gxml"
+	"git.zc0901.com/go/god/lib/gconv"
+	"git.zc0901.com/go/god/lib/jsonx"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	ApplicationXml      = "application/xml"
+	TextXml             = "text/xml"
+	ApplicationForm     = "application/x-www-form-urlencoded"
+	ApplicationProtobuf = "application/x-protobuf"
+	ApplicationMsgpack  = "application/x-msgpack"
+	Accept              = "Accept"
+
+	xmlRootTag = "xml"
+)
+
+var (
+	// ErrUnsupportedBody 编解码器不支持该类型的值，如 protobuf 只支持 proto.Message
+	ErrUnsupportedBody = errors.New("编解码器不支持该类型的值")
+
+	codecs    = make(map[string]Codec)
+	codecLock sync.RWMutex
+)
+
+type (
+	// Codec 请求体和响应体的编解码器，按 Content-Type 解析请求体，按 Accept 编排响应体
+	Codec interface {
+		// ContentType 响应的内容类型
+		ContentType() string
+		// Marshal 编排响应体，不支持 v 的类型时返回 ErrUnsupportedBody
+		Marshal(v interface{}) ([]byte, error)
+		// Unmarshal 将请求体解析为参数，与路径和表单参数合并后转换到 v；
+		// 无法解析为参数的格式（如 protobuf）可直接填充 v 并返回 nil。
+		Unmarshal(data []byte, v interface{}) (map[string]interface{}, error)
+	}
+
+	jsonCodec     struct{}
+	xmlCodec      struct{}
+	formCodec     struct{}
+	protobufCodec struct{}
+	msgpackCodec  struct{}
+
+	acceptRange struct {
+		mediaType string
+		q         float64
+	}
+)
+
+func init() {
+	RegisterCodec(jsonCodec{}, ApplicationJson)
+	RegisterCodec(xmlCodec{}, ApplicationXml, TextXml)
+	RegisterCodec(formCodec{}, ApplicationForm)
+	RegisterCodec(protobufCodec{}, ApplicationProtobuf, "application/protobuf")
+	RegisterCodec(msgpackCodec{}, ApplicationMsgpack, "application/msgpack")
+}
+
+// RegisterCodec 注册编解码器，contentTypes 为空时使用 codec.ContentType()，已注册的类型会被覆盖
+func RegisterCodec(codec Codec, contentTypes ...string) {
+	if len(contentTypes) == 0 {
+		contentTypes = []string{codec.ContentType()}
+	}
+
+	codecLock.Lock()
+	defer codecLock.Unlock()
+	for _, contentType := range contentTypes {
+		codecs[strings.ToLower(contentType)] = codec
+	}
+}
+
+// CodecFor 返回内容类型对应的编解码器，忽略 charset 等参数
+func CodecFor(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	codecLock.RLock()
+	defer codecLock.RUnlock()
+	codec, ok := codecs[mediaType]
+	return codec, ok
+}
+
+// negotiate 按 Accept 请求头选择响应的编解码器，按 q 值从高到低匹配，支持 type/* 和 */*，
+// 没有匹配时使用 JSON。
+func negotiate(accept string) Codec {
+	ranges := parseAccept(accept)
+
+	codecLock.RLock()
+	defer codecLock.RUnlock()
+	for _, ar := range ranges {
+		if codec, ok := codecs[ar.mediaType]; ok {
+			return codec
+		}
+
+		if ar.mediaType == "*/*" {
+			break
+		}
+		if strings.HasSuffix(ar.mediaType, "/*") {
+			if codec := matchWildcard(strings.TrimSuffix(ar.mediaType, "*")); codec != nil {
+				return codec
+			}
+		}
+	}
+
+	return jsonCodec{}
+}
+
+// matchWildcard 按内容类型排序匹配，保证结果稳定
+func matchWildcard(prefix string) Codec {
+	var types []string
+	for t := range codecs {
+		if strings.HasPrefix(t, prefix) {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+
+	sort.Strings(types)
+	return codecs[types[0]]
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+func (jsonCodec) ContentType() string {
+	return ApplicationJson
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, _ interface{}) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := jsonx.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (xmlCodec) ContentType() string {
+	return ApplicationXml
+}
+
+// Marshal 映射使用 gxml 编排，其他类型使用 encoding/xml 编排
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return gxml.Encode(m, xmlRootTag)
+	}
+
+	return xml.Marshal(v)
+}
+
+func (xmlCodec) Unmarshal(data []byte, _ interface{}) (map[string]interface{}, error) {
+	return gxml.DecodeWithoutRoot(data)
+}
+
+func (formCodec) ContentType() string {
+	return ApplicationForm
+}
+
+func (formCodec) Marshal(v interface{}) ([]byte, error) {
+	m := gconv.Map(v)
+	if m == nil {
+		return nil, ErrUnsupportedBody
+	}
+
+	values := make(url.Values, len(m))
+	for k, v := range m {
+		values.Set(k, gconv.String(v))
+	}
+	return []byte(values.Encode()), nil
+}
+
+func (formCodec) Unmarshal(data []byte, _ interface{}) (map[string]interface{}, error) {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{}, len(values))
+	for k := range values {
+		m[k] = values.Get(k)
+	}
+	return m, nil
+}
+
+func (protobufCodec) ContentType() string {
+	return ApplicationProtobuf
+}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrUnsupportedBody
+	}
+
+	return proto.Marshal(msg)
+}
+
+// Unmarshal 直接填充 proto.Message，保留已从路径和表单解析的字段
+func (protobufCodec) Unmarshal(data []byte, v interface{}) (map[string]interface{}, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w：%T 不是 proto.Message", ErrUnsupportedBody, v)
+	}
+
+	return nil, proto.UnmarshalOptions{Merge: true}.Unmarshal(data, msg)
+}
+
+func (msgpackCodec) ContentType() string {
+	return ApplicationMsgpack
+}
+
+// Marshal 与 JSON 保持一致，使用 json 标签命名字段
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	enc := msgpack.GetEncoder()
+	defer msgpack.PutEncoder(enc)
+
+	var buf bytes.Buffer
+	enc.Reset(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, _ interface{}) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := msgpack.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,161 @@
+package httpx
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type codecReq struct {
+	Name string `json:"name" form:"name" v:"required"`
+	Age  int    `json:"age" form:"age"`
+	Id   int    `form:"id"`
+}
+
+func TestParseBody_Codecs(t *testing.T) {
+	packed, err := msgpack.Marshal(map[string]interface{}{"name": "kevin", "age": 18})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		contentType string
+		body        []byte
+	}{
+		{ApplicationJson + "; charset=utf-8", []byte(`{"name":"kevin","age":18}`)},
+		{ApplicationXml, []byte(`<req><name>kevin</name><age>18</age></req>`)},
+		{TextXml, []byte(`<?xml version="1.0" encoding="UTF-8"?><req><name>kevin</name><age>18</age></req>`)},
+		{ApplicationForm, []byte(`name=kevin&age=18`)},
+		{ApplicationMsgpack, packed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.contentType, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "http://localhost/?id=1", bytes.NewReader(test.body))
+			r.Header.Set(ContentType, test.contentType)
+
+			var v codecReq
+			assert.Nil(t, Parse(r, &v))
+			assert.Equal(t, codecReq{Name: "kevin", Age: 18, Id: 1}, v)
+		})
+	}
+}
+
+func TestParseBody_Protobuf(t *testing.T) {
+	body, err := proto.Marshal(wrapperspb.String("kevin"))
+	assert.Nil(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "http://localhost/", bytes.NewReader(body))
+	r.Header.Set(ContentType, ApplicationProtobuf)
+	var v wrapperspb.StringValue
+	assert.Nil(t, Parse(r, &v))
+	assert.Equal(t, "kevin", v.Value)
+
+	r = httptest.NewRequest(http.MethodPost, "http://localhost/", bytes.NewReader(body))
+	r.Header.Set(ContentType, ApplicationProtobuf)
+	var req codecReq
+	assert.True(t, errors.Is(Parse(r, &req), ErrUnsupportedBody))
+}
+
+func TestParseBody_Unknown(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "http://localhost/", strings.NewReader("kevin"))
+	r.Header.Set(ContentType, "text/plain")
+	params, err := ParseBody(r, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, params.Size())
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", ApplicationJson},
+		{"*/*", ApplicationJson},
+		{"text/html", ApplicationJson},
+		{"application/xml", ApplicationXml},
+		{"text/html, application/xml;q=0.9, application/json;q=0.8", ApplicationXml},
+		{"application/json;q=0.5, application/x-msgpack", ApplicationMsgpack},
+		{"application/xml;q=0, application/json", ApplicationJson},
+		{"text/*", ApplicationXml},
+	}
+
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			assert.Equal(t, test.contentType, negotiate(test.accept).ContentType())
+		})
+	}
+}
+
+func TestOkCtx(t *testing.T) {
+	SetOkJsonHandler(func(body interface{}) interface{} {
+		return map[string]interface{}{
+			"code": 0,
+			"data": body,
+		}
+	})
+	defer SetOkJsonHandler(nil)
+
+	r := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+	r.Header.Set(Accept, ApplicationXml)
+	w := httptest.NewRecorder()
+	OkCtx(w, r, "kevin")
+	assert.Equal(t, ApplicationXml, w.Header().Get(ContentType))
+	assert.Equal(t, "<xml><code>0</code><data>kevin</data></xml>", w.Body.String())
+
+	r.Header.Set(Accept, ApplicationMsgpack)
+	w = httptest.NewRecorder()
+	OkCtx(w, r, "kevin")
+	assert.Equal(t, ApplicationMsgpack, w.Header().Get(ContentType))
+	var m map[string]interface{}
+	assert.Nil(t, msgpack.Unmarshal(w.Body.Bytes(), &m))
+	assert.Equal(t, "kevin", m["data"])
+
+	// protobuf 不支持包装后的映射，使用 JSON
+	r.Header.Set(Accept, ApplicationProtobuf)
+	w = httptest.NewRecorder()
+	OkCtx(w, r, "kevin")
+	assert.Equal(t, ApplicationJson, w.Header().Get(ContentType))
+	assert.Equal(t, `{"code":0,"data":"kevin"}`, w.Body.String())
+}
+
+func TestOkCtx_Protobuf(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+	r.Header.Set(Accept, ApplicationProtobuf)
+	w := httptest.NewRecorder()
+	OkCtx(w, r, wrapperspb.String("kevin"))
+	assert.Equal(t, ApplicationProtobuf, w.Header().Get(ContentType))
+
+	var v wrapperspb.StringValue
+	assert.Nil(t, proto.Unmarshal(w.Body.Bytes(), &v))
+	assert.Equal(t, "kevin", v.Value)
+}
+
+func TestErrorCtx(t *testing.T) {
+	type errBody struct {
+		Code int    `json:"code" xml:"code"`
+		Msg  string `json:"msg" xml:"msg"`
+	}
+	SetErrorHandler(func(err error) (int, interface{}) {
+		return http.StatusBadRequest, errBody{Code: 1, Msg: err.Error()}
+	})
+	defer SetErrorHandler(nil)
+
+	r := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+	r.Header.Set(Accept, ApplicationXml)
+	w := httptest.NewRecorder()
+	ErrorCtx(w, r, errors.New("failed"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ApplicationXml, w.Header().Get(ContentType))
+	assert.Equal(t, "<errBody><code>1</code><msg>failed</msg></errBody>", w.Body.String())
+
+	w = httptest.NewRecorder()
+	Error(w, errors.New("failed"))
+	assert.Equal(t, `{"code":1,"msg":"failed"}`, w.Body.String())
+}
@@ -2,6 +2,7 @@ package httpx
 
 import (
 	"io"
+	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -30,8 +31,20 @@ var (
 	formUnmarshaler = mapping.NewUnmarshaler(formKey, mapping.WithStringValues())
 )
 
-// Parse 依次将请求路径、表单和JSON中的参数，解析值目标 v
-func Parse(r *http.Request, pointer interface{}) error {
+// Parse 依次将请求路径、表单和请求体中的参数，解析值目标 v，
+// 请求体按 Content-Type 选择已注册的编解码器解析，
+// 分块上传的文件绑定到 `form:"name,file"` 声明的字段。
+func Parse(r *http.Request, pointer interface{}) (err error) {
+	files, err := parseFiles(r, pointer)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			removeFiles(files)
+		}
+	}()
+
 	pathParams, err := ParsePath(r, pointer)
 	if err != nil {
 		return err
@@ -42,7 +55,7 @@ func Parse(r *http.Request, pointer interface{}) error {
 		return err
 	}
 
-	bodyParams, err := ParseJsonBody(r, pointer)
+	bodyParams, err := ParseBody(r, pointer)
 	if err != nil {
 		return err
 	}
@@ -63,6 +76,39 @@ func Parse(r *http.Request, pointer interface{}) error {
 	return nil
 }
 
+// ParseBody 按 Content-Type 选择编解码器解析请求体参数，
+// 表单和分块上传的请求体已由 ParseForm 解析，未注册的类型忽略请求体。
+func ParseBody(r *http.Request, pointer interface{}) (*gmap.StrAnyMap, error) {
+	if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+		return gmap.NewStrAnyMap(), nil
+	}
+
+	contentType := r.Header.Get(ContentType)
+	if isForm(contentType) {
+		return gmap.NewStrAnyMap(), nil
+	}
+
+	codec, ok := CodecFor(contentType)
+	if !ok {
+		return gmap.NewStrAnyMap(), nil
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodyLen))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return gmap.NewStrAnyMap(), nil
+	}
+
+	params, err := codec.Unmarshal(data, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return gmap.NewStrAnyMapFrom(params), nil
+}
+
 // ParseJsonBody 解析请求体为JSON的参数
 func ParseJsonBody(r *http.Request, pointer interface{}) (*gmap.StrAnyMap, error) {
 	var reader io.Reader
@@ -81,9 +127,12 @@ func ParseForm(r *http.Request, pointer interface{}) (*gmap.StrAnyMap, error) {
 		return nil, err
 	}
 
+	if isMultipart(r) {
+		limitBody(r)
+	}
 	if err := r.ParseMultipartForm(maxMemory); err != nil {
 		if err != http.ErrNotMultipart {
-			return nil, err
+			return nil, multipartError(err)
 		}
 	}
 
@@ -150,6 +199,12 @@ func ParseHeader(headerValue string) map[string]string {
 	return params
 }
 
+// 判断是否为 ParseForm 已解析的表单或分块上传请求体
+func isForm(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.HasPrefix(contentType, ApplicationForm) || strings.HasPrefix(contentType, MultipartFormData)
+}
+
 // 判断是否带有JSON请求体
 func withJsonBody(r *http.Request) bool {
 	return r.ContentLength > 0 && strings.Contains(r.Header.Get(ContentType), ApplicationJson)
@@ -1,7 +1,7 @@
 package httpx
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"sync"
 
@@ -34,28 +34,12 @@ func NewDefaultError(msg string) error {
 
 // Error 错误响应，支持自定义错误处理器
 func Error(w http.ResponseWriter, err error) {
-	lock.RLock()
-	handler := errorHandler
-	lock.RUnlock()
-
-	if handler == nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
+	writeError(w, jsonCodec{}, err)
+}
 
-	code, body := errorHandler(err)
-	e, ok := body.(error)
-	if ok {
-		http.Error(w, e.Error(), code)
-	} else {
-		if m, ok := body.(Message); ok {
-			if m.Code > 0 {
-				http.Error(w, m.Msg, http.StatusOK)
-				return
-			}
-		}
-		WriteJson(w, code, body)
-	}
+// ErrorCtx 错误响应，按请求的 Accept 选择编解码器，支持自定义错误处理器
+func ErrorCtx(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, negotiate(r.Header.Get(Accept)), err)
 }
 
 // Ok 正常响应
@@ -65,16 +49,12 @@ func Ok(w http.ResponseWriter) {
 
 // OkJson 正常JSON响应
 func OkJson(w http.ResponseWriter, body interface{}) {
-	lock.RLock()
-	handler := okJsonHandler
-	lock.RUnlock()
-
-	if handler != nil {
-		body = okJsonHandler(body)
-	}
+	WriteJson(w, http.StatusOK, wrapOk(body))
+}
 
-	WriteJson(w, http.StatusOK, body)
-	return
+// OkCtx 正常响应，按请求的 Accept 选择编解码器，支持自定义成功处理器
+func OkCtx(w http.ResponseWriter, r *http.Request, body interface{}) {
+	WriteCtx(w, r, http.StatusOK, wrapOk(body))
 }
 
 // SetErrorHandler 设置自定义错误处理器
@@ -93,12 +73,29 @@ func SetOkJsonHandler(handler func(body interface{}) interface{}) {
 
 // WriteJson 写JSON响应
 func WriteJson(w http.ResponseWriter, code int, body interface{}) {
-	w.Header().Set(ContentType, ApplicationJson)
-	w.WriteHeader(code)
+	write(w, jsonCodec{}, code, body)
+}
+
+// WriteCtx 写响应，按请求的 Accept 选择编解码器，
+// 编解码器不支持响应体的类型时（如 protobuf 遇到非 proto.Message）使用 JSON。
+func WriteCtx(w http.ResponseWriter, r *http.Request, code int, body interface{}) {
+	write(w, negotiate(r.Header.Get(Accept)), code, body)
+}
 
-	if bytes, err := json.Marshal(body); err != nil {
+func write(w http.ResponseWriter, codec Codec, code int, body interface{}) {
+	bytes, err := codec.Marshal(body)
+	if errors.Is(err, ErrUnsupportedBody) {
+		codec = jsonCodec{}
+		bytes, err = codec.Marshal(body)
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-	} else if n, err := w.Write(bytes); err != nil {
+		return
+	}
+
+	w.Header().Set(ContentType, codec.ContentType())
+	w.WriteHeader(code)
+	if n, err := w.Write(bytes); err != nil {
 		// http.ErrHandlerTimeout 已经被 http.TimeoutHandler 处理了
 		// 所以此处忽略。
 		if err != http.ErrHandlerTimeout {
@@ -108,3 +105,42 @@ func WriteJson(w http.ResponseWriter, code int, body interface{}) {
 		logx.Errorf("实际字节数：%d，写字节数：%d", len(bytes), n)
 	}
 }
+
+// writeError 按自定义错误处理器转换错误，未设置时以纯文本返回 400
+func writeError(w http.ResponseWriter, codec Codec, err error) {
+	lock.RLock()
+	handler := errorHandler
+	lock.RUnlock()
+
+	if handler == nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	code, body := handler(err)
+	e, ok := body.(error)
+	if ok {
+		http.Error(w, e.Error(), code)
+	} else {
+		if m, ok := body.(Message); ok {
+			if m.Code > 0 {
+				http.Error(w, m.Msg, http.StatusOK)
+				return
+			}
+		}
+		write(w, codec, code, body)
+	}
+}
+
+// wrapOk 按自定义成功处理器包装响应体
+func wrapOk(body interface{}) interface{} {
+	lock.RLock()
+	handler := okJsonHandler
+	lock.RUnlock()
+
+	if handler != nil {
+		return handler(body)
+	}
+
+	return body
+}
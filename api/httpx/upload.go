@@ -0,0 +1,557 @@
+package httpx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"git.zc0901.com/go/god/api/internal/context"
+	"git.zc0901.com/go/god/lib/fs"
+)
+
+const (
+	MultipartFormData = "multipart/form-data"
+
+	fileOption     = "file"
+	optionalOption = "optional"
+	maxSizeOption  = "maxsize="
+	typesOption    = "types="
+	typesSeparator = "|"
+	sniffLen       = 512
+	tempPattern    = "upload-*"
+)
+
+var (
+	// ErrRequestTooLarge 上传请求超过最大字节数
+	ErrRequestTooLarge = errors.New("上传请求过大")
+	// ErrFileTooLarge 上传文件超过最大字节数
+	ErrFileTooLarge = errors.New("上传文件过大")
+	// ErrFileType 上传文件类型不允许
+	ErrFileType = errors.New("上传文件类型不允许")
+	// ErrMissingFile 缺少必填的上传文件
+	ErrMissingFile = errors.New("缺少上传文件")
+
+	fileType = reflect.TypeOf(File{})
+
+	uploadDir string
+)
+
+type (
+	// File 上传的文件，在请求结构体中以 `form:"avatar,file"` 声明，
+	// 字段类型可以为 File、*File 或 []*File。
+	// 标签可附加 optional、maxsize=字节数 和 types=image/png|image/* 选项。
+	File struct {
+		Filename    string               // 客户端提交的文件名
+		Size        int64                // 文件字节数
+		ContentType string               // 根据文件内容嗅探的 MIME 类型
+		Header      textproto.MIMEHeader // 分块头
+		Path        string               // 流式保存到临时目录时的文件路径
+		fh          *multipart.FileHeader
+	}
+
+	fileField struct {
+		name     string
+		optional bool
+		maxSize  int64
+		types    []string
+		value    reflect.Value
+	}
+
+	// maxBytesReader 读取超过 n 字节时返回 ErrRequestTooLarge
+	maxBytesReader struct {
+		io.ReadCloser
+		n int64
+	}
+)
+
+// SetUploadDir 设置上传文件的临时目录，设置后声明了文件字段的请求
+// 会边读边写入该目录，不再缓存在内存中。文件处理完毕后应调用 SaveAs 或 Remove。
+func SetUploadDir(dir string) {
+	lock.Lock()
+	defer lock.Unlock()
+	uploadDir = dir
+}
+
+// Open 打开文件读取内容，调用者需关闭
+func (f *File) Open() (io.ReadCloser, error) {
+	if len(f.Path) > 0 {
+		return os.Open(f.Path)
+	}
+	if f.fh == nil {
+		return nil, os.ErrNotExist
+	}
+
+	return f.fh.Open()
+}
+
+// SaveAs 将文件保存到 path，目录不存在时自动创建
+func (f *File) SaveAs(path string) error {
+	if err := fs.MkdirIfNotExist(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	if len(f.Path) > 0 {
+		if err := os.Rename(f.Path, path); err == nil {
+			f.Path = path
+			return nil
+		}
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// Remove 删除流式保存的临时文件
+func (f *File) Remove() error {
+	if len(f.Path) == 0 {
+		return nil
+	}
+
+	return fs.RemoveIfExist(f.Path)
+}
+
+// ParseFiles 将分块上传的文件绑定到请求结构体中声明的文件字段，
+// 并按请求上下文中的最大字节数、标签中的 maxsize 和 types 校验每个文件。
+func ParseFiles(r *http.Request, pointer interface{}) error {
+	_, err := parseFiles(r, pointer)
+	return err
+}
+
+// parseFiles 绑定文件字段，返回流式保存的临时文件，以便后续解析失败时删除
+func parseFiles(r *http.Request, pointer interface{}) ([]*File, error) {
+	fields, err := fileFields(pointer)
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+
+	lock.RLock()
+	dir := uploadDir
+	lock.RUnlock()
+
+	if len(dir) > 0 && r.MultipartForm == nil && isMultipart(r) {
+		return streamFiles(r, fields, dir)
+	}
+
+	if r.MultipartForm == nil && isMultipart(r) {
+		limitBody(r)
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			return nil, multipartError(err)
+		}
+	}
+
+	var files map[string][]*multipart.FileHeader
+	if r.MultipartForm != nil {
+		files = r.MultipartForm.File
+	}
+
+	return nil, bindFiles(fields, func(field fileField) ([]*File, error) {
+		var result []*File
+		for _, fh := range files[field.name] {
+			f, err := newFile(fh)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, f)
+		}
+		return result, nil
+	}, defaultMaxSize(r))
+}
+
+// streamFiles 边读边将声明的文件字段写入临时目录，表单值放入 r.Form 和 r.PostForm
+func streamFiles(r *http.Request, fields []fileField, dir string) ([]*File, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	limitBody(r)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]fileField, len(fields))
+	for _, field := range fields {
+		limits[field.name] = field
+	}
+
+	form := &multipart.Form{
+		Value: make(map[string][]string),
+	}
+	files := make(map[string][]*File)
+	var saved []*File
+	maxValueBytes := int64(maxMemory)
+	max := defaultMaxSize(r)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			removeFiles(saved)
+			return nil, multipartError(err)
+		}
+
+		name := part.FormName()
+		if len(name) == 0 {
+			continue
+		}
+
+		if len(part.FileName()) == 0 {
+			value, err := ioutil.ReadAll(io.LimitReader(part, maxValueBytes+1))
+			if err != nil {
+				removeFiles(saved)
+				return nil, multipartError(err)
+			}
+			maxValueBytes -= int64(len(value))
+			if maxValueBytes < 0 {
+				removeFiles(saved)
+				return nil, multipart.ErrMessageTooLarge
+			}
+			form.Value[name] = append(form.Value[name], string(value))
+			r.Form.Add(name, string(value))
+			r.PostForm.Add(name, string(value))
+			continue
+		}
+
+		field, ok := limits[name]
+		if !ok {
+			// 未声明的文件直接丢弃
+			if _, err := io.Copy(ioutil.Discard, part); err != nil {
+				removeFiles(saved)
+				return nil, multipartError(err)
+			}
+			continue
+		}
+
+		f, err := saveFile(dir, part, field, max)
+		if err != nil {
+			removeFiles(saved)
+			return nil, err
+		}
+		saved = append(saved, f)
+		files[name] = append(files[name], f)
+	}
+	r.MultipartForm = form
+
+	if err := bindFiles(fields, func(field fileField) ([]*File, error) {
+		return files[field.name], nil
+	}, max); err != nil {
+		removeFiles(saved)
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// saveFile 将一个文件分块写入临时目录，写入前先校验嗅探出的类型
+func saveFile(dir string, part *multipart.Part, field fileField, max int64) (*File, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, multipartError(err)
+	}
+	head = head[:n]
+
+	f := &File{
+		Filename:    part.FileName(),
+		ContentType: http.DetectContentType(head),
+		Header:      part.Header,
+	}
+	if err := field.checkType(f); err != nil {
+		return nil, err
+	}
+
+	tmp, err := fs.TempFileInDir(dir, tempPattern)
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Close()
+	f.Path = tmp.Name()
+
+	limit := field.limit(max)
+	var reader io.Reader = io.MultiReader(bytes.NewReader(head), part)
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit+1)
+	}
+	f.Size, err = io.Copy(tmp, reader)
+	if err == nil && limit > 0 && f.Size > limit {
+		err = fmt.Errorf("%w：%s 限制为 %d 字节", ErrFileTooLarge, field.name, limit)
+	}
+	if err != nil {
+		_ = tmp.Close()
+		_ = f.Remove()
+		return nil, multipartError(err)
+	}
+
+	return f, nil
+}
+
+// bindFiles 校验并设置文件字段
+func bindFiles(fields []fileField, lookup func(field fileField) ([]*File, error), max int64) error {
+	for _, field := range fields {
+		files, err := lookup(field)
+		if err != nil {
+			return err
+		}
+
+		if len(files) == 0 {
+			if field.optional {
+				continue
+			}
+			return fmt.Errorf("%w：%s", ErrMissingFile, field.name)
+		}
+
+		limit := field.limit(max)
+		for _, f := range files {
+			if limit > 0 && f.Size > limit {
+				return fmt.Errorf("%w：%s 限制为 %d 字节", ErrFileTooLarge, field.name, limit)
+			}
+			if err := field.checkType(f); err != nil {
+				return err
+			}
+		}
+
+		field.set(files)
+	}
+
+	return nil
+}
+
+// fileFields 提取结构体中 form 标签带有 file 选项的字段，包括内嵌结构体的字段
+func fileFields(pointer interface{}) ([]fileField, error) {
+	value := reflect.ValueOf(pointer)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	return collectFileFields(value.Elem())
+}
+
+func collectFileFields(value reflect.Value) ([]fileField, error) {
+	var fields []fileField
+	rt := value.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			embedded, err := collectFileFields(value.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup(formKey)
+		if !ok {
+			continue
+		}
+
+		field, ok, err := parseFileTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("字段 %s：%v", sf.Name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if !isFileType(sf.Type) {
+			return nil, fmt.Errorf("字段 %s 的类型必须为 httpx.File、*httpx.File 或 []*httpx.File", sf.Name)
+		}
+		if len(field.name) == 0 {
+			field.name = sf.Name
+		}
+		field.value = value.Field(i)
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// parseFileTag 解析 `form:"name,file,optional,maxsize=1048576,types=image/png|image/jpeg"`
+func parseFileTag(tag string) (fileField, bool, error) {
+	segments := strings.Split(tag, ",")
+	field := fileField{
+		name: strings.TrimSpace(segments[0]),
+	}
+
+	var isFile bool
+	for _, segment := range segments[1:] {
+		option := strings.TrimSpace(segment)
+		switch {
+		case option == fileOption:
+			isFile = true
+		case option == optionalOption:
+			field.optional = true
+		case strings.HasPrefix(option, maxSizeOption):
+			size, err := strconv.ParseInt(strings.TrimPrefix(option, maxSizeOption), 10, 64)
+			if err != nil || size <= 0 {
+				return field, false, fmt.Errorf("无效的 %s 选项", option)
+			}
+			field.maxSize = size
+		case strings.HasPrefix(option, typesOption):
+			for _, t := range strings.Split(strings.TrimPrefix(option, typesOption), typesSeparator) {
+				if t = strings.TrimSpace(t); len(t) > 0 {
+					field.types = append(field.types, strings.ToLower(t))
+				}
+			}
+		}
+	}
+
+	return field, isFile, nil
+}
+
+func isFileType(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Struct:
+		return rt == fileType
+	case reflect.Ptr:
+		return rt.Elem() == fileType
+	case reflect.Slice:
+		return rt.Elem().Kind() == reflect.Ptr && rt.Elem().Elem() == fileType
+	default:
+		return false
+	}
+}
+
+// limit 返回文件的最大字节数，标签未指定或超过请求限制时使用请求限制
+func (f fileField) limit(max int64) int64 {
+	if f.maxSize > 0 && (max <= 0 || f.maxSize < max) {
+		return f.maxSize
+	}
+
+	return max
+}
+
+// checkType 按嗅探出的 MIME 类型校验，支持 image/* 形式的通配
+func (f fileField) checkType(file *File) error {
+	if len(f.types) == 0 {
+		return nil
+	}
+
+	contentType := file.ContentType
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	for _, t := range f.types {
+		if t == contentType {
+			return nil
+		}
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(t, "*")) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w：%s 为 %s", ErrFileType, f.name, contentType)
+}
+
+func (f fileField) set(files []*File) {
+	switch f.value.Kind() {
+	case reflect.Struct:
+		f.value.Set(reflect.ValueOf(*files[0]))
+	case reflect.Ptr:
+		f.value.Set(reflect.ValueOf(files[0]))
+	case reflect.Slice:
+		f.value.Set(reflect.ValueOf(files))
+	}
+}
+
+// newFile 从内存解析的分块文件创建 File，并嗅探内容类型
+func newFile(fh *multipart.FileHeader) (*File, error) {
+	src, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	return &File{
+		Filename:    fh.Filename,
+		Size:        fh.Size,
+		ContentType: http.DetectContentType(head[:n]),
+		Header:      fh.Header,
+		fh:          fh,
+	}, nil
+}
+
+func removeFiles(files []*File) {
+	for _, f := range files {
+		_ = f.Remove()
+	}
+}
+
+// defaultMaxSize 单个文件默认与整个请求使用相同的最大字节数（即 Conf.MaxBytes）
+func defaultMaxSize(r *http.Request) int64 {
+	return context.MaxBytes(r)
+}
+
+func isMultipart(r *http.Request) bool {
+	return strings.HasPrefix(strings.ToLower(r.Header.Get(ContentType)), MultipartFormData)
+}
+
+// limitBody 按请求上下文中的最大字节数限制分块请求体，防止无 Content-Length 的请求绕过限制
+func limitBody(r *http.Request) {
+	max := context.MaxBytes(r)
+	if max <= 0 {
+		return
+	}
+	if _, ok := r.Body.(*maxBytesReader); ok {
+		return
+	}
+
+	r.Body = &maxBytesReader{ReadCloser: r.Body, n: max}
+}
+
+func multipartError(err error) error {
+	if errors.Is(err, ErrRequestTooLarge) {
+		return ErrRequestTooLarge
+	}
+
+	return err
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.n < 0 {
+		return 0, ErrRequestTooLarge
+	}
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if int64(n) <= r.n {
+		r.n -= int64(n)
+		return n, err
+	}
+
+	n = int(r.n)
+	r.n = -1
+	return n, ErrRequestTooLarge
+}
@@ -0,0 +1,203 @@
+package httpx
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.zc0901.com/go/god/api/internal/context"
+	"github.com/stretchr/testify/assert"
+)
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A0000000000")
+
+type uploadPart struct {
+	name     string
+	filename string
+	data     []byte
+}
+
+func newUploadRequest(t *testing.T, max int64, parts ...uploadPart) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range parts {
+		if len(part.filename) == 0 {
+			assert.Nil(t, writer.WriteField(part.name, string(part.data)))
+			continue
+		}
+
+		w, err := writer.CreateFormFile(part.name, part.filename)
+		assert.Nil(t, err)
+		_, err = w.Write(part.data)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "http://localhost/upload?id=1", &body)
+	r.Header.Set(ContentType, writer.FormDataContentType())
+	if max > 0 {
+		r = context.WithMaxBytes(r, max)
+	}
+	return r
+}
+
+func TestParseUpload(t *testing.T) {
+	var v struct {
+		Id     int     `form:"id"`
+		Name   string  `form:"name"`
+		Avatar *File   `form:"avatar,file,types=image/*"`
+		Docs   []*File `form:"docs,file,optional"`
+		Cover  File    `form:"cover,file,optional"`
+	}
+
+	r := newUploadRequest(t, 1024,
+		uploadPart{name: "name", data: []byte("kevin")},
+		uploadPart{name: "avatar", filename: "a.png", data: pngHeader},
+		uploadPart{name: "docs", filename: "1.txt", data: []byte("one")},
+		uploadPart{name: "docs", filename: "2.txt", data: []byte("two")},
+	)
+	assert.Nil(t, Parse(r, &v))
+	assert.Equal(t, 1, v.Id)
+	assert.Equal(t, "kevin", v.Name)
+	assert.Equal(t, "a.png", v.Avatar.Filename)
+	assert.Equal(t, int64(len(pngHeader)), v.Avatar.Size)
+	assert.Equal(t, "image/png", v.Avatar.ContentType)
+	assert.Len(t, v.Docs, 2)
+	assert.Equal(t, "text/plain; charset=utf-8", v.Docs[1].ContentType)
+	assert.Empty(t, v.Cover.Filename)
+
+	f, err := v.Docs[0].Open()
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	assert.Equal(t, "one", string(data))
+}
+
+func TestParseUpload_Errors(t *testing.T) {
+	type avatarReq struct {
+		Avatar *File `form:"avatar,file,maxsize=16,types=image/png|image/jpeg"`
+	}
+
+	tests := []struct {
+		name  string
+		max   int64
+		parts []uploadPart
+		err   error
+	}{
+		{
+			name: "missing",
+			parts: []uploadPart{
+				{name: "other", filename: "a.png", data: pngHeader},
+			},
+			err: ErrMissingFile,
+		},
+		{
+			name: "type",
+			parts: []uploadPart{
+				{name: "avatar", filename: "a.png", data: []byte("plain text")},
+			},
+			err: ErrFileType,
+		},
+		{
+			name: "file too large",
+			parts: []uploadPart{
+				{name: "avatar", filename: "a.png", data: append(pngHeader, "0000"...)},
+			},
+			err: ErrFileTooLarge,
+		},
+		{
+			name: "request too large",
+			max:  64,
+			parts: []uploadPart{
+				{name: "avatar", filename: "a.png", data: pngHeader},
+				{name: "padding", data: bytes.Repeat([]byte("0"), 128)},
+			},
+			err: ErrRequestTooLarge,
+		},
+	}
+
+	for _, dir := range []string{"", t.TempDir()} {
+		SetUploadDir(dir)
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				var v avatarReq
+				r := newUploadRequest(t, test.max, test.parts...)
+				// 模拟无 Content-Length 的分块传输请求
+				r.ContentLength = -1
+				err := Parse(r, &v)
+				assert.True(t, errors.Is(err, test.err), "%v", err)
+			})
+		}
+
+		if len(dir) > 0 {
+			files, err := ioutil.ReadDir(dir)
+			assert.Nil(t, err)
+			assert.Empty(t, files)
+		}
+	}
+	SetUploadDir("")
+}
+
+func TestParseUpload_Stream(t *testing.T) {
+	dir := t.TempDir()
+	SetUploadDir(dir)
+	defer SetUploadDir("")
+
+	var v struct {
+		Name   string `form:"name"`
+		Avatar *File  `form:"avatar,file"`
+	}
+	r := newUploadRequest(t, 1024,
+		uploadPart{name: "name", data: []byte("kevin")},
+		uploadPart{name: "ignored", filename: "x.txt", data: []byte("ignored")},
+		uploadPart{name: "avatar", filename: "a.png", data: pngHeader},
+	)
+	assert.Nil(t, Parse(r, &v))
+	assert.Equal(t, "kevin", v.Name)
+	assert.Equal(t, "image/png", v.Avatar.ContentType)
+	assert.Equal(t, dir, filepath.Dir(v.Avatar.Path))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	target := filepath.Join(t.TempDir(), "avatars", "a.png")
+	assert.Nil(t, v.Avatar.SaveAs(target))
+	data, err := ioutil.ReadFile(target)
+	assert.Nil(t, err)
+	assert.Equal(t, pngHeader, data)
+	_, err = os.Stat(filepath.Join(dir, files[0].Name()))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParseFileTag(t *testing.T) {
+	field, ok, err := parseFileTag("avatar,file,optional,maxsize=1024,types=image/png|IMAGE/jpeg")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "avatar", field.name)
+	assert.True(t, field.optional)
+	assert.Equal(t, int64(1024), field.maxSize)
+	assert.Equal(t, []string{"image/png", "image/jpeg"}, field.types)
+	assert.Equal(t, int64(512), field.limit(512))
+	assert.Equal(t, int64(1024), field.limit(0))
+
+	_, ok, err = parseFileTag("name,optional")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	_, _, err = parseFileTag("avatar,file,maxsize=abc")
+	assert.NotNil(t, err)
+
+	var v struct {
+		Avatar string `form:"avatar,file"`
+	}
+	_, err = fileFields(&v)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,24 @@
+package context
+
+import (
+	"context"
+	"net/http"
+)
+
+// 请求最大字节数键
+var maxBytesKey = contextKey("maxBytes")
+
+// MaxBytes 返回请求允许的最大字节数，未限制时返回 0
+func MaxBytes(r *http.Request) int64 {
+	max, ok := r.Context().Value(maxBytesKey).(int64)
+	if ok {
+		return max
+	}
+
+	return 0
+}
+
+// WithMaxBytes 包装带有最大字节数的上下文
+func WithMaxBytes(r *http.Request, max int64) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), maxBytesKey, max))
+}
@@ -1,12 +1,15 @@
 package api
 
 import (
-	"errors"
 	"log"
 	"net/http"
+	"time"
 
 	"git.zc0901.com/go/god/api/handler"
 	"git.zc0901.com/go/god/api/router"
+	"git.zc0901.com/go/god/api/static"
+	"git.zc0901.com/go/god/api/token"
+	"git.zc0901.com/go/god/lib/limit"
 	"git.zc0901.com/go/god/lib/logx"
 )
 
@@ -33,10 +36,6 @@ func MustNewServer(c Conf, opts ...RunOption) *Server {
 }
 
 func NewServer(c Conf, opts ...RunOption) (*Server, error) {
-	if len(opts) > 1 {
-		return nil, errors.New("只允许一个 RunOption")
-	}
-
 	if err := c.Setup(); err != nil {
 		return nil, err
 	}
@@ -74,6 +73,7 @@ func (s *Server) Start() {
 }
 
 func (s *Server) Stop() {
+	s.engine.streams.Close()
 	logx.Close()
 }
 
@@ -107,6 +107,57 @@ func WithJwtTransition(secret, prevSecret string) RouteOption {
 	}
 }
 
+// WithJwtKeys 生成使用公钥验证 RS256、ES256、EdDSA 等非对称算法令牌的路由选项
+func WithJwtKeys(keys token.KeyProvider) RouteOption {
+	return func(r *featuredRoutes) {
+		r.jwt.enabled = true
+		r.jwt.keys = keys
+	}
+}
+
+// WithJwtPem 生成使用 PEM 文件中的公钥验证令牌的路由选项，文件名（不含扩展名）作为 kid
+func WithJwtPem(files ...string) RouteOption {
+	keys, err := token.NewPemKeys(files...)
+	logx.Must(err)
+	return WithJwtKeys(keys)
+}
+
+// WithJwks 生成使用 JWKS 验证令牌的路由选项，source 为 JWKS 文件路径或 http(s) 地址，
+// 按令牌的 kid 选择公钥，并定期或遇到未知 kid 时重新加载。
+func WithJwks(source string, opts ...token.JwksOption) RouteOption {
+	keys, err := token.NewJwks(source, opts...)
+	logx.Must(err)
+	return WithJwtKeys(keys)
+}
+
+// WithJwtAudience 要求令牌的 aud 包含指定受众之一，与 WithJwt 等选项一起使用
+func WithJwtAudience(audiences ...string) RouteOption {
+	return func(r *featuredRoutes) {
+		r.jwt.audiences = append(r.jwt.audiences, audiences...)
+	}
+}
+
+// WithJwtIssuer 要求令牌的 iss 为指定签发者之一，与 WithJwt 等选项一起使用
+func WithJwtIssuer(issuers ...string) RouteOption {
+	return func(r *featuredRoutes) {
+		r.jwt.issuers = append(r.jwt.issuers, issuers...)
+	}
+}
+
+// WithJwtClockSkew 自定义校验令牌时间时允许的时钟偏差，与 WithJwt 等选项一起使用
+func WithJwtClockSkew(skew time.Duration) RouteOption {
+	return func(r *featuredRoutes) {
+		r.jwt.clockSkew = skew
+	}
+}
+
+// WithJwtRevokedCheck 拒绝 jti 已被吊销的令牌，如 token.Issuer.IsRevoked，与 WithJwt 等选项一起使用
+func WithJwtRevokedCheck(check handler.RevokedCheck) RouteOption {
+	return func(r *featuredRoutes) {
+		r.jwt.revoked = check
+	}
+}
+
 func WithMiddlewares(ms []Middleware, rs ...Route) []Route {
 	for i := len(ms) - 1; i >= 0; i-- {
 		rs = WithMiddleware(ms[i], rs...)
@@ -132,16 +183,29 @@ func WithMiddleware(middleware Middleware, rs ...Route) []Route {
 
 // 附加资源未找到处理器选项
 func WithNotFoundHandler(handler http.Handler) RunOption {
-	rt := router.NewRouter()
-	rt.SetNotFoundHandler(handler)
-	return WithRouter(rt)
+	return func(server *Server) {
+		server.engine.notFound = handler
+	}
 }
 
 // 附加资源不允许访问处理器选项
 func WithNotAllowedHandler(handler http.Handler) RunOption {
-	rt := router.NewRouter()
-	rt.SetNotAllowedHandler(handler)
-	return WithRouter(rt)
+	return func(server *Server) {
+		server.engine.notAllowed = handler
+	}
+}
+
+// WithStaticFiles 在 prefix 下提供 dir 目录中的静态文件，磁盘上不存在 dir 时使用 gres 打包的同名目录。
+// 只处理未匹配任何路由的 GET 和 HEAD 请求，文件不存在时交给资源未找到处理器。
+func WithStaticFiles(prefix, dir string, opts ...static.Option) RunOption {
+	return WithFileSystem(prefix, static.Dir(dir), opts...)
+}
+
+// WithFileSystem 在 prefix 下提供 fs 中的静态文件，如 http.Dir 或 static.Resource 包装的 gres 资源
+func WithFileSystem(prefix string, fs http.FileSystem, opts ...static.Option) RunOption {
+	return func(server *Server) {
+		server.engine.statics = append(server.engine.statics, static.Handler(prefix, fs, opts...))
+	}
 }
 
 // 附加高优先级路由选项
@@ -160,6 +224,52 @@ func WithSignature(signature SignatureConf) RouteOption {
 	}
 }
 
+// WithStream 附加长连接路由选项，用于 WebSocket（stream.Upgrade）和 SSE（stream.NewSSE）路由。
+// 长连接路由不经过超时控制、Gzip 和最大字节限制，仍执行鉴权、日志、链路追踪和最大连接数限制，
+// 服务停止时连接会被关闭。
+func WithStream() RouteOption {
+	return func(r *featuredRoutes) {
+		r.stream = true
+	}
+}
+
+// WithTimeout 附加路由超时时间选项，覆盖 Conf.Timeout，为 0 时不限制超时
+func WithTimeout(timeout time.Duration) RouteOption {
+	return func(r *featuredRoutes) {
+		r.timeout = &timeout
+	}
+}
+
+// WithMaxBytes 附加路由最大请求字节数选项，覆盖 Conf.MaxBytes，为 0 时不限制大小，
+// 同时作为 httpx 解析上传文件时的单个文件和整个请求的大小限制。
+func WithMaxBytes(maxBytes int64) RouteOption {
+	return func(r *featuredRoutes) {
+		r.maxBytes = &maxBytes
+	}
+}
+
+// WithoutShedding 附加不参与自适应降载的路由选项，用于健康检查等在高负载时也必须响应的路由
+func WithoutShedding() RouteOption {
+	return func(r *featuredRoutes) {
+		r.noShedding = true
+	}
+}
+
+// WithPeriodLimit 附加周期限流路由选项，keyFunc 为空时按客户端地址限流
+func WithPeriodLimit(limiter *limit.PeriodLimit, keyFunc handler.LimitKeyFunc) RouteOption {
+	return func(r *featuredRoutes) {
+		r.limit.period = limiter
+		r.limit.keyFunc = keyFunc
+	}
+}
+
+// WithTokenLimit 附加令牌桶限流路由选项
+func WithTokenLimit(limiter *limit.TokenLimiter) RouteOption {
+	return func(r *featuredRoutes) {
+		r.limit.token = limiter
+	}
+}
+
 func WithUnauthorizedCallback(callback handler.UnauthorizedCallback) RunOption {
 	return func(server *Server) {
 		server.engine.SetUnauthorizedCallback(callback)
@@ -0,0 +1,70 @@
+package static
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path"
+
+	"git.zc0901.com/go/god/lib/os/gfile"
+	"git.zc0901.com/go/god/lib/os/gres"
+)
+
+type (
+	resourceFileSystem struct {
+		res  *gres.Resource
+		root string
+	}
+
+	// resourceFile 每次打开使用独立的读取器，gres.File 的读取器在并发请求间共享
+	resourceFile struct {
+		*bytes.Reader
+		file *gres.File
+	}
+)
+
+// Dir 返回 dir 目录的文件系统，磁盘上不存在该目录时使用 gres 默认资源中打包的同名目录
+func Dir(dir string) http.FileSystem {
+	if !gfile.Exists(dir) && !gres.IsEmpty() && gres.Contains(dir) {
+		return Resource(gres.Instance(), dir)
+	}
+
+	return http.Dir(dir)
+}
+
+// Resource 返回 gres 资源中以 root 为根目录的文件系统
+func Resource(res *gres.Resource, root string) http.FileSystem {
+	return resourceFileSystem{
+		res:  res,
+		root: root,
+	}
+}
+
+func (fs resourceFileSystem) Open(name string) (http.File, error) {
+	file := fs.res.Get(path.Join(fs.root, path.Clean("/"+name)))
+	if file == nil {
+		return nil, os.ErrNotExist
+	}
+
+	var content []byte
+	if !file.FileInfo().IsDir() {
+		content = file.Content()
+	}
+
+	return resourceFile{
+		Reader: bytes.NewReader(content),
+		file:   file,
+	}, nil
+}
+
+func (f resourceFile) Close() error {
+	return nil
+}
+
+func (f resourceFile) Readdir(count int) ([]os.FileInfo, error) {
+	return f.file.Readdir(count)
+}
+
+func (f resourceFile) Stat() (os.FileInfo, error) {
+	return f.file.FileInfo(), nil
+}
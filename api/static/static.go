@@ -0,0 +1,245 @@
+package static
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	defaultIndex = "index.html"
+	gzipExt      = ".gz"
+	noCache      = "no-cache"
+)
+
+type (
+	// Option 自定义静态文件服务的方法
+	Option func(o *options)
+
+	options struct {
+		index string
+		spa   bool
+		rules []cacheRule
+	}
+
+	cacheRule struct {
+		pattern string
+		value   string
+	}
+
+	server struct {
+		prefix string
+		fs     http.FileSystem
+		options
+	}
+)
+
+// Handler 返回在 prefix 下提供 fs 中静态文件的中间件，只处理 GET 和 HEAD 请求，
+// 文件不存在时交给 next 处理。支持 ETag/Last-Modified 条件请求、Range 请求和预压缩的 .gz 文件，
+// 不列出目录，也不提供以 . 开头的隐藏文件。
+func Handler(prefix string, fs http.FileSystem, opts ...Option) func(next http.Handler) http.Handler {
+	s := server{
+		prefix: "/" + strings.Trim(prefix, "/"),
+		fs:     fs,
+		options: options{
+			index: defaultIndex,
+		},
+	}
+	for _, opt := range opts {
+		opt(&s.options)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.serve(w, r) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// WithIndex 自定义目录的索引文件，默认 index.html
+func WithIndex(index string) Option {
+	return func(o *options) {
+		o.index = index
+	}
+}
+
+// WithSPA 单页应用模式，请求的页面不存在时返回根目录的索引文件，由前端路由处理，
+// 只对浏览器页面请求（Accept 含 text/html）生效，缺失的 js、css 及接口请求等仍返回 404。
+func WithSPA() Option {
+	return func(o *options) {
+		o.spa = true
+	}
+}
+
+// WithCacheControl 为匹配 pattern 的文件设置 Cache-Control，按添加顺序取第一个匹配的规则。
+// pattern 使用 path.Match 语法，匹配相对路径，不含 / 时也匹配文件名，
+// 如 WithCacheControl("assets/*", "public, max-age=31536000, immutable")、WithCacheControl("*.html", "no-cache")。
+func WithCacheControl(pattern, value string) Option {
+	return func(o *options) {
+		o.rules = append(o.rules, cacheRule{
+			pattern: pattern,
+			value:   value,
+		})
+	}
+}
+
+// serve 尝试提供静态文件，返回 false 表示应交给下一个处理器
+func (s server) serve(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	name, ok := s.relative(r.URL.Path)
+	if !ok || hidden(name) {
+		return false
+	}
+
+	f, info, err := s.open(name)
+	if err == nil && info.IsDir() {
+		f.Close()
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			redirect(w, r, path.Base(r.URL.Path)+"/")
+			return true
+		}
+
+		name = path.Join(name, s.index)
+		f, info, err = s.open(name)
+	}
+	if err == nil {
+		defer f.Close()
+		s.serveFile(w, r, name, f, info, s.cacheControl(name))
+		return true
+	}
+
+	if !s.spa || !acceptsPage(r) {
+		return false
+	}
+
+	name = "/" + s.index
+	f, info, err = s.open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	// 索引页内容随版本变化，不能被缓存
+	s.serveFile(w, r, name, f, info, noCache)
+	return true
+}
+
+// relative 返回请求路径相对于 prefix 的文件名
+func (s server) relative(upath string) (string, bool) {
+	if s.prefix != "/" {
+		if upath != s.prefix && !strings.HasPrefix(upath, s.prefix+"/") {
+			return "", false
+		}
+		upath = strings.TrimPrefix(upath, s.prefix)
+	}
+
+	return path.Clean("/" + upath), true
+}
+
+func (s server) open(name string) (http.File, os.FileInfo, error) {
+	f, err := s.fs.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return f, info, nil
+}
+
+// serveFile 由 http.ServeContent 处理条件请求和 Range 请求，客户端支持 gzip 时优先返回预压缩的 .gz 文件
+func (s server) serveFile(w http.ResponseWriter, r *http.Request, name string, f http.File, info os.FileInfo,
+	cacheControl string) {
+	header := w.Header()
+	content, modTime, etag := f, info.ModTime(), etagOf(info, "")
+
+	if gz, gzInfo, err := s.open(name + gzipExt); err == nil {
+		defer gz.Close()
+		if !gzInfo.IsDir() {
+			header.Add("Vary", "Accept-Encoding")
+			if acceptsGzip(r) {
+				contentType := mime.TypeByExtension(path.Ext(name))
+				if len(contentType) == 0 {
+					contentType = "application/octet-stream"
+				}
+				header.Set("Content-Type", contentType)
+				header.Set("Content-Encoding", "gzip")
+				content, modTime, etag = gz, gzInfo.ModTime(), etagOf(gzInfo, "-gz")
+			}
+		}
+	}
+
+	header.Set("Etag", etag)
+	if len(cacheControl) > 0 {
+		header.Set("Cache-Control", cacheControl)
+	}
+	http.ServeContent(w, r, name, modTime, content)
+}
+
+func (s server) cacheControl(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	base := path.Base(name)
+	for _, rule := range s.rules {
+		if ok, _ := path.Match(rule.pattern, name); ok {
+			return rule.value
+		}
+		if !strings.Contains(rule.pattern, "/") {
+			if ok, _ := path.Match(rule.pattern, base); ok {
+				return rule.value
+			}
+		}
+	}
+
+	return ""
+}
+
+func etagOf(info os.FileInfo, suffix string) string {
+	return fmt.Sprintf(`"%x-%x%s"`, info.ModTime().UnixNano(), info.Size(), suffix)
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		encoding = strings.TrimSpace(encoding)
+		if encoding == "gzip" || strings.HasPrefix(encoding, "gzip;") && !strings.HasSuffix(encoding, "q=0") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// acceptsPage 判断是否为浏览器页面请求，不按扩展名判断，以免 /api/users 这类请求也返回索引页
+func acceptsPage(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// hidden 判断路径中是否有以 . 开头的文件或目录，如 .git、.env
+func hidden(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redirect(w http.ResponseWriter, r *http.Request, target string) {
+	if len(r.URL.RawQuery) > 0 {
+		target += "?" + r.URL.RawQuery
+	}
+	w.Header().Set("Location", target)
+	w.WriteHeader(http.StatusMovedPermanently)
+}
@@ -0,0 +1,135 @@
+package static
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.zc0901.com/go/god/lib/os/gres"
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareDir(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.html":      "<html>index</html>",
+		"assets/app.js":   "console.log('app')",
+		"docs/index.html": "<html>docs</html>",
+		".env":            "SECRET=1",
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(file), os.ModePerm))
+		assert.Nil(t, ioutil.WriteFile(file, []byte(content), os.ModePerm))
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(files["assets/app.js"]))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "assets/app.js.gz"), buf.Bytes(), os.ModePerm))
+
+	return dir
+}
+
+func serve(h http.Handler, path string, headers ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	dir := prepareDir(t)
+	h := Handler("/admin", http.Dir(dir),
+		WithCacheControl("assets/*", "public, max-age=31536000"),
+		WithCacheControl("*.html", "no-cache"),
+	)(http.NotFoundHandler())
+
+	w := serve(h, "/admin/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html>index</html>", w.Body.String())
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	etag := w.Header().Get("Etag")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+
+	w = serve(h, "/admin/", "If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = serve(h, "/admin/assets/app.js", "Range", "bytes=0-6")
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "console", w.Body.String())
+	assert.Equal(t, "public, max-age=31536000", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+	w = serve(h, "/admin/assets/app.js", "Accept-Encoding", "gzip, deflate")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+	reader, err := gzip.NewReader(w.Body)
+	assert.Nil(t, err)
+	content, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "console.log('app')", string(content))
+
+	w = serve(h, "/admin/docs")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "docs/", w.Header().Get("Location"))
+
+	for _, path := range []string{"/admin/.env", "/admin/missing", "/other/index.html", "/adminx/index.html"} {
+		assert.Equal(t, http.StatusNotFound, serve(h, path).Code, path)
+	}
+}
+
+func TestHandler_SPA(t *testing.T) {
+	dir := prepareDir(t)
+	h := Handler("/", http.Dir(dir), WithSPA(), WithCacheControl("*", "public, max-age=60"))(http.NotFoundHandler())
+
+	w := serve(h, "/users/1", "Accept", "text/html")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html>index</html>", w.Body.String())
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	w = serve(h, "/docs/v1.2", "Accept", "text/html,application/xhtml+xml")
+	assert.Equal(t, "<html>index</html>", w.Body.String())
+
+	// 非页面请求即使路径无扩展名也返回 404
+	assert.Equal(t, http.StatusNotFound, serve(h, "/assets/missing.js").Code)
+	assert.Equal(t, http.StatusNotFound, serve(h, "/api/users", "Accept", "application/json").Code)
+	assert.Equal(t, http.StatusNotFound, serve(h, "/users/1").Code)
+
+	r := httptest.NewRequest(http.MethodPost, "/users/1", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestResource(t *testing.T) {
+	dir := prepareDir(t)
+	data, err := gres.Pack(dir, "/www")
+	assert.Nil(t, err)
+	res := gres.New()
+	assert.Nil(t, res.Add(string(data)))
+
+	h := Handler("/", Resource(res, "/www"), WithSPA())(http.NotFoundHandler())
+	w := serve(h, "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html>index</html>", w.Body.String())
+
+	w = serve(h, "/assets/app.js", "Range", "bytes=8-10")
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "log", w.Body.String())
+
+	w = serve(h, "/users/1", "Accept", "text/html")
+	assert.Equal(t, "<html>index</html>", w.Body.String())
+}
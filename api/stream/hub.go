@@ -0,0 +1,93 @@
+package stream
+
+import (
+	"sync"
+
+	"git.zc0901.com/go/god/lib/lang"
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/threading"
+)
+
+// Hub 向一组长连接广播消息，连接关闭后自动离开，接收太慢的连接会被关闭。
+type Hub struct {
+	lock    sync.RWMutex
+	streams map[Stream]lang.PlaceholderType
+}
+
+// NewHub 新建广播中心
+func NewHub() *Hub {
+	return &Hub{
+		streams: make(map[Stream]lang.PlaceholderType),
+	}
+}
+
+// Join 加入一个长连接，连接关闭后自动离开
+func (h *Hub) Join(s Stream) {
+	h.lock.Lock()
+	if _, ok := h.streams[s]; ok {
+		h.lock.Unlock()
+		return
+	}
+	h.streams[s] = lang.Placeholder
+	h.lock.Unlock()
+
+	threading.GoSafe(func() {
+		<-s.Done()
+		h.Leave(s)
+	})
+}
+
+// Leave 移除一个长连接，不关闭连接
+func (h *Hub) Leave(s Stream) {
+	h.lock.Lock()
+	delete(h.streams, s)
+	h.lock.Unlock()
+}
+
+// Len 返回连接数
+func (h *Hub) Len() int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return len(h.streams)
+}
+
+// Broadcast 向所有连接发送消息
+func (h *Hub) Broadcast(data []byte) {
+	h.BroadcastFunc(data, nil)
+}
+
+// BroadcastFunc 向 filter 返回 true 的连接发送消息，filter 为空时发送给所有连接
+func (h *Hub) BroadcastFunc(data []byte, filter func(s Stream) bool) {
+	h.lock.RLock()
+	streams := make([]Stream, 0, len(h.streams))
+	for s := range h.streams {
+		if filter == nil || filter(s) {
+			streams = append(streams, s)
+		}
+	}
+	h.lock.RUnlock()
+
+	for _, s := range streams {
+		switch err := s.Send(data); err {
+		case nil:
+		case ErrSlowConsumer:
+			logx.Error("长连接接收太慢，已断开")
+			_ = s.Close()
+			h.Leave(s)
+		default:
+			h.Leave(s)
+		}
+	}
+}
+
+// Close 关闭所有连接
+func (h *Hub) Close() {
+	h.lock.Lock()
+	streams := h.streams
+	h.streams = make(map[Stream]lang.PlaceholderType)
+	h.lock.Unlock()
+
+	for s := range streams {
+		_ = s.Close()
+	}
+}
@@ -0,0 +1,143 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.zc0901.com/go/god/lib/threading"
+)
+
+var errNoFlusher = errors.New("ResponseWriter 不支持 Flush，无法推送 SSE")
+
+type (
+	// SSE 一个 Server-Sent Events 流。
+	// 处理函数创建后需等待 <-Done() 再返回，返回后不能再写入响应。
+	SSE struct {
+		*sender
+	}
+
+	// Event 一个 SSE 事件，Id、Event 和 Retry 为空时不发送
+	Event struct {
+		Id    string
+		Event string
+		Data  []byte
+		Retry time.Duration
+	}
+
+	// SSEOption 自定义 SSE 流的方法
+	SSEOption func(o *sseOptions)
+
+	sseOptions struct {
+		pingInterval time.Duration
+		sendBuffer   int
+	}
+)
+
+// NewSSE 新建 SSE 流，写入响应头并立即发送，默认每 30 秒发送一次注释行作为心跳，
+// 防止代理断开空闲连接。客户端断开或 api.Server 停止时 Done 返回的通道被关闭。
+func NewSSE(w http.ResponseWriter, r *http.Request, opts ...SSEOption) (*SSE, error) {
+	o := sseOptions{
+		pingInterval: defaultPingInterval,
+		sendBuffer:   defaultSendBuffer,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errNoFlusher
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// 禁止 nginx 缓冲响应
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	s := &SSE{
+		sender: newSender(o.sendBuffer, o.pingInterval),
+	}
+	s.write = func(data []byte) error {
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+	s.ping = func() error {
+		return s.write([]byte(":\n\n"))
+	}
+	threading.GoSafe(func() {
+		s.loop(r.Context().Done())
+	})
+
+	if err := track(r, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// WithSSEPingInterval 自定义心跳间隔，为 0 时不发送心跳
+func WithSSEPingInterval(interval time.Duration) SSEOption {
+	return func(o *sseOptions) {
+		o.pingInterval = interval
+	}
+}
+
+// WithSSESendBuffer 自定义发送缓冲的事件个数，默认 256
+func WithSSESendBuffer(size int) SSEOption {
+	return func(o *sseOptions) {
+		o.sendBuffer = size
+	}
+}
+
+// Send 发送一个只有数据的事件
+func (s *SSE) Send(data []byte) error {
+	return s.SendEvent(Event{Data: data})
+}
+
+// SendEvent 发送一个事件
+func (s *SSE) SendEvent(event Event) error {
+	return s.sender.Send(event.format())
+}
+
+// format 按 SSE 协议格式化事件，多行数据拆分为多个 data 字段
+func (e Event) format() []byte {
+	var buf bytes.Buffer
+	if len(e.Id) > 0 {
+		buf.WriteString("id: ")
+		buf.WriteString(singleLine(e.Id))
+		buf.WriteByte('\n')
+	}
+	if len(e.Event) > 0 {
+		buf.WriteString("event: ")
+		buf.WriteString(singleLine(e.Event))
+		buf.WriteByte('\n')
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry: ")
+		buf.WriteString(strconv.FormatInt(int64(e.Retry/time.Millisecond), 10))
+		buf.WriteByte('\n')
+	}
+	for _, line := range bytes.Split(e.Data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(bytes.TrimSuffix(line, []byte("\r")))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes()
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
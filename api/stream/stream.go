@@ -0,0 +1,205 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"git.zc0901.com/go/god/lib/lang"
+	"git.zc0901.com/go/god/lib/syncx"
+	"git.zc0901.com/go/god/lib/threading"
+)
+
+const (
+	defaultSendBuffer   = 256
+	defaultPingInterval = 30 * time.Second
+)
+
+var (
+	// ErrClosed 长连接已关闭
+	ErrClosed = errors.New("长连接已关闭")
+	// ErrSlowConsumer 客户端接收太慢，发送缓冲已满
+	ErrSlowConsumer = errors.New("客户端接收太慢，发送缓冲已满")
+
+	groupKey = contextKey{}
+)
+
+type (
+	// Stream 一个服务端推送的长连接，WebSocket 连接或 SSE 流
+	Stream interface {
+		// Send 将消息放入发送缓冲，由后台写入客户端，缓冲满时返回 ErrSlowConsumer
+		Send(data []byte) error
+		// Close 关闭连接，可多次调用
+		Close() error
+		// Done 连接关闭后返回的通道被关闭
+		Done() <-chan lang.PlaceholderType
+	}
+
+	// Group 跟踪一组长连接，服务停止时统一关闭
+	Group struct {
+		lock    sync.Mutex
+		streams map[Stream]lang.PlaceholderType
+		closed  bool
+	}
+
+	contextKey struct{}
+
+	// sender 长连接共用的发送循环，所有写操作都在同一个协程中执行
+	sender struct {
+		send     chan []byte
+		quit     *syncx.DoneChan
+		done     *syncx.DoneChan
+		interval time.Duration
+		write    func(data []byte) error
+		ping     func() error
+		cleanup  func()
+	}
+)
+
+// NewGroup 新建长连接组
+func NewGroup() *Group {
+	return &Group{
+		streams: make(map[Stream]lang.PlaceholderType),
+	}
+}
+
+// Handle 将长连接组放入请求上下文，Upgrade 和 NewSSE 创建的连接会加入该组
+func (g *Group) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), groupKey, g)))
+	})
+}
+
+// Len 返回活跃的长连接数
+func (g *Group) Len() int {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return len(g.streams)
+}
+
+// Close 关闭组内所有长连接，之后加入的连接会被立即关闭
+func (g *Group) Close() {
+	g.lock.Lock()
+	g.closed = true
+	streams := make([]Stream, 0, len(g.streams))
+	for s := range g.streams {
+		streams = append(streams, s)
+	}
+	g.lock.Unlock()
+
+	for _, s := range streams {
+		_ = s.Close()
+	}
+}
+
+func (g *Group) add(s Stream) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.closed {
+		return false
+	}
+
+	g.streams[s] = lang.Placeholder
+	threading.GoSafe(func() {
+		<-s.Done()
+		g.remove(s)
+	})
+	return true
+}
+
+func (g *Group) remove(s Stream) {
+	g.lock.Lock()
+	delete(g.streams, s)
+	g.lock.Unlock()
+}
+
+// track 将长连接加入请求上下文中的长连接组，服务已停止时关闭连接
+func track(r *http.Request, s Stream) error {
+	g, ok := r.Context().Value(groupKey).(*Group)
+	if !ok {
+		return nil
+	}
+
+	if !g.add(s) {
+		_ = s.Close()
+		return ErrClosed
+	}
+
+	return nil
+}
+
+func newSender(size int, interval time.Duration) *sender {
+	if size <= 0 {
+		size = defaultSendBuffer
+	}
+
+	return &sender{
+		send:     make(chan []byte, size),
+		quit:     syncx.NewDoneChan(),
+		done:     syncx.NewDoneChan(),
+		interval: interval,
+	}
+}
+
+func (s *sender) Send(data []byte) error {
+	select {
+	case <-s.quit.Done():
+		return ErrClosed
+	case <-s.done.Done():
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case s.send <- data:
+		return nil
+	default:
+		return ErrSlowConsumer
+	}
+}
+
+func (s *sender) Close() error {
+	s.quit.Close()
+	return nil
+}
+
+func (s *sender) Done() <-chan lang.PlaceholderType {
+	return s.done.Done()
+}
+
+// loop 发送缓冲中的消息并定时发送心跳，stop 关闭或写入失败时退出
+func (s *sender) loop(stop <-chan struct{}) {
+	defer func() {
+		if s.cleanup != nil {
+			s.cleanup()
+		}
+		s.done.Close()
+	}()
+
+	var tick <-chan time.Time
+	if s.interval > 0 && s.ping != nil {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case data := <-s.send:
+			if err := s.write(data); err != nil {
+				return
+			}
+		case <-tick:
+			if err := s.ping(); err != nil {
+				return
+			}
+		case <-s.quit.Done():
+			return
+		case <-stop:
+			return
+		}
+	}
+}
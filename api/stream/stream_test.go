@@ -0,0 +1,146 @@
+package stream
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocket(t *testing.T) {
+	group := NewGroup()
+	hub := NewHub()
+	svr := httptest.NewServer(group.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+
+		hub.Join(c)
+		for {
+			data, err := c.Read()
+			if err != nil {
+				return
+			}
+			assert.Nil(t, c.Send(append([]byte("echo: "), data...)))
+		}
+	})))
+	defer svr.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(svr.URL, "http"), nil)
+	assert.Nil(t, err)
+	defer client.Close()
+
+	assert.Nil(t, client.WriteMessage(websocket.TextMessage, []byte("hello")))
+	_, data, err := client.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, "echo: hello", string(data))
+
+	assert.Eventually(t, func() bool {
+		return hub.Len() == 1 && group.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+	hub.Broadcast([]byte("news"))
+	_, data, err = client.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, "news", string(data))
+
+	// 服务停止时以 1001 关闭连接
+	group.Close()
+	_, _, err = client.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	assert.Eventually(t, func() bool {
+		return hub.Len() == 0 && group.Len() == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocket_CheckOrigin(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := Upgrade(w, r)
+		assert.NotNil(t, err)
+	}))
+	defer svr.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(svr.URL, "http"), http.Header{
+		"Origin": []string{"http://evil.com"},
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestSSE(t *testing.T) {
+	group := NewGroup()
+	hub := NewHub()
+	svr := httptest.NewServer(group.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := NewSSE(w, r, WithSSEPingInterval(0))
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		assert.Nil(t, s.SendEvent(Event{Id: "1", Event: "greet", Data: []byte("hello\nworld")}))
+		hub.Join(s)
+		<-s.Done()
+	})))
+	defer svr.Close()
+
+	resp, err := http.Get(svr.URL)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "id: 1\nevent: greet\ndata: hello\ndata: world\n\n", readEvent(t, reader))
+
+	assert.Eventually(t, func() bool {
+		return hub.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+	hub.Broadcast([]byte("news"))
+	assert.Equal(t, "data: news\n\n", readEvent(t, reader))
+
+	// 服务停止时结束响应
+	group.Close()
+	_, err = reader.ReadString('\n')
+	assert.NotNil(t, err)
+}
+
+func TestSender_SlowConsumer(t *testing.T) {
+	s := newSender(1, 0)
+	assert.Nil(t, s.Send([]byte("a")))
+	assert.Equal(t, ErrSlowConsumer, s.Send([]byte("b")))
+	assert.Nil(t, s.Close())
+	assert.Equal(t, ErrClosed, s.Send([]byte("c")))
+}
+
+func TestGroup_Closed(t *testing.T) {
+	group := NewGroup()
+	group.Close()
+
+	svr := httptest.NewServer(group.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := NewSSE(w, r)
+		assert.Equal(t, ErrClosed, err)
+	})))
+	defer svr.Close()
+
+	resp, err := http.Get(svr.URL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	var event strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.Nil(t, err) {
+			return event.String()
+		}
+
+		event.WriteString(line)
+		if line == "\n" {
+			return event.String()
+		}
+	}
+}
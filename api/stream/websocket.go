@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"git.zc0901.com/go/god/lib/threading"
+	"github.com/gorilla/websocket"
+)
+
+const writeWait = 10 * time.Second
+
+type (
+	// Conn 一个 WebSocket 连接。
+	// Send 发送的消息由后台协程写入，读消息需在处理函数中循环调用 Read。
+	Conn struct {
+		*sender
+		conn *websocket.Conn
+	}
+
+	// UpgradeOption 自定义 WebSocket 升级的方法
+	UpgradeOption func(o *upgradeOptions)
+
+	upgradeOptions struct {
+		upgrader     websocket.Upgrader
+		pingInterval time.Duration
+		sendBuffer   int
+		readLimit    int64
+	}
+)
+
+// Upgrade 将请求升级为 WebSocket 连接，默认只允许同源请求，每 30 秒发送一次 ping。
+// 连接会在 api.Server 停止时以 1001（Going Away）关闭。
+func Upgrade(w http.ResponseWriter, r *http.Request, opts ...UpgradeOption) (*Conn, error) {
+	o := upgradeOptions{
+		pingInterval: defaultPingInterval,
+		sendBuffer:   defaultSendBuffer,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ws, err := o.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.readLimit > 0 {
+		ws.SetReadLimit(o.readLimit)
+	}
+	if o.pingInterval > 0 {
+		// 两个心跳周期内收不到任何消息或 pong 即认为连接已断开
+		_ = ws.SetReadDeadline(time.Now().Add(2 * o.pingInterval))
+		ws.SetPongHandler(func(string) error {
+			return ws.SetReadDeadline(time.Now().Add(2 * o.pingInterval))
+		})
+	}
+
+	c := &Conn{
+		sender: newSender(o.sendBuffer, o.pingInterval),
+		conn:   ws,
+	}
+	c.write = func(data []byte) error {
+		_ = ws.SetWriteDeadline(time.Now().Add(writeWait))
+		return ws.WriteMessage(websocket.TextMessage, data)
+	}
+	c.ping = func() error {
+		return ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+	}
+	c.cleanup = func() {
+		msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+		_ = ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+		_ = ws.Close()
+	}
+	threading.GoSafe(func() {
+		c.loop(nil)
+	})
+
+	if err = track(r, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// WithCheckOrigin 自定义跨域检查，默认只允许与 Host 相同的 Origin
+func WithCheckOrigin(check func(r *http.Request) bool) UpgradeOption {
+	return func(o *upgradeOptions) {
+		o.upgrader.CheckOrigin = check
+	}
+}
+
+// WithPingInterval 自定义心跳间隔，为 0 时不发送心跳也不检测读超时
+func WithPingInterval(interval time.Duration) UpgradeOption {
+	return func(o *upgradeOptions) {
+		o.pingInterval = interval
+	}
+}
+
+// WithSendBuffer 自定义发送缓冲的消息条数，默认 256
+func WithSendBuffer(size int) UpgradeOption {
+	return func(o *upgradeOptions) {
+		o.sendBuffer = size
+	}
+}
+
+// WithReadLimit 自定义单条消息的最大字节数
+func WithReadLimit(limit int64) UpgradeOption {
+	return func(o *upgradeOptions) {
+		o.readLimit = limit
+	}
+}
+
+// WithSubprotocols 自定义服务端支持的子协议
+func WithSubprotocols(protocols ...string) UpgradeOption {
+	return func(o *upgradeOptions) {
+		o.upgrader.Subprotocols = protocols
+	}
+}
+
+// Read 读取一条消息，连接断开或关闭时返回错误，此时应结束处理函数
+func (c *Conn) Read() ([]byte, error) {
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ReadJSON 读取一条 JSON 消息
+func (c *Conn) ReadJSON(v interface{}) error {
+	data, err := c.Read()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// SendJSON 将 v 编排为 JSON 后发送
+func (c *Conn) SendJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.Send(data)
+}
+
+// Subprotocol 返回协商的子协议
+func (c *Conn) Subprotocol() string {
+	return c.conn.Subprotocol()
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"git.zc0901.com/go/god/lib/logx"
+)
+
+const (
+	swaggerPath   = "/swagger"
+	swaggerUiPath = "https://unpkg.com/swagger-ui-dist@3"
+
+	swaggerPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Swagger UI</title>
+  <link rel="stylesheet" href="%[1]s/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="%[1]s/swagger-ui-bundle.js"></script>
+<script>
+  window.ui = SwaggerUIBundle({url: "%[2]s", dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`
+)
+
+// WithSwagger 在 /swagger 提供 Swagger UI 页面，在 /swagger/openapi.json（或 .yaml）提供 file 中的 OpenAPI 文档，
+// 文档可通过 god api swagger 生成。Swagger UI 的静态资源从 CDN 加载，文档路由不参与自适应降载。
+func WithSwagger(file string) RunOption {
+	content, err := ioutil.ReadFile(file)
+	logx.Must(err)
+
+	contentType, name := "application/json", "openapi.json"
+	if ext := strings.ToLower(filepath.Ext(file)); ext == ".yaml" || ext == ".yml" {
+		contentType, name = "application/yaml", "openapi.yaml"
+	}
+	docPath := path.Join(swaggerPath, name)
+	page := []byte(fmt.Sprintf(swaggerPage, swaggerUiPath, docPath))
+
+	return func(server *Server) {
+		server.AddRoutes([]Route{
+			{
+				Method: http.MethodGet,
+				Path:   swaggerPath,
+				Handler: func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "text/html; charset=utf-8")
+					w.Write(page)
+				},
+			},
+			{
+				Method: http.MethodGet,
+				Path:   docPath,
+				Handler: func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", contentType)
+					w.Write(content)
+				},
+			},
+		}, WithoutShedding())
+	}
+}
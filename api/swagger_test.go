@@ -0,0 +1,35 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSwagger(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "openapi.yaml")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("openapi: 3.0.3\n"), 0644))
+
+	server := &Server{engine: newEngine(Conf{})}
+	WithSwagger(file)(server)
+
+	routes := server.engine.routes
+	assert.Len(t, routes, 1)
+	assert.True(t, routes[0].noShedding)
+	assert.Equal(t, "/swagger", routes[0].routes[0].Path)
+	assert.Equal(t, "/swagger/openapi.yaml", routes[0].routes[1].Path)
+
+	w := httptest.NewRecorder()
+	routes[0].routes[0].Handler(w, httptest.NewRequest(http.MethodGet, "/swagger", nil))
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `url: "/swagger/openapi.yaml"`)
+
+	w = httptest.NewRecorder()
+	routes[0].routes[1].Handler(w, httptest.NewRequest(http.MethodGet, "/swagger/openapi.yaml", nil))
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+	assert.Equal(t, "openapi: 3.0.3\n", w.Body.String())
+}
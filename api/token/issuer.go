@@ -0,0 +1,370 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"git.zc0901.com/go/god/lib/store/redis"
+	"git.zc0901.com/go/god/lib/stringx"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	defaultAccessExpire  = time.Hour
+	defaultRefreshExpire = time.Hour * 24 * 7
+	defaultKeyPrefix     = "token"
+
+	refreshTokenBytes = 32
+	jtiBytes          = 16
+
+	// 取出并删除刷新令牌，保证同一刷新令牌只能轮换一次
+	takeScript = `local v = redis.call("GET", KEYS[1])
+if v then
+	redis.call("DEL", KEYS[1])
+end
+return v`
+)
+
+var (
+	ErrNoStore              = errors.New("未设置刷新令牌存储")
+	ErrInvalidRefreshToken  = errors.New("无效的刷新令牌")
+	ErrRefreshTokenReused   = errors.New("刷新令牌被重复使用，已吊销该令牌家族")
+	ErrRefreshTokenRevoked  = errors.New("刷新令牌已被吊销")
+	errInvalidRefreshRecord = errors.New("无效的刷新令牌记录")
+
+	standardClaims = map[string]bool{
+		"aud": true,
+		"exp": true,
+		"jti": true,
+		"iat": true,
+		"iss": true,
+		"nbf": true,
+		"sub": true,
+	}
+)
+
+type (
+	// Issuer 签发访问令牌和刷新令牌。
+	// 访问令牌默认使用与 api.WithJwt 相同的秘钥以 HS256 签名，也可通过 WithSigningKey 使用私钥签名；
+	// 刷新令牌为随机字符串，保存在 redis 中，每次刷新都会轮换，旧令牌被重复使用时吊销整个令牌家族。
+	Issuer struct {
+		method        jwt.SigningMethod
+		key           interface{}
+		kid           string
+		issuer        string
+		audiences     []string
+		accessExpire  time.Duration
+		refreshExpire time.Duration
+		store         *redis.Redis
+		keyPrefix     string
+	}
+
+	// IssuerOption 自定义 Issuer 的方法
+	IssuerOption func(issuer *Issuer)
+
+	// Pair 一次签发的访问令牌和刷新令牌，过期时间为 Unix 秒
+	Pair struct {
+		AccessToken   string `json:"accessToken"`
+		AccessExpire  int64  `json:"accessExpire"`
+		RefreshToken  string `json:"refreshToken,omitempty"`
+		RefreshExpire int64  `json:"refreshExpire,omitempty"`
+	}
+
+	refreshRecord struct {
+		Subject  string                 `json:"sub"`
+		Family   string                 `json:"family"`
+		Payloads map[string]interface{} `json:"payloads,omitempty"`
+	}
+)
+
+// NewIssuer 新建令牌签发者，secret 与 api.WithJwt 的秘钥相同
+func NewIssuer(secret string, opts ...IssuerOption) *Issuer {
+	issuer := &Issuer{
+		method:        jwt.SigningMethodHS256,
+		key:           []byte(secret),
+		accessExpire:  defaultAccessExpire,
+		refreshExpire: defaultRefreshExpire,
+		keyPrefix:     defaultKeyPrefix,
+	}
+	for _, opt := range opts {
+		opt(issuer)
+	}
+
+	return issuer
+}
+
+// WithSigningKey 使用私钥签名访问令牌，如 RS256 的 *rsa.PrivateKey、EdDSA 的 ed25519.PrivateKey，
+// kid 写入令牌头部，便于验证方通过 JWKS 选择公钥。
+func WithSigningKey(method jwt.SigningMethod, key interface{}, kid string) IssuerOption {
+	return func(issuer *Issuer) {
+		issuer.method = method
+		issuer.key = key
+		issuer.kid = kid
+	}
+}
+
+// WithIssuerName 自定义令牌的签发者 iss
+func WithIssuerName(name string) IssuerOption {
+	return func(issuer *Issuer) {
+		issuer.issuer = name
+	}
+}
+
+// WithAudiences 自定义令牌的受众 aud
+func WithAudiences(audiences ...string) IssuerOption {
+	return func(issuer *Issuer) {
+		issuer.audiences = audiences
+	}
+}
+
+// WithAccessExpire 自定义访问令牌有效期，默认一小时
+func WithAccessExpire(expire time.Duration) IssuerOption {
+	return func(issuer *Issuer) {
+		issuer.accessExpire = expire
+	}
+}
+
+// WithRefreshExpire 自定义刷新令牌有效期，默认七天
+func WithRefreshExpire(expire time.Duration) IssuerOption {
+	return func(issuer *Issuer) {
+		issuer.refreshExpire = expire
+	}
+}
+
+// WithStore 设置保存刷新令牌和吊销记录的 redis，prefix 为键前缀，为空时使用 token
+func WithStore(store *redis.Redis, prefix string) IssuerOption {
+	return func(issuer *Issuer) {
+		issuer.store = store
+		if len(prefix) > 0 {
+			issuer.keyPrefix = prefix
+		}
+	}
+}
+
+// IssueAccess 仅签发访问令牌，payloads 中的非标准声明会由 Authorize 放入请求上下文
+func (is *Issuer) IssueAccess(subject string, payloads map[string]interface{}) (Pair, error) {
+	now := time.Now()
+	claims := make(jwt.MapClaims, len(payloads)+6)
+	for k, v := range payloads {
+		if !standardClaims[k] {
+			claims[k] = v
+		}
+	}
+
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(is.accessExpire).Unix()
+	claims["jti"] = randomHex(jtiBytes)
+	if len(subject) > 0 {
+		claims["sub"] = subject
+	}
+	if len(is.issuer) > 0 {
+		claims["iss"] = is.issuer
+	}
+	switch len(is.audiences) {
+	case 0:
+	case 1:
+		claims["aud"] = is.audiences[0]
+	default:
+		claims["aud"] = is.audiences
+	}
+
+	token := jwt.NewWithClaims(is.method, claims)
+	if len(is.kid) > 0 {
+		token.Header["kid"] = is.kid
+	}
+	signed, err := token.SignedString(is.key)
+	if err != nil {
+		return Pair{}, err
+	}
+
+	return Pair{
+		AccessToken:  signed,
+		AccessExpire: claims["exp"].(int64),
+	}, nil
+}
+
+// Issue 签发访问令牌和刷新令牌，需要通过 WithStore 设置 redis
+func (is *Issuer) Issue(subject string, payloads map[string]interface{}) (Pair, error) {
+	return is.issue(refreshRecord{
+		Subject:  subject,
+		Family:   randomHex(jtiBytes),
+		Payloads: payloads,
+	})
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌，旧刷新令牌随即失效。
+// 已轮换过的刷新令牌再次使用说明可能被盗用，此时吊销整个令牌家族并返回 ErrRefreshTokenReused。
+func (is *Issuer) Refresh(refreshToken string) (Pair, error) {
+	if is.store == nil {
+		return Pair{}, ErrNoStore
+	}
+
+	val, err := is.store.Eval(takeScript, []string{is.refreshKey(refreshToken)})
+	if err == redis.Nil {
+		return Pair{}, is.checkReused(refreshToken)
+	} else if err != nil {
+		return Pair{}, err
+	}
+
+	data, ok := val.(string)
+	if !ok {
+		return Pair{}, errInvalidRefreshRecord
+	}
+	var record refreshRecord
+	if err = json.Unmarshal([]byte(data), &record); err != nil {
+		return Pair{}, err
+	}
+
+	// 记录已轮换的令牌，用于发现重复使用
+	if err = is.store.SetEx(is.usedKey(refreshToken), record.Family, is.refreshSeconds()); err != nil {
+		return Pair{}, err
+	}
+
+	active, err := is.store.Exists(is.familyKey(record.Family))
+	if err != nil {
+		return Pair{}, err
+	}
+	if !active {
+		return Pair{}, ErrRefreshTokenRevoked
+	}
+
+	return is.issue(record)
+}
+
+// RevokeRefresh 吊销刷新令牌及由它轮换出的所有刷新令牌，如用户退出登录时
+func (is *Issuer) RevokeRefresh(refreshToken string) error {
+	if is.store == nil {
+		return ErrNoStore
+	}
+
+	data, err := is.store.Get(is.refreshKey(refreshToken))
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return ErrInvalidRefreshToken
+	}
+
+	var record refreshRecord
+	if err = json.Unmarshal([]byte(data), &record); err != nil {
+		return err
+	}
+
+	_, err = is.store.Del(is.refreshKey(refreshToken), is.familyKey(record.Family))
+	return err
+}
+
+// RevokeAccess 将访问令牌的 jti 加入黑名单直到 expire（Unix 秒），
+// 配合 handler.WithRevokedCheck(issuer.IsRevoked) 拒绝已吊销的访问令牌。
+func (is *Issuer) RevokeAccess(jti string, expire int64) error {
+	if is.store == nil {
+		return ErrNoStore
+	}
+
+	seconds := expire - time.Now().Unix()
+	if seconds <= 0 {
+		return nil
+	}
+
+	return is.store.SetEx(is.revokedKey(jti), "1", int(seconds))
+}
+
+// IsRevoked 判断访问令牌的 jti 是否已被吊销
+func (is *Issuer) IsRevoked(jti string) (bool, error) {
+	if is.store == nil {
+		return false, ErrNoStore
+	}
+
+	return is.store.Exists(is.revokedKey(jti))
+}
+
+func (is *Issuer) issue(record refreshRecord) (Pair, error) {
+	if is.store == nil {
+		return Pair{}, ErrNoStore
+	}
+
+	pair, err := is.IssueAccess(record.Subject, record.Payloads)
+	if err != nil {
+		return Pair{}, err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return Pair{}, err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return Pair{}, err
+	}
+
+	seconds := is.refreshSeconds()
+	if err = is.store.SetEx(is.familyKey(record.Family), "1", seconds); err != nil {
+		return Pair{}, err
+	}
+	if err = is.store.SetEx(is.refreshKey(refreshToken), string(data), seconds); err != nil {
+		return Pair{}, err
+	}
+
+	pair.RefreshToken = refreshToken
+	pair.RefreshExpire = time.Now().Add(is.refreshExpire).Unix()
+	return pair, nil
+}
+
+func (is *Issuer) checkReused(refreshToken string) error {
+	family, err := is.store.Get(is.usedKey(refreshToken))
+	if err != nil {
+		return err
+	}
+	if len(family) == 0 {
+		return ErrInvalidRefreshToken
+	}
+
+	if _, err = is.store.Del(is.familyKey(family)); err != nil {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+func (is *Issuer) refreshSeconds() int {
+	return int(is.refreshExpire / time.Second)
+}
+
+func (is *Issuer) refreshKey(refreshToken string) string {
+	return is.keyPrefix + ":refresh:" + refreshToken
+}
+
+func (is *Issuer) usedKey(refreshToken string) string {
+	return is.keyPrefix + ":used:" + refreshToken
+}
+
+func (is *Issuer) familyKey(family string) string {
+	return is.keyPrefix + ":family:" + family
+}
+
+func (is *Issuer) revokedKey(jti string) string {
+	return is.keyPrefix + ":revoked:" + jti
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return stringx.Randn(n * 2)
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const issuerSecret = "14F17379-EB8F-411B-8F12-6929002DCA76"
+
+func TestIssuer_IssueAccess(t *testing.T) {
+	issuer := NewIssuer(issuerSecret, WithIssuerName("god"), WithAudiences("api"),
+		WithAccessExpire(time.Minute))
+	pair, err := issuer.IssueAccess("1", map[string]interface{}{
+		"role": "admin",
+		"exp":  0,
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, pair.RefreshToken)
+	assert.InDelta(t, time.Now().Add(time.Minute).Unix(), pair.AccessExpire, 1)
+
+	claims := parseAccess(t, pair.AccessToken)
+	assert.Equal(t, "1", claims["sub"])
+	assert.Equal(t, "admin", claims["role"])
+	assert.Equal(t, "god", claims["iss"])
+	assert.Equal(t, "api", claims["aud"])
+	assert.NotEmpty(t, claims["jti"])
+
+	_, err = issuer.Issue("1", nil)
+	assert.Equal(t, ErrNoStore, err)
+}
+
+func TestIssuer_SigningKey(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	pair, err := NewIssuer("", WithSigningKey(SigningMethodEdDSA, key, "ed")).IssueAccess("1", nil)
+	assert.Nil(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	tok, err := NewTokenParser().ParseTokenWithKeys(req, &PemKeys{keys: []publicKey{{kid: "ed", key: pub}}})
+	assert.Nil(t, err)
+	assert.Equal(t, "ed", tok.Header["kid"])
+}
+
+func TestIssuer_Refresh(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	issuer := NewIssuer(issuerSecret, WithStore(r, "test"))
+	pair, err := issuer.Issue("1", map[string]interface{}{"role": "admin"})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, pair.RefreshToken)
+
+	rotated, err := issuer.Refresh(pair.RefreshToken)
+	assert.Nil(t, err)
+	assert.NotEqual(t, pair.RefreshToken, rotated.RefreshToken)
+	assert.Equal(t, "admin", parseAccess(t, rotated.AccessToken)["role"])
+
+	// 旧令牌被重复使用，整个令牌家族被吊销
+	_, err = issuer.Refresh(pair.RefreshToken)
+	assert.Equal(t, ErrRefreshTokenReused, err)
+	_, err = issuer.Refresh(rotated.RefreshToken)
+	assert.Equal(t, ErrRefreshTokenRevoked, err)
+
+	_, err = issuer.Refresh("unknown")
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+}
+
+func TestIssuer_Revoke(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	issuer := NewIssuer(issuerSecret, WithStore(r, ""))
+	pair, err := issuer.Issue("1", nil)
+	assert.Nil(t, err)
+
+	assert.Nil(t, issuer.RevokeRefresh(pair.RefreshToken))
+	_, err = issuer.Refresh(pair.RefreshToken)
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Equal(t, ErrInvalidRefreshToken, issuer.RevokeRefresh(pair.RefreshToken))
+
+	jti := parseAccess(t, pair.AccessToken)["jti"].(string)
+	revoked, err := issuer.IsRevoked(jti)
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+
+	assert.Nil(t, issuer.RevokeAccess(jti, pair.AccessExpire))
+	revoked, err = issuer.IsRevoked(jti)
+	assert.Nil(t, err)
+	assert.True(t, revoked)
+
+	// 已过期的令牌无需吊销
+	assert.Nil(t, issuer.RevokeAccess("expired", time.Now().Unix()-1))
+	revoked, err = issuer.IsRevoked("expired")
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+}
+
+func parseAccess(t *testing.T, token string) jwt.MapClaims {
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	tok, err := NewTokenParser().ParseToken(req, issuerSecret, "")
+	assert.Nil(t, err)
+	return tok.Claims.(jwt.MapClaims)
+}
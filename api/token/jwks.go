@@ -0,0 +1,303 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/syncx"
+	"git.zc0901.com/go/god/lib/threading"
+	"git.zc0901.com/go/god/lib/timex"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	defaultJwksRefreshInterval = time.Hour
+	// 遇到未知 kid 时重新加载的最小间隔，防止伪造 kid 的请求频繁拉取
+	jwksMinRefreshInterval = time.Minute
+	jwksTimeout            = 10 * time.Second
+)
+
+var errJwksStatus = errors.New("获取 JWKS 失败")
+
+type (
+	// Jwks 从文件或 HTTP 地址加载的 JSON Web Key Set，按 kid 选择公钥。
+	// 公钥在后台定期重新加载，遇到未知 kid 时也会立即重新加载以支持身份提供方轮换密钥。
+	Jwks struct {
+		source          string
+		client          *http.Client
+		refreshInterval time.Duration
+		barrier         syncx.SingleFlight
+		done            *syncx.DoneChan
+		lock            sync.RWMutex
+		keys            []publicKey
+		refreshed       time.Duration
+	}
+
+	// JwksOption 自定义 Jwks 的方法
+	JwksOption func(jwks *Jwks)
+
+	jsonWebKeySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	jsonWebKey struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+)
+
+// NewJwks 新建 Jwks，source 为 JWKS 文件路径或 http(s) 地址，首次加载失败时返回错误。
+// 加载成功后在后台定期重新加载，不再使用时调用 Stop 停止。
+func NewJwks(source string, opts ...JwksOption) (*Jwks, error) {
+	jwks := &Jwks{
+		source:          source,
+		client:          &http.Client{Timeout: jwksTimeout},
+		refreshInterval: defaultJwksRefreshInterval,
+		barrier:         syncx.NewSingleFlight(),
+		done:            syncx.NewDoneChan(),
+	}
+	for _, opt := range opts {
+		opt(jwks)
+	}
+
+	if err := jwks.refresh(); err != nil {
+		return nil, err
+	}
+
+	if jwks.refreshInterval > 0 {
+		threading.GoSafe(jwks.refreshLoop)
+	}
+
+	return jwks, nil
+}
+
+// WithJwksRefreshInterval 自定义 JWKS 后台重新加载的间隔，默认一小时，<=0 表示不定期重新加载
+func WithJwksRefreshInterval(interval time.Duration) JwksOption {
+	return func(jwks *Jwks) {
+		jwks.refreshInterval = interval
+	}
+}
+
+// WithJwksClient 自定义获取 JWKS 的 HTTP 客户端
+func WithJwksClient(client *http.Client) JwksOption {
+	return func(jwks *Jwks) {
+		jwks.client = client
+	}
+}
+
+// Key 实现 KeyProvider 接口，只在 kid 未知时同步重新加载。
+func (j *Jwks) Key(token *jwt.Token) (interface{}, error) {
+	key, err := selectKey(j.loadKeys(), token, true)
+	if err == nil {
+		return key, nil
+	}
+
+	// 可能是身份提供方刚轮换了密钥
+	if timex.Since(j.lastRefreshed()) < jwksMinRefreshInterval {
+		return nil, err
+	}
+
+	j.tryRefresh()
+	return selectKey(j.loadKeys(), token, true)
+}
+
+// Stop 停止后台定期重新加载
+func (j *Jwks) Stop() {
+	j.done.Close()
+}
+
+func (j *Jwks) refreshLoop() {
+	ticker := time.NewTicker(j.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-j.done.Done():
+			return
+		case <-ticker.C:
+			j.tryRefresh()
+		}
+	}
+}
+
+func (j *Jwks) tryRefresh() {
+	// 加载失败时继续使用之前的公钥
+	if err := j.refresh(); err != nil {
+		logx.Errorf("加载 JWKS 失败，来源：%s，错误：%v", j.source, err)
+	}
+}
+
+func (j *Jwks) refresh() error {
+	_, _, err := j.barrier.Do(j.source, func() (interface{}, error) {
+		data, err := j.load()
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := parseJwks(data)
+		if err != nil {
+			return nil, err
+		}
+
+		j.lock.Lock()
+		j.keys = keys
+		j.refreshed = timex.Now()
+		j.lock.Unlock()
+
+		return nil, nil
+	})
+
+	if err != nil {
+		// 失败也更新加载时间，避免每个请求都重新加载
+		j.lock.Lock()
+		j.refreshed = timex.Now()
+		j.lock.Unlock()
+	}
+
+	return err
+}
+
+func (j *Jwks) load() ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return ioutil.ReadFile(j.source)
+	}
+
+	resp, err := j.client.Get(j.source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", errJwksStatus, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (j *Jwks) loadKeys() []publicKey {
+	j.lock.RLock()
+	defer j.lock.RUnlock()
+	return j.keys
+}
+
+func (j *Jwks) lastRefreshed() time.Duration {
+	j.lock.RLock()
+	defer j.lock.RUnlock()
+	return j.refreshed
+}
+
+// parseJwks 解析 JWKS 中用于签名的 RSA、EC 和 Ed25519 公钥，跳过不支持的公钥
+func parseJwks(data []byte) ([]publicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []publicKey
+	for _, k := range set.Keys {
+		if len(k.Use) > 0 && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			logx.Errorf("跳过无效的 JWK，kid：%s，错误：%v", k.Kid, err)
+			continue
+		}
+
+		keys = append(keys, publicKey{
+			kid: k.Kid,
+			alg: k.Alg,
+			key: key,
+		})
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoPublicKey
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("不支持的椭圆曲线：%s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC 公钥不在曲线上")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("不支持的曲线：%s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errEd25519Key
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("不支持的密钥类型：%s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,166 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	ErrNoPublicKey = errors.New("没有找到可验证令牌的公钥")
+
+	// 使用公钥验证时允许的签名算法，拒绝 HS256 等对称算法，防止算法混淆攻击
+	asymmetricMethods = []string{
+		"RS256", "RS384", "RS512",
+		"PS256", "PS384", "PS512",
+		"ES256", "ES384", "ES512",
+		"EdDSA",
+	}
+)
+
+type (
+	// KeyProvider 根据令牌头部的 alg 和 kid 提供验签公钥
+	KeyProvider interface {
+		Key(token *jwt.Token) (interface{}, error)
+	}
+
+	// PemKeys 从 PEM 文件加载的一组公钥
+	PemKeys struct {
+		keys []publicKey
+	}
+
+	publicKey struct {
+		kid string
+		alg string
+		key interface{}
+	}
+)
+
+// NewPemKeys 从 PEM 文件加载公钥，支持 PUBLIC KEY、RSA PUBLIC KEY 和 CERTIFICATE。
+// 文件名（不含扩展名）作为 kid，令牌没有 kid 或 kid 不匹配时使用第一个类型匹配的公钥。
+func NewPemKeys(files ...string) (*PemKeys, error) {
+	var pk PemKeys
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := ParsePemKeys(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		for _, key := range keys {
+			pk.keys = append(pk.keys, publicKey{
+				kid: kid,
+				key: key,
+			})
+		}
+	}
+
+	if len(pk.keys) == 0 {
+		return nil, ErrNoPublicKey
+	}
+
+	return &pk, nil
+}
+
+// Key 实现 KeyProvider 接口
+func (pk *PemKeys) Key(token *jwt.Token) (interface{}, error) {
+	return selectKey(pk.keys, token, false)
+}
+
+// ParsePemKeys 解析 PEM 数据中的所有公钥
+func ParsePemKeys(data []byte) ([]interface{}, error) {
+	var keys []interface{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case "RSA PUBLIC KEY":
+			key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, cert.PublicKey)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoPublicKey
+	}
+
+	return keys, nil
+}
+
+// selectKey 优先按 kid 选择公钥，否则选择第一个与签名算法匹配的公钥。
+// strict 为 true 时，令牌带有 kid 则必须找到 kid 相同的公钥。
+func selectKey(keys []publicKey, token *jwt.Token, strict bool) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if len(kid) > 0 {
+		for _, key := range keys {
+			if key.kid == kid && matchKey(token.Method, key) {
+				return key.key, nil
+			}
+		}
+
+		if strict {
+			return nil, ErrNoPublicKey
+		}
+	}
+
+	for _, key := range keys {
+		if matchKey(token.Method, key) {
+			return key.key, nil
+		}
+	}
+
+	return nil, ErrNoPublicKey
+}
+
+func matchKey(method jwt.SigningMethod, key publicKey) bool {
+	if len(key.alg) > 0 && key.alg != method.Alg() {
+		return false
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := key.key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		_, ok := key.key.(*ecdsa.PublicKey)
+		return ok
+	case *SigningMethodEd25519:
+		_, ok := key.key.(ed25519.PublicKey)
+		return ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,227 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.zc0901.com/go/god/lib/timex"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTokenWithPemKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	dir, err := ioutil.TempDir("", "god-token")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	rsaFile := writePem(t, dir, "rsa.pem", &rsaKey.PublicKey)
+	edFile := writePem(t, dir, "ed.pem", edPub)
+
+	keys, err := NewPemKeys(rsaFile, edFile)
+	assert.Nil(t, err)
+	parser := NewTokenParser()
+
+	tok, err := parser.ParseTokenWithKeys(newRequest(t, jwt.SigningMethodRS256, rsaKey, "rsa"), keys)
+	assert.Nil(t, err)
+	assert.Equal(t, "value", tok.Claims.(jwt.MapClaims)["key"])
+
+	// 没有 kid 时按算法选择公钥
+	tok, err = parser.ParseTokenWithKeys(newRequest(t, SigningMethodEdDSA, edKey, ""), keys)
+	assert.Nil(t, err)
+	assert.Equal(t, "value", tok.Claims.(jwt.MapClaims)["key"])
+
+	// 拒绝对称算法签名的令牌
+	_, err = parser.ParseTokenWithKeys(newRequest(t, jwt.SigningMethodHS256, []byte("secret"), ""), keys)
+	assert.NotNil(t, err)
+
+	// 没有匹配的公钥
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	_, err = parser.ParseTokenWithKeys(newRequest(t, jwt.SigningMethodES256, ecKey, ""), keys)
+	assert.NotNil(t, err)
+
+	_, err = NewPemKeys(filepath.Join(dir, "none.pem"))
+	assert.NotNil(t, err)
+}
+
+func TestParseTokenWithJwks(t *testing.T) {
+	key1, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	key2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	var rotated int32
+	var loads int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&loads, 1)
+		keys := []map[string]string{ecJwk("k1", &key1.PublicKey)}
+		if atomic.LoadInt32(&rotated) > 0 {
+			keys = []map[string]string{ecJwk("k2", &key2.PublicKey)}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer svr.Close()
+
+	jwks, err := NewJwks(svr.URL)
+	assert.Nil(t, err)
+	defer jwks.Stop()
+	parser := NewTokenParser()
+
+	_, err = parser.ParseTokenWithKeys(newRequest(t, jwt.SigningMethodES256, key1, "k1"), jwks)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+
+	// 身份提供方轮换密钥，刚加载过时不会因未知 kid 重新加载
+	atomic.StoreInt32(&rotated, 1)
+	_, err = parser.ParseTokenWithKeys(newRequest(t, jwt.SigningMethodES256, key2, "k2"), jwks)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+
+	jwks.lock.Lock()
+	jwks.refreshed = timex.Now() - jwksMinRefreshInterval
+	jwks.lock.Unlock()
+	_, err = parser.ParseTokenWithKeys(newRequest(t, jwt.SigningMethodES256, key2, "k2"), jwks)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+
+	_, err = NewJwks(svr.URL + "/none\x00")
+	assert.NotNil(t, err)
+}
+
+func TestJwks_BackgroundRefresh(t *testing.T) {
+	key1, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	key2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	var rotated int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := []map[string]string{ecJwk("k1", &key1.PublicKey)}
+		if atomic.LoadInt32(&rotated) > 0 {
+			keys = []map[string]string{ecJwk("k1", &key2.PublicKey)}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer svr.Close()
+
+	jwks, err := NewJwks(svr.URL, WithJwksRefreshInterval(10*time.Millisecond))
+	assert.Nil(t, err)
+	defer jwks.Stop()
+	parser := NewTokenParser()
+
+	// kid 不变而公钥更换时，由后台定期加载更新
+	atomic.StoreInt32(&rotated, 1)
+	assert.Eventually(t, func() bool {
+		_, err := parser.ParseTokenWithKeys(newRequest(t, jwt.SigningMethodES256, key2, "k1"), jwks)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestParseJwks(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	data, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "OKP", "crv": "Ed25519", "kid": "ed", "x": base64.RawURLEncoding.EncodeToString(edPub)},
+			{"kty": "RSA", "kid": "rsa", "alg": "RS256",
+				"n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString([]byte{1, 0, 1})},
+			{"kty": "RSA", "kid": "enc", "use": "enc"},
+			{"kty": "oct", "kid": "hmac"},
+		},
+	})
+	assert.Nil(t, err)
+
+	keys, err := parseJwks(data)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(keys))
+	assert.Equal(t, edPub, keys[0].key)
+	assert.Equal(t, rsaKey.PublicKey, *keys[1].key.(*rsa.PublicKey))
+
+	// 公钥声明了 alg 时只用于该算法
+	assert.False(t, matchKey(jwt.SigningMethodRS512, keys[1]))
+	assert.True(t, matchKey(jwt.SigningMethodRS256, keys[1]))
+
+	_, err = parseJwks([]byte(`{"keys":[]}`))
+	assert.Equal(t, ErrNoPublicKey, err)
+}
+
+func TestTokenParser_Leeway(t *testing.T) {
+	const key = "14F17379-EB8F-411B-8F12-6929002DCA76"
+	token, err := buildToken(key, map[string]interface{}{
+		"key": "value",
+	}, -5)
+	assert.Nil(t, err)
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	_, err = NewTokenParser().ParseToken(req, key, "")
+	assert.NotNil(t, err)
+
+	tok, err := NewTokenParser(WithLeeway(time.Minute)).ParseToken(req, key, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", tok.Claims.(jwt.MapClaims)["key"])
+
+	_, err = NewTokenParser(WithLeeway(time.Second)).ParseToken(req, key, "")
+	assert.NotNil(t, err)
+}
+
+func newRequest(t *testing.T, method jwt.SigningMethod, key interface{}, kid string) *http.Request {
+	tok := jwt.NewWithClaims(method, jwt.MapClaims{
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"key": "value",
+	})
+	if len(kid) > 0 {
+		tok.Header["kid"] = kid
+	}
+	signed, err := tok.SignedString(key)
+	assert.Nil(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	return req
+}
+
+func writePem(t *testing.T, dir, name string, key interface{}) string {
+	data, err := x509.MarshalPKIXPublicKey(key)
+	assert.Nil(t, err)
+
+	file := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: data,
+	}), 0644))
+	return file
+}
+
+func ecJwk(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"kid": kid,
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}
+}
@@ -18,6 +18,7 @@ type (
 		resetTime     time.Duration
 		resetDuration time.Duration
 		history       sync.Map
+		leeway        time.Duration
 	}
 
 	ParseOption func(parser *Parser)
@@ -74,11 +75,62 @@ func (tp *Parser) ParseToken(r *http.Request, secret, prevSecret string) (*jwt.T
 	return token, nil
 }
 
+// ParseTokenWithKeys 使用公钥验证 RS256、ES256、EdDSA 等非对称算法签名的令牌
+func (tp *Parser) ParseTokenWithKeys(r *http.Request, keys KeyProvider) (*jwt.Token, error) {
+	parser := tp.newParser()
+	parser.ValidMethods = asymmetricMethods
+	token, err := request.ParseFromRequest(r, request.AuthorizationHeaderExtractor, keys.Key,
+		request.WithParser(parser))
+	if err != nil {
+		return nil, err
+	}
+
+	return token, tp.validateClaims(token)
+}
+
 func (tp *Parser) doParseToken(r *http.Request, secret string) (*jwt.Token, error) {
-	return request.ParseFromRequest(r, request.AuthorizationHeaderExtractor,
+	token, err := request.ParseFromRequest(r, request.AuthorizationHeaderExtractor,
 		func(token *jwt.Token) (interface{}, error) {
 			return []byte(secret), nil
-		}, request.WithParser(newParser()))
+		}, request.WithParser(tp.newParser()))
+	if err != nil {
+		return nil, err
+	}
+
+	return token, tp.validateClaims(token)
+}
+
+// validateClaims 设置了时钟偏差时，按偏差校验过期时间、生效时间和签发时间
+func (tp *Parser) validateClaims(token *jwt.Token) error {
+	if tp.leeway <= 0 {
+		return nil
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	leeway := int64(tp.leeway / time.Second)
+	if !claims.VerifyExpiresAt(now.Unix()-leeway, false) {
+		return jwt.NewValidationError("令牌已过期", jwt.ValidationErrorExpired)
+	}
+	if !claims.VerifyIssuedAt(now.Unix()+leeway, false) {
+		return jwt.NewValidationError("令牌签发时间无效", jwt.ValidationErrorIssuedAt)
+	}
+	if !claims.VerifyNotBefore(now.Unix()+leeway, false) {
+		return jwt.NewValidationError("令牌尚未生效", jwt.ValidationErrorNotValidYet)
+	}
+
+	return nil
+}
+
+func (tp *Parser) newParser() *jwt.Parser {
+	parser := newParser()
+	// 由 validateClaims 按时钟偏差校验
+	parser.SkipClaimsValidation = tp.leeway > 0
+	return parser
 }
 
 func (tp *Parser) incrementCount(secret string) {
@@ -114,6 +166,13 @@ func WithResetDuration(duration time.Duration) ParseOption {
 	}
 }
 
+// WithLeeway 自定义校验令牌时间时允许的时钟偏差
+func WithLeeway(leeway time.Duration) ParseOption {
+	return func(parser *Parser) {
+		parser.leeway = leeway
+	}
+}
+
 func newParser() *jwt.Parser {
 	return &jwt.Parser{
 		UseJSONNumber: true,
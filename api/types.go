@@ -1,6 +1,13 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"git.zc0901.com/go/god/api/handler"
+	"git.zc0901.com/go/god/api/token"
+	"git.zc0901.com/go/god/lib/limit"
+)
 
 type (
 	// 路由
@@ -18,9 +25,14 @@ type (
 
 	// jsonWebToken 设置
 	jwtSetting struct {
-		enabled    bool   // 是否启用jwt验证
-		secret     string // jwt秘钥
-		prevSecret string // 上一个jwt秘钥
+		enabled    bool                 // 是否启用jwt验证
+		secret     string               // jwt秘钥
+		prevSecret string               // 上一个jwt秘钥
+		keys       token.KeyProvider    // 非对称算法验签公钥
+		audiences  []string             // 允许的受众
+		issuers    []string             // 允许的签发者
+		clockSkew  time.Duration        // 允许的时钟偏差
+		revoked    handler.RevokedCheck // 令牌吊销检查
 	}
 
 	// 签名设置
@@ -29,11 +41,23 @@ type (
 		enabled bool // 是否启用签名校验
 	}
 
-	// 特色路由，支持高优先级、jwt令牌校验、签名校验
+	// 限流设置
+	limitSetting struct {
+		period  *limit.PeriodLimit   // 周期限流器
+		keyFunc handler.LimitKeyFunc // 周期限流键提取函数
+		token   *limit.TokenLimiter  // 令牌桶限流器
+	}
+
+	// 特色路由，支持高优先级、jwt令牌校验、签名校验、限流
 	featuredRoutes struct {
-		priority  bool             // 带有高优先级的路由
-		jwt       jwtSetting       // JWT 鉴权
-		signature signatureSetting // 签名校验
-		routes    []Route
+		priority   bool             // 带有高优先级的路由
+		jwt        jwtSetting       // JWT 鉴权
+		signature  signatureSetting // 签名校验
+		limit      limitSetting     // 分布式限流
+		stream     bool             // WebSocket、SSE 等长连接路由
+		timeout    *time.Duration   // 路由超时时间，为空时使用 Conf.Timeout
+		maxBytes   *int64           // 路由最大请求字节数，为空时使用 Conf.MaxBytes
+		noShedding bool             // 不参与自适应降载
+		routes     []Route
 	}
 )
@@ -4,7 +4,8 @@ go 1.15
 
 require (
 	github.com/BurntSushi/toml v0.3.1
-	github.com/alicebob/miniredis/v2 v2.14.1
+	github.com/ClickHouse/clickhouse-go v1.5.4
+	github.com/alicebob/miniredis/v2 v2.14.5
 	github.com/beanstalkd/go-beanstalk v0.1.0
 	github.com/clbanning/mxj v1.8.4
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
@@ -16,10 +17,12 @@ require (
 	github.com/golang/mock v1.4.4
 	github.com/golang/protobuf v1.5.2
 	github.com/google/uuid v1.1.2
+	github.com/gorilla/websocket v1.4.2
 	github.com/grokify/html-strip-tags-go v0.0.0-20200923094847-079d207a09f1
 	github.com/iancoleman/strcase v0.1.2
 	github.com/json-iterator/go v1.1.11
 	github.com/justinas/alice v1.2.0
+	github.com/lib/pq v1.10.9
 	github.com/logrusorgru/aurora v2.0.3+incompatible
 	github.com/onsi/ginkgo v1.14.2 // indirect
 	github.com/onsi/gomega v1.10.3 // indirect
@@ -29,12 +32,14 @@ require (
 	github.com/spaolacci/murmur3 v1.1.0
 	github.com/stretchr/testify v1.7.0
 	github.com/urfave/cli v1.22.5
+	github.com/vmihailenco/msgpack/v5 v5.3.5
 	github.com/xwb1989/sqlparser v0.0.0-20180606152119-120387863bf2
 	github.com/yuin/gopher-lua v0.0.0-20200816102855-ee81675732da // indirect
 	go.etcd.io/etcd/api/v3 v3.5.0
 	go.etcd.io/etcd/client/v3 v3.5.0
 	golang.org/x/net v0.0.0-20210405180319-a5a99cb37ef4
 	golang.org/x/text v0.3.5
+	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba
 	google.golang.org/grpc v1.38.0
 	google.golang.org/protobuf v1.26.0
 	//google.golang.org/protobuf v1.26.0
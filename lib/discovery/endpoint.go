@@ -0,0 +1,58 @@
+package discovery
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type (
+	// EndpointMeta 服务节点元数据
+	EndpointMeta struct {
+		Zone    string            `json:",optional"`               // 节点所在的可用区，客户端优先选择同可用区的节点
+		Version string            `json:",optional"`               // 节点版本，可用于金丝雀发布
+		Weight  int               `json:",optional,range=[0:100]"` // 节点权重，1-100，默认100
+		Tags    map[string]string `json:",optional"`               // 节点标签，可用于按标签路由
+	}
+
+	// Endpoint 服务节点，带元数据时以 JSON 格式发布，否则只发布地址。
+	// 旧版本客户端会把 JSON 值当作地址拨号而无法连接，
+	// 服务端设置元数据前，所有订阅该服务的客户端都必须先升级到支持 ParseEndpoint 的版本。
+	Endpoint struct {
+		Addr string
+		EndpointMeta
+	}
+)
+
+// ParseEndpoint 解析发布的服务节点，兼容只包含地址的值
+func ParseEndpoint(value string) Endpoint {
+	if !strings.HasPrefix(value, "{") {
+		return Endpoint{Addr: value}
+	}
+
+	var endpoint Endpoint
+	if err := json.Unmarshal([]byte(value), &endpoint); err != nil || len(endpoint.Addr) == 0 {
+		return Endpoint{Addr: value}
+	}
+
+	return endpoint
+}
+
+// IsZero 判断元数据是否为空
+func (m EndpointMeta) IsZero() bool {
+	return len(m.Zone) == 0 && len(m.Version) == 0 && m.Weight == 0 && len(m.Tags) == 0
+}
+
+// String 返回发布到 etcd 的值，元数据为空时只返回地址，以兼容旧版本客户端，
+// 元数据不为空时返回的 JSON 值只有新版本客户端能够解析，见 Endpoint。
+func (e Endpoint) String() string {
+	if e.IsZero() {
+		return e.Addr
+	}
+
+	content, err := json.Marshal(e)
+	if err != nil {
+		return e.Addr
+	}
+
+	return string(content)
+}
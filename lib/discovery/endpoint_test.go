@@ -0,0 +1,46 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		value    string
+	}{
+		{
+			name:     "addr only",
+			endpoint: Endpoint{Addr: "10.0.0.1:8080"},
+			value:    "10.0.0.1:8080",
+		},
+		{
+			name: "with meta",
+			endpoint: Endpoint{
+				Addr: "10.0.0.1:8080",
+				EndpointMeta: EndpointMeta{
+					Zone:    "az1",
+					Version: "v2",
+					Weight:  50,
+					Tags:    map[string]string{"env": "canary"},
+				},
+			},
+			value: `{"Addr":"10.0.0.1:8080","Zone":"az1","Version":"v2","Weight":50,"Tags":{"env":"canary"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.value, test.endpoint.String())
+			assert.Equal(t, test.endpoint, ParseEndpoint(test.value))
+		})
+	}
+}
+
+func TestParseEndpoint_Invalid(t *testing.T) {
+	assert.Equal(t, Endpoint{Addr: "{bad"}, ParseEndpoint("{bad"))
+	assert.Equal(t, Endpoint{Addr: `{"Zone":"az1"}`}, ParseEndpoint(`{"Zone":"az1"}`))
+}
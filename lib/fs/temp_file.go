@@ -38,3 +38,13 @@ func TempFilenameWithText(text string) (string, error) {
 
 	return filename, nil
 }
+
+// TempFileInDir 在 dir 目录中创建名称以 pattern 为模式的临时文件，目录不存在时自动创建。
+// 调用者应该关闭文件句柄，并按名称删除文件。
+func TempFileInDir(dir, pattern string) (*os.File, error) {
+	if err := MkdirIfNotExist(dir); err != nil {
+		return nil, err
+	}
+
+	return ioutil.TempFile(dir, pattern)
+}
@@ -0,0 +1,126 @@
+package limit
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"git.zc0901.com/go/god/lib/store/redis"
+)
+
+const (
+	// 计数首次写入时设置窗口过期；达到配额返回 2，超出返回 0
+	periodScript = `local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local current = redis.call("INCRBY", KEYS[1], 1)
+if current == 1 then
+    redis.call("EXPIRE", KEYS[1], window)
+end
+if current < limit then
+    return 1
+elseif current == limit then
+    return 2
+else
+    return 0
+end`
+	zoneDiff = 3600 * 8 // GMT+8，用于按自然周期对齐
+)
+
+const (
+	// Unknown 未知状态，通常是因为出错。
+	Unknown = iota
+	// Allowed 允许通过，仍在配额内。
+	Allowed
+	// HitQuota 允许通过，但恰好用完配额。
+	HitQuota
+	// OverQuota 超出配额，应拒绝。
+	OverQuota
+
+	internalOverQuota = 0
+	internalAllowed   = 1
+	internalHitQuota  = 2
+)
+
+// ErrUnknownCode 表示限流脚本返回了未知的状态码。
+var ErrUnknownCode = errors.New("未知的限流状态码")
+
+type (
+	// PeriodLimit 周期限流器：每个键在每个窗口内最多允许 quota 次请求。
+	PeriodLimit struct {
+		period     int
+		quota      int
+		limitStore *redis.Redis
+		keyPrefix  string
+		align      bool
+	}
+
+	// PeriodOption 自定义 PeriodLimit 的方法。
+	PeriodOption func(l *PeriodLimit)
+)
+
+// NewPeriodLimit 返回一个周期限流器，period 为窗口秒数，quota 为窗口内的配额，二者都必须大于 0。
+func NewPeriodLimit(period, quota int, limitStore *redis.Redis, keyPrefix string,
+	opts ...PeriodOption) *PeriodLimit {
+	if period <= 0 {
+		panic("period 必须大于0")
+	}
+	if quota <= 0 {
+		panic("quota 必须大于0")
+	}
+
+	limiter := &PeriodLimit{
+		period:     period,
+		quota:      quota,
+		limitStore: limitStore,
+		keyPrefix:  keyPrefix,
+	}
+
+	for _, opt := range opts {
+		opt(limiter)
+	}
+
+	return limiter
+}
+
+// Align 让窗口按自然周期对齐，如按天限流时在零点重置。
+func Align() PeriodOption {
+	return func(l *PeriodLimit) {
+		l.align = true
+	}
+}
+
+// Take 占用一次 key 的配额，返回 Allowed、HitQuota 或 OverQuota。
+func (h *PeriodLimit) Take(key string) (int, error) {
+	resp, err := h.limitStore.Eval(periodScript, []string{h.keyPrefix + key}, []string{
+		strconv.Itoa(h.quota),
+		strconv.Itoa(h.calcExpireSeconds()),
+	})
+	if err != nil {
+		return Unknown, err
+	}
+
+	code, ok := resp.(int64)
+	if !ok {
+		return Unknown, ErrUnknownCode
+	}
+
+	switch code {
+	case internalOverQuota:
+		return OverQuota, nil
+	case internalAllowed:
+		return Allowed, nil
+	case internalHitQuota:
+		return HitQuota, nil
+	default:
+		return Unknown, ErrUnknownCode
+	}
+}
+
+func (h *PeriodLimit) calcExpireSeconds() int {
+	if h.align {
+		unix := time.Now().Unix() + zoneDiff
+		return h.period - int(unix%int64(h.period))
+	}
+
+	return h.period
+}
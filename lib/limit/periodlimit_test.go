@@ -0,0 +1,80 @@
+package limit
+
+import (
+	"testing"
+
+	"git.zc0901.com/go/god/lib/store/redis"
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPeriodLimit_Invalid(t *testing.T) {
+	store := redis.NewRedis("localhost:6379", redis.StandaloneMode)
+	assert.Panics(t, func() {
+		NewPeriodLimit(0, 1, store, "periodlimit", Align())
+	})
+	assert.Panics(t, func() {
+		NewPeriodLimit(-1, 1, store, "periodlimit")
+	})
+	assert.Panics(t, func() {
+		NewPeriodLimit(1, 0, store, "periodlimit")
+	})
+}
+
+func TestPeriodLimit_Take(t *testing.T) {
+	testPeriodLimit(t)
+}
+
+func TestPeriodLimit_TakeWithAlign(t *testing.T) {
+	testPeriodLimit(t, Align())
+}
+
+func TestPeriodLimit_RedisUnavailable(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	const (
+		seconds = 1
+		quota   = 5
+	)
+	l := NewPeriodLimit(seconds, quota, redis.NewRedis(s.Addr(), redis.StandaloneMode), "periodlimit")
+	s.Close()
+	val, err := l.Take("first")
+	assert.NotNil(t, err)
+	assert.Equal(t, Unknown, val)
+}
+
+func testPeriodLimit(t *testing.T, opts ...PeriodOption) {
+	store, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	const (
+		seconds = 1
+		total   = 100
+		quota   = 5
+	)
+	l := NewPeriodLimit(seconds, quota, store, "periodlimit", opts...)
+	var allowed, hitQuota, overQuota int
+	for i := 0; i < total; i++ {
+		val, err := l.Take("first")
+		if err != nil {
+			t.Error(err)
+		}
+		switch val {
+		case Allowed:
+			allowed++
+		case HitQuota:
+			hitQuota++
+		case OverQuota:
+			overQuota++
+		default:
+			t.Error("unknown status")
+		}
+	}
+
+	assert.Equal(t, quota-1, allowed)
+	assert.Equal(t, 1, hitQuota)
+	assert.Equal(t, total-quota, overQuota)
+}
@@ -0,0 +1,153 @@
+package limit
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/store/redis"
+	xrate "golang.org/x/time/rate"
+)
+
+const (
+	// 令牌桶脚本：按流逝时间补充令牌，足够则扣减并返回 1
+	tokenScript = `local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local requested = tonumber(ARGV[4])
+local fill_time = capacity/rate
+local ttl = math.max(1, math.floor(fill_time*2))
+local last_tokens = tonumber(redis.call("GET", KEYS[1]))
+if last_tokens == nil then
+    last_tokens = capacity
+end
+local last_refreshed = tonumber(redis.call("GET", KEYS[2]))
+if last_refreshed == nil then
+    last_refreshed = 0
+end
+local delta = math.max(0, now-last_refreshed)
+local filled_tokens = math.min(capacity, last_tokens+(delta*rate))
+local allowed = filled_tokens >= requested
+local new_tokens = filled_tokens
+if allowed then
+    new_tokens = filled_tokens - requested
+end
+redis.call("SETEX", KEYS[1], ttl, new_tokens)
+redis.call("SETEX", KEYS[2], ttl, now)
+return allowed`
+	// 使用 hash tag 保证集群模式下两个键落在同一个槽
+	tokenFormat     = "{%s}.tokens"
+	timestampFormat = "{%s}.ts"
+	pingInterval    = time.Millisecond * 100
+)
+
+// TokenLimiter 基于 Redis 的令牌桶限流器，Redis 不可用时降级为进程内令牌桶。
+type TokenLimiter struct {
+	rate           int
+	burst          int
+	store          *redis.Redis
+	tokenKey       string
+	timestampKey   string
+	rescueLock     sync.Mutex
+	redisAlive     uint32
+	rescueLimiter  *xrate.Limiter
+	monitorStarted bool
+}
+
+// NewTokenLimiter 返回一个令牌桶限流器，rate 为每秒生成的令牌数，burst 为桶容量，二者都必须大于 0。
+func NewTokenLimiter(rate, burst int, store *redis.Redis, key string) *TokenLimiter {
+	if rate <= 0 {
+		panic("rate 必须大于0")
+	}
+	if burst <= 0 {
+		panic("burst 必须大于0")
+	}
+
+	return &TokenLimiter{
+		rate:          rate,
+		burst:         burst,
+		store:         store,
+		tokenKey:      fmt.Sprintf(tokenFormat, key),
+		timestampKey:  fmt.Sprintf(timestampFormat, key),
+		redisAlive:    1,
+		rescueLimiter: xrate.NewLimiter(xrate.Every(time.Second/time.Duration(rate)), burst),
+	}
+}
+
+// Allow 是 AllowN(time.Now(), 1) 的简写。
+func (lim *TokenLimiter) Allow() bool {
+	return lim.AllowN(time.Now(), 1)
+}
+
+// AllowN 判断在 now 时刻是否允许 n 个请求通过。
+func (lim *TokenLimiter) AllowN(now time.Time, n int) bool {
+	return lim.reserveN(now, n)
+}
+
+func (lim *TokenLimiter) reserveN(now time.Time, n int) bool {
+	if atomic.LoadUint32(&lim.redisAlive) == 0 {
+		return lim.rescueLimiter.AllowN(now, n)
+	}
+
+	resp, err := lim.store.Eval(tokenScript, []string{
+		lim.tokenKey,
+		lim.timestampKey,
+	}, []string{
+		strconv.Itoa(lim.rate),
+		strconv.Itoa(lim.burst),
+		strconv.FormatInt(now.Unix(), 10),
+		strconv.Itoa(n),
+	})
+	// Lua 中的 false 会被转换为 redis nil
+	if err == redis.Nil {
+		return false
+	} else if err != nil {
+		logx.Errorf("令牌桶限流器出错，降级为进程内限流器：%s", err)
+		lim.startMonitor()
+		return lim.rescueLimiter.AllowN(now, n)
+	}
+
+	code, ok := resp.(int64)
+	if !ok {
+		logx.Errorf("令牌桶限流器返回值错误：%v", resp)
+		lim.startMonitor()
+		return lim.rescueLimiter.AllowN(now, n)
+	}
+
+	// Lua 中的 true 会被转换为 1
+	return code == 1
+}
+
+func (lim *TokenLimiter) startMonitor() {
+	lim.rescueLock.Lock()
+	defer lim.rescueLock.Unlock()
+
+	if lim.monitorStarted {
+		return
+	}
+
+	lim.monitorStarted = true
+	atomic.StoreUint32(&lim.redisAlive, 0)
+
+	go lim.waitForRedis()
+}
+
+func (lim *TokenLimiter) waitForRedis() {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		lim.rescueLock.Lock()
+		lim.monitorStarted = false
+		lim.rescueLock.Unlock()
+	}()
+
+	for range ticker.C {
+		if lim.store.Ping() {
+			atomic.StoreUint32(&lim.redisAlive, 1)
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package limit
+
+import (
+	"testing"
+	"time"
+
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/store/redis"
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	logx.Disable()
+}
+
+func TestNewTokenLimiter_Invalid(t *testing.T) {
+	store := redis.NewRedis("localhost:6379", redis.StandaloneMode)
+	assert.Panics(t, func() {
+		NewTokenLimiter(0, 1, store, "tokenlimit")
+	})
+	assert.Panics(t, func() {
+		NewTokenLimiter(-1, 1, store, "tokenlimit")
+	})
+	assert.Panics(t, func() {
+		NewTokenLimiter(1, 0, store, "tokenlimit")
+	})
+}
+
+func TestTokenLimit_Rescue(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	const (
+		total = 100
+		rate  = 5
+		burst = 10
+	)
+	l := NewTokenLimiter(rate, burst, redis.NewRedis(s.Addr(), redis.StandaloneMode), "tokenlimit")
+	s.Close()
+
+	var allowed int
+	for i := 0; i < total; i++ {
+		time.Sleep(time.Second / time.Duration(total))
+		if l.Allow() {
+			allowed++
+		}
+	}
+
+	assert.True(t, allowed >= burst+rate)
+}
+
+func TestTokenLimit_Take(t *testing.T) {
+	store, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	const (
+		total = 100
+		rate  = 5
+		burst = 10
+	)
+	l := NewTokenLimiter(rate, burst, store, "tokenlimit")
+	var allowed int
+	for i := 0; i < total; i++ {
+		time.Sleep(time.Second / time.Duration(total))
+		if l.Allow() {
+			allowed++
+		}
+	}
+
+	assert.True(t, allowed >= burst+rate)
+}
+
+func TestTokenLimit_TakeBurst(t *testing.T) {
+	store, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	const (
+		total = 100
+		rate  = 5
+		burst = 10
+	)
+	l := NewTokenLimiter(rate, burst, store, "tokenlimit")
+	var allowed int
+	for i := 0; i < total; i++ {
+		if l.Allow() {
+			allowed++
+		}
+	}
+
+	assert.True(t, allowed >= burst)
+}
@@ -124,6 +124,17 @@ func Disable() {
 	enabled.Set(false)
 }
 
+// Overloaded 判断泄流器是否处于过载状态，即冷却时长内丢弃过请求，非自适应泄流器始终返回 false。
+func Overloaded(shedder Shedder) bool {
+	as, ok := shedder.(*adaptiveShedder)
+	if !ok || !as.droppedRecently.True() {
+		return false
+	}
+
+	dropTime := as.dropTime.Load()
+	return dropTime > 0 && timex.Since(dropTime) < coolOffDuration
+}
+
 // Allow 判断是否接受请求并进行相关处理。
 func (as *adaptiveShedder) Allow() (Promise, error) {
 	if as.shouldDrop() {
@@ -6,6 +6,7 @@ import (
 	"git.zc0901.com/go/god/lib/mathx"
 	"git.zc0901.com/go/god/lib/stat"
 	"git.zc0901.com/go/god/lib/syncx"
+	"git.zc0901.com/go/god/lib/timex"
 	"github.com/stretchr/testify/assert"
 	"math/rand"
 	"sync"
@@ -186,6 +187,20 @@ func TestAdaptiveShedderStillHot(t *testing.T) {
 	assert.False(t, shedder.stillHot())
 }
 
+func TestOverloaded(t *testing.T) {
+	shedder := &adaptiveShedder{
+		dropTime:        syncx.NewAtomicDuration(),
+		droppedRecently: syncx.NewAtomicBool(),
+	}
+	assert.False(t, Overloaded(shedder))
+	shedder.dropTime.Set(timex.Now())
+	shedder.droppedRecently.Set(true)
+	assert.True(t, Overloaded(shedder))
+	shedder.dropTime.Set(timex.Now() - coolOffDuration*2)
+	assert.False(t, Overloaded(shedder))
+	assert.False(t, Overloaded(newNopShedder()))
+}
+
 func BenchmarkAdaptiveShedder_Allow(b *testing.B) {
 	logx.Disable()
 
@@ -0,0 +1,22 @@
+package rq
+
+import (
+	"time"
+
+	"git.zc0901.com/go/god/lib/store/redis"
+)
+
+// Conf 基于 Redis Streams 的可靠队列配置
+type Conf struct {
+	Redis             redis.Conf    // redis 配置
+	Name              string        // 队列名称，用于生成流、延迟集合等键
+	Group             string        `json:",default=rq"`     // 消费组名称
+	Consumer          string        `json:",optional"`       // 消费者名称，默认为主机名加进程号
+	Workers           int           `json:",default=1"`      // 消费协程数
+	BatchSize         int64         `json:",default=10"`     // 每次拉取的最大消息数
+	VisibilityTimeout time.Duration `json:",default=30s"`    // 消息被拉取后未确认多久可被其他消费者重新认领
+	MaxRetries        int           `json:",default=3"`      // 消费失败的最大重试次数，超出后进入死信流
+	RetryBackoff      time.Duration `json:",default=1s"`     // 首次重试的等待时长，之后按指数退避
+	MaxRetryBackoff   time.Duration `json:",default=5m"`     // 重试等待时长上限
+	DeadLetterMaxLen  int64         `json:",default=100000"` // 死信流的近似最大长度
+}
@@ -0,0 +1,379 @@
+package rq
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/service"
+	"git.zc0901.com/go/god/lib/store/redis"
+	"git.zc0901.com/go/god/lib/syncx"
+	"git.zc0901.com/go/god/lib/sysx"
+	"git.zc0901.com/go/god/lib/threading"
+)
+
+const (
+	// 将到期的延迟任务移入流中
+	scheduleScript = `local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, id in ipairs(ids) do
+    local job = redis.call("HGET", KEYS[2], id)
+    if job then
+        redis.call("XADD", KEYS[3], "*", ARGV[3], job)
+        redis.call("HDEL", KEYS[2], id)
+    end
+    redis.call("ZREM", KEYS[1], id)
+end
+return #ids`
+)
+
+// ErrVisibilityTimeout 表示消息被拉取后超过可见性超时仍未确认，视为一次失败
+var ErrVisibilityTimeout = errors.New("任务消费超时未确认")
+
+type (
+	// Consume 消费函数，返回错误或 panic 时按退避策略重试，超出重试次数后进入死信流
+	Consume func(body []byte) error
+
+	// Consumer 任务消费者。
+	// 与 dq.Consumer 不同，rq 的消费函数需返回错误以触发重试，两者的 Consume 不可互换。
+	Consumer interface {
+		Consume(consume Consume)
+	}
+
+	consumer struct {
+		conf Conf
+	}
+
+	// 消费者服务，可加入 service.Group
+	consumerService struct {
+		conf    Conf
+		store   *redis.Redis
+		keys    keys
+		name    string
+		consume Consume
+		done    *syncx.DoneChan
+	}
+)
+
+// NewConsumer 新建基于 Redis Streams 的任务消费者
+func NewConsumer(c Conf) Consumer {
+	return consumer{conf: c}
+}
+
+// Consume 阻塞消费任务，直到进程退出
+func (c consumer) Consume(consume Consume) {
+	group := service.NewServiceGroup()
+	group.Add(NewService(c.conf, consume))
+	group.Start()
+}
+
+// NewService 新建消费者服务，便于和其他服务一起加入 service.Group
+func NewService(c Conf, consume Consume) service.Service {
+	name := c.Consumer
+	if len(name) == 0 {
+		name = fmt.Sprintf("%s-%d", sysx.Hostname(), os.Getpid())
+	}
+	if c.Workers <= 0 {
+		c.Workers = 1
+	}
+
+	return &consumerService{
+		conf:    c,
+		store:   c.Redis.NewRedis(),
+		keys:    newKeys(c.Name),
+		name:    name,
+		consume: consume,
+		done:    syncx.NewDoneChan(),
+	}
+}
+
+// Start 开启消费者服务
+func (s *consumerService) Start() {
+	for {
+		err := s.store.XGroupCreateMkStream(s.keys.stream, s.conf.Group, "0")
+		if err == nil {
+			break
+		}
+
+		logx.Errorf("创建消费组 %s 失败：%s", s.conf.Group, err)
+		if !s.sleep(time.Second) {
+			return
+		}
+	}
+
+	group := threading.NewRoutineGroup()
+	group.RunSafe(s.schedule)
+	group.RunSafe(s.reclaim)
+	for i := 0; i < s.conf.Workers; i++ {
+		name := s.name + "-" + strconv.Itoa(i)
+		group.RunSafe(func() {
+			s.work(name)
+		})
+	}
+	group.Wait()
+}
+
+// Stop 停止消费者服务
+func (s *consumerService) Stop() {
+	s.done.Close()
+}
+
+// 拉取并消费新消息
+func (s *consumerService) work(name string) {
+	for !s.closed() {
+		streams, err := s.store.XReadGroup(&redis.XReadGroupArgs{
+			Group:    s.conf.Group,
+			Consumer: name,
+			Streams:  []string{s.keys.stream, ">"},
+			Count:    s.conf.BatchSize,
+			Block:    readBlock,
+		})
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			logx.Error(err)
+			s.sleep(time.Second)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				s.handle(msg, nil)
+			}
+		}
+	}
+}
+
+// 定时将到期的延迟任务移入流中
+func (s *consumerService) schedule() {
+	ticker := time.NewTicker(scheduleTicker)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done.Done():
+			return
+		case <-ticker.C:
+			_, err := s.store.Eval(scheduleScript, []string{s.keys.delayed, s.keys.jobs, s.keys.stream},
+				strconv.FormatInt(toMillis(time.Now()), 10), scheduleLimit, jobField)
+			if err != nil {
+				logx.Error(err)
+			}
+		}
+	}
+}
+
+// 定时认领超过可见性超时仍未确认的消息，通常是因为消费者崩溃或卡死
+func (s *consumerService) reclaim() {
+	if s.conf.VisibilityTimeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(reclaimTicker)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done.Done():
+			return
+		case <-ticker.C:
+			s.reclaimOnce()
+		}
+	}
+}
+
+// 分页扫描整个待确认列表，未超时的消息不会挡住其后已超时的消息
+func (s *consumerService) reclaimOnce() {
+	start := "-"
+	for !s.closed() {
+		pendings, err := s.store.XPendingExt(&redis.XPendingExtArgs{
+			Stream: s.keys.stream,
+			Group:  s.conf.Group,
+			Start:  start,
+			End:    "+",
+			Count:  s.conf.BatchSize,
+		})
+		if err != nil {
+			logx.Error(err)
+			return
+		}
+
+		var ids []string
+		for _, pending := range pendings {
+			if pending.Idle >= s.conf.VisibilityTimeout {
+				ids = append(ids, pending.Id)
+			}
+		}
+		s.claim(ids)
+
+		if int64(len(pendings)) < s.conf.BatchSize {
+			return
+		}
+		if start = nextStreamId(pendings[len(pendings)-1].Id); len(start) == 0 {
+			return
+		}
+	}
+}
+
+func (s *consumerService) claim(ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	msgs, err := s.store.XClaim(&redis.XClaimArgs{
+		Stream:   s.keys.stream,
+		Group:    s.conf.Group,
+		Consumer: s.name,
+		MinIdle:  s.conf.VisibilityTimeout,
+		Messages: ids,
+	})
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+
+	for _, msg := range msgs {
+		s.handle(msg, ErrVisibilityTimeout)
+	}
+}
+
+// 处理一条消息，cause 不为空表示该消息上次消费已失败，直接进入重试流程
+func (s *consumerService) handle(msg redis.XMessage, cause error) {
+	j, ok := decodeJob(msg.Values[jobField])
+	if !ok {
+		logx.Errorf("丢弃无法解析的队列任务：%v", msg.Values)
+		s.ack(msg.ID)
+		return
+	}
+
+	if n, err := s.store.SRem(s.keys.revoked, j.Id); err != nil {
+		logx.Error(err)
+	} else if n > 0 {
+		s.ack(msg.ID)
+		return
+	}
+
+	err := cause
+	if err == nil {
+		err = s.invoke(j.Body)
+	}
+	if err == nil {
+		s.ack(msg.ID)
+		return
+	}
+
+	s.retry(msg.ID, j, err)
+}
+
+func (s *consumerService) invoke(body []byte) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("消费任务 panic：%v", p)
+		}
+	}()
+
+	return s.consume(body)
+}
+
+// 消费失败后按指数退避重新加入延迟集合，超出重试次数则写入死信流
+func (s *consumerService) retry(msgId string, j job, cause error) {
+	j.Attempt++
+	val, err := j.encode()
+	if err != nil {
+		logx.Error(err)
+		s.ack(msgId)
+		return
+	}
+
+	if j.Attempt > s.conf.MaxRetries {
+		logx.Errorf("任务 %s 重试 %d 次后仍失败，转入死信流：%s", j.Id, s.conf.MaxRetries, cause)
+		_, err = s.store.XAdd(&redis.XAddArgs{
+			Stream:       s.keys.dead,
+			MaxLenApprox: s.conf.DeadLetterMaxLen,
+			Values: map[string]interface{}{
+				jobField: val,
+				errField: cause.Error(),
+			},
+		})
+	} else {
+		at := time.Now().Add(s.backoff(j.Attempt))
+		_, err = s.store.Eval(delayScript, []string{s.keys.delayed, s.keys.jobs},
+			j.Id, val, strconv.FormatInt(toMillis(at), 10))
+	}
+	if err != nil {
+		// 未能转移的消息保留在待确认列表中，超时后会被重新认领
+		logx.Error(err)
+		return
+	}
+
+	s.ack(msgId)
+}
+
+func (s *consumerService) backoff(attempt int) time.Duration {
+	backoff := s.conf.RetryBackoff
+	for i := 1; i < attempt; i++ {
+		backoff <<= 1
+		if s.conf.MaxRetryBackoff > 0 && backoff >= s.conf.MaxRetryBackoff {
+			return s.conf.MaxRetryBackoff
+		}
+	}
+
+	return backoff
+}
+
+func (s *consumerService) ack(msgId string) {
+	if _, err := s.store.XAck(s.keys.stream, s.conf.Group, msgId); err != nil {
+		logx.Error(err)
+		return
+	}
+
+	if _, err := s.store.XDel(s.keys.stream, msgId); err != nil {
+		logx.Error(err)
+	}
+}
+
+// 返回紧随 id 之后的流消息 id，用于分页时排除上一页的最后一条，兼容不支持 "(" 排他区间的 Redis
+func nextStreamId(id string) string {
+	pos := strings.IndexByte(id, '-')
+	if pos < 0 {
+		return ""
+	}
+
+	ms, err := strconv.ParseUint(id[:pos], 10, 64)
+	if err != nil {
+		return ""
+	}
+	seq, err := strconv.ParseUint(id[pos+1:], 10, 64)
+	if err != nil {
+		return ""
+	}
+
+	if seq == math.MaxUint64 {
+		return strconv.FormatUint(ms+1, 10) + "-0"
+	}
+	return strconv.FormatUint(ms, 10) + "-" + strconv.FormatUint(seq+1, 10)
+}
+
+func (s *consumerService) closed() bool {
+	select {
+	case <-s.done.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// 等待指定时长，服务停止时返回 false
+func (s *consumerService) sleep(d time.Duration) bool {
+	select {
+	case <-s.done.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
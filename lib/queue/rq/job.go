@@ -0,0 +1,57 @@
+package rq
+
+import (
+	"git.zc0901.com/go/god/lib/jsonx"
+)
+
+type (
+	// 队列任务
+	job struct {
+		Id      string `json:"id"`
+		Body    []byte `json:"body"`
+		Attempt int    `json:"attempt"`
+	}
+
+	// 队列相关的 redis 键，使用 hash tag 保证集群模式下落在同一个槽
+	keys struct {
+		stream  string
+		delayed string
+		jobs    string
+		revoked string
+		dead    string
+	}
+)
+
+func newKeys(name string) keys {
+	prefix := "{" + name + "}"
+	return keys{
+		stream:  prefix + streamSuffix,
+		delayed: prefix + delayedSuffix,
+		jobs:    prefix + jobsSuffix,
+		revoked: prefix + revokedSuffix,
+		dead:    prefix + deadSuffix,
+	}
+}
+
+func (j job) encode() (string, error) {
+	val, err := jsonx.Marshal(j)
+	if err != nil {
+		return "", err
+	}
+
+	return string(val), nil
+}
+
+func decodeJob(val interface{}) (job, bool) {
+	var j job
+	s, ok := val.(string)
+	if !ok {
+		return j, false
+	}
+
+	if err := jsonx.UnmarshalFromString(s, &j); err != nil {
+		return j, false
+	}
+
+	return j, true
+}
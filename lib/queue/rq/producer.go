@@ -0,0 +1,110 @@
+package rq
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"git.zc0901.com/go/god/lib/store/redis"
+	"git.zc0901.com/go/god/lib/stringx"
+)
+
+const (
+	idSep = "," // 编号分隔符
+
+	// 写入延迟任务：内容存哈希，执行时间存有序集合
+	delayScript = `redis.call("HSET", KEYS[2], ARGV[1], ARGV[2])
+return redis.call("ZADD", KEYS[1], ARGV[3], ARGV[1])`
+	// 撤回任务：尚未到期的直接删除，已进入流的记录到撤回集合，由消费者跳过
+	revokeScript = `local revoked = 0
+for i = 2, #ARGV do
+    if redis.call("ZREM", KEYS[1], ARGV[i]) == 1 then
+        redis.call("HDEL", KEYS[2], ARGV[i])
+        revoked = revoked + 1
+    else
+        redis.call("SADD", KEYS[3], ARGV[i])
+    end
+end
+redis.call("EXPIRE", KEYS[3], ARGV[1])
+return revoked`
+)
+
+type (
+	// Producer 任务生产者，与 dq.Producer 保持一致
+	Producer interface {
+		At(body []byte, at time.Time) (string, error)           // 定时执行
+		Delay(body []byte, delay time.Duration) (string, error) // 延迟执行
+		Revoke(ids string) error                                // 撤回任务，多个编号以逗号分隔
+		Close() error
+	}
+
+	producer struct {
+		store *redis.Redis
+		keys  keys
+	}
+)
+
+// NewProducer 新建基于 Redis Streams 的任务生产者
+func NewProducer(c Conf) Producer {
+	return &producer{
+		store: c.Redis.NewRedis(),
+		keys:  newKeys(c.Name),
+	}
+}
+
+// At 定时执行，at 不晚于当前时间则立即投递
+func (p *producer) At(body []byte, at time.Time) (string, error) {
+	j := job{
+		Id:   stringx.RandId(),
+		Body: body,
+	}
+	val, err := j.encode()
+	if err != nil {
+		return "", err
+	}
+
+	if !at.After(time.Now()) {
+		_, err = p.store.XAdd(&redis.XAddArgs{
+			Stream: p.keys.stream,
+			Values: map[string]interface{}{jobField: val},
+		})
+	} else {
+		_, err = p.store.Eval(delayScript, []string{p.keys.delayed, p.keys.jobs},
+			j.Id, val, strconv.FormatInt(toMillis(at), 10))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return j.Id, nil
+}
+
+// Delay 延迟执行
+func (p *producer) Delay(body []byte, delay time.Duration) (string, error) {
+	return p.At(body, time.Now().Add(delay))
+}
+
+// Revoke 撤回一批任务
+func (p *producer) Revoke(ids string) error {
+	args := []interface{}{revokedExpire}
+	for _, id := range strings.Split(ids, idSep) {
+		if len(id) > 0 {
+			args = append(args, id)
+		}
+	}
+	if len(args) == 1 {
+		return nil
+	}
+
+	_, err := p.store.Eval(revokeScript, []string{p.keys.delayed, p.keys.jobs, p.keys.revoked}, args...)
+	return err
+}
+
+// Close 关闭生产者，redis 连接由连接池统一管理，无需释放
+func (p *producer) Close() error {
+	return nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
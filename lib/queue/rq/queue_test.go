@@ -0,0 +1,113 @@
+package rq
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/store/redis"
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	logx.Disable()
+}
+
+func TestQueue(t *testing.T) {
+	store, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	c := newTestConf(store)
+	producer := NewProducer(c)
+	defer producer.Close()
+
+	var lock sync.Mutex
+	var received []string
+	var failed bool
+	svc := NewService(c, func(body []byte) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		switch string(body) {
+		case "retry":
+			if !failed {
+				failed = true
+				return errors.New("first attempt fails")
+			}
+		case "dead":
+			panic("always fails")
+		}
+
+		received = append(received, string(body))
+		return nil
+	})
+	go svc.Start()
+	defer svc.Stop()
+
+	_, err = producer.At([]byte("now"), time.Now())
+	assert.Nil(t, err)
+	_, err = producer.Delay([]byte("later"), 200*time.Millisecond)
+	assert.Nil(t, err)
+	_, err = producer.Delay([]byte("retry"), 0)
+	assert.Nil(t, err)
+	_, err = producer.Delay([]byte("dead"), 0)
+	assert.Nil(t, err)
+	id, err := producer.Delay([]byte("revoked"), 300*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Nil(t, producer.Revoke(id))
+
+	time.Sleep(time.Second * 2)
+
+	lock.Lock()
+	assert.ElementsMatch(t, []string{"now", "later", "retry"}, received)
+	lock.Unlock()
+
+	keys := newKeys(c.Name)
+	msgs, err := store.XRange(keys.dead, "-", "+")
+	assert.Nil(t, err)
+	assert.Len(t, msgs, 1)
+	j, ok := decodeJob(msgs[0].Values[jobField])
+	assert.True(t, ok)
+	assert.Equal(t, "dead", string(j.Body))
+	assert.Equal(t, c.MaxRetries+1, j.Attempt)
+}
+
+func TestConsumerService_Backoff(t *testing.T) {
+	s := consumerService{conf: Conf{
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 5 * time.Second,
+	}}
+	assert.Equal(t, time.Second, s.backoff(1))
+	assert.Equal(t, 2*time.Second, s.backoff(2))
+	assert.Equal(t, 4*time.Second, s.backoff(3))
+	assert.Equal(t, 5*time.Second, s.backoff(4))
+}
+
+func TestNextStreamId(t *testing.T) {
+	assert.Equal(t, "1600000000000-1", nextStreamId("1600000000000-0"))
+	assert.Equal(t, "1600000000001-0", nextStreamId("1600000000000-18446744073709551615"))
+	assert.Equal(t, "", nextStreamId("1600000000000"))
+	assert.Equal(t, "", nextStreamId("a-0"))
+}
+
+func newTestConf(store *redis.Redis) Conf {
+	return Conf{
+		Redis: redis.Conf{
+			Host: store.Addr,
+			Mode: redis.StandaloneMode,
+		},
+		Name:              "test",
+		Group:             "rq",
+		Workers:           2,
+		BatchSize:         10,
+		VisibilityTimeout: time.Minute,
+		MaxRetries:        1,
+		RetryBackoff:      10 * time.Millisecond,
+		MaxRetryBackoff:   time.Second,
+		DeadLetterMaxLen:  100,
+	}
+}
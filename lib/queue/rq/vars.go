@@ -0,0 +1,18 @@
+package rq
+
+import "time"
+
+const (
+	streamSuffix   = ":stream"  // 待消费消息流
+	delayedSuffix  = ":delayed" // 延迟任务有序集合，分值为执行时间
+	jobsSuffix     = ":jobs"    // 延迟任务内容哈希
+	revokedSuffix  = ":revoked" // 已撤回任务集合
+	deadSuffix     = ":dead"    // 死信流
+	jobField       = "job"      // 流消息中存放任务的字段
+	errField       = "error"    // 死信消息中存放错误的字段
+	revokedExpire  = 24 * 3600  // 撤回记录保留秒数
+	readBlock      = time.Second
+	scheduleLimit  = 100
+	scheduleTicker = 100 * time.Millisecond
+	reclaimTicker  = time.Second
+)
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -23,6 +24,10 @@ type (
 		GetBits(key string, offset []int64) (map[int64]bool, error)
 		Take(dest interface{}, key string, queryFn func(interface{}) error) error
 		TakeEx(dest interface{}, key string, queryFn func(interface{}, time.Duration) error) error
+		TakeCtx(ctx context.Context, dest interface{}, key string,
+			queryFn func(ctx context.Context, v interface{}) error) error
+		TakeExCtx(ctx context.Context, dest interface{}, key string,
+			queryFn func(ctx context.Context, v interface{}, expires time.Duration) error) error
 	}
 
 	cluster struct {
@@ -41,16 +46,18 @@ func NewCacheCluster(clusterConf ClusterConf, barrier syncx.SingleFlight, stat *
 	}
 
 	// 添加一批 redis 缓存节点
+	o := newOptions(opts...)
 	dispatcher := hash.NewConsistentHash()
 	for _, conf := range clusterConf {
-		node := NewCacheNode(conf.NewRedis(), barrier, stat, errNotFound, opts...)
+		node := newCacheNode(conf.NewRedis(), barrier, stat, errNotFound, o)
 		dispatcher.AddWithWeight(node, conf.Weight)
 	}
 
-	return cluster{
+	// 本地一级缓存加在整个集群之前，失效广播经由第一个节点
+	return withLocalCache(cluster{
 		dispatcher:  dispatcher,
 		errNotFound: errNotFound,
-	}
+	}, clusterConf[0].NewRedis(), stat, o)
 }
 
 func (c cluster) Del(keys ...string) error {
@@ -201,3 +208,23 @@ func (c cluster) TakeEx(dest interface{}, key string, queryFn func(newVal interf
 
 	return node.(Cache).TakeEx(dest, key, queryFn)
 }
+
+func (c cluster) TakeCtx(ctx context.Context, dest interface{}, key string,
+	queryFn func(ctx context.Context, v interface{}) error) error {
+	node, ok := c.dispatcher.Get(key)
+	if !ok {
+		return c.errNotFound
+	}
+
+	return node.(Cache).TakeCtx(ctx, dest, key, queryFn)
+}
+
+func (c cluster) TakeExCtx(ctx context.Context, dest interface{}, key string,
+	queryFn func(ctx context.Context, v interface{}, expires time.Duration) error) error {
+	node, ok := c.dispatcher.Get(key)
+	if !ok {
+		return c.errNotFound
+	}
+
+	return node.(Cache).TakeExCtx(ctx, dest, key, queryFn)
+}
@@ -0,0 +1,351 @@
+package cache
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"git.zc0901.com/go/god/lib/collection"
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/store/redis"
+	"git.zc0901.com/go/god/lib/stringx"
+	"git.zc0901.com/go/god/lib/syncx"
+	"git.zc0901.com/go/god/lib/threading"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const subscribeRetryInterval = time.Second // 订阅失效广播失败后的重试间隔
+
+var (
+	// 本进程标识，忽略自己发出的失效广播
+	localId = stringx.RandId()
+
+	invalidatorsLock sync.Mutex
+	invalidators     = make(map[string]*invalidator)
+)
+
+type (
+	// localCache 在 redis 缓存之前增加一层进程内的一级缓存。
+	// 一级缓存保存 JSON 编排后的值，Del、Set 和 SetEx 时先失效本地缓存，
+	// 再通过 redis 发布订阅通知其他实例失效各自的本地缓存。
+	localCache struct {
+		Cache
+		local       *collection.Cache
+		excludes    []string
+		stat        *Stat
+		invalidator *invalidator
+	}
+
+	// invalidator 订阅失效广播，同一 redis 和频道上的本地缓存共用一个订阅，
+	// 最后一个本地缓存被回收后关闭订阅。
+	invalidator struct {
+		key     string
+		redis   *redis.Redis
+		channel string
+		done    *syncx.DoneChan
+		lock    sync.Mutex
+		caches  []*collection.Cache
+		loads   map[string]*loadGeneration
+	}
+
+	// loadGeneration 记录正在从 redis 加载的键被失效的次数，
+	// 加载期间键被失效时不写入本地缓存，以免旧值覆盖失效。
+	loadGeneration struct {
+		refs       int
+		generation uint64
+	}
+
+	invalidateMessage struct {
+		Source string   `json:"source"`
+		Keys   []string `json:"keys"`
+	}
+)
+
+// withLocalCache 按需为缓存 c 增加本地一级缓存，r 用于广播和订阅缓存失效消息。
+func withLocalCache(c Cache, r *redis.Redis, stat *Stat, o Options) Cache {
+	if o.LocalExpires <= 0 {
+		return c
+	}
+
+	local, err := collection.NewCache(o.LocalExpires, collection.WithLimit(o.LocalLimit),
+		collection.WithName("本地-"+stat.name))
+	if err != nil {
+		logx.Errorf("创建本地缓存失败，仅使用 redis 缓存，错误：%v", err)
+		return c
+	}
+
+	lc := &localCache{
+		Cache:       c,
+		local:       local,
+		excludes:    o.LocalExcludes,
+		stat:        stat,
+		invalidator: addInvalidator(r, o.LocalChannel, local),
+	}
+	// 缓存没有关闭方法，被回收时从订阅中移除，以便释放订阅
+	runtime.SetFinalizer(lc, func(lc *localCache) {
+		lc.invalidator.remove(lc.local)
+	})
+
+	return lc
+}
+
+func (lc *localCache) Del(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := lc.Cache.Del(keys...)
+	lc.invalidate(keys...)
+	return err
+}
+
+func (lc *localCache) Get(key string, dest interface{}) error {
+	return lc.load(key, dest, func() error {
+		return lc.Cache.Get(key, dest)
+	})
+}
+
+func (lc *localCache) Set(key string, val interface{}) error {
+	err := lc.Cache.Set(key, val)
+	lc.invalidate(key)
+	return err
+}
+
+func (lc *localCache) SetEx(key string, val interface{}, expires time.Duration) error {
+	err := lc.Cache.SetEx(key, val, expires)
+	lc.invalidate(key)
+	return err
+}
+
+func (lc *localCache) Take(dest interface{}, key string, queryFn func(interface{}) error) error {
+	return lc.load(key, dest, func() error {
+		return lc.Cache.Take(dest, key, queryFn)
+	})
+}
+
+func (lc *localCache) TakeEx(dest interface{}, key string, queryFn func(interface{}, time.Duration) error) error {
+	return lc.load(key, dest, func() error {
+		return lc.Cache.TakeEx(dest, key, queryFn)
+	})
+}
+
+func (lc *localCache) TakeCtx(ctx context.Context, dest interface{}, key string,
+	queryFn func(ctx context.Context, v interface{}) error) error {
+	return lc.load(key, dest, func() error {
+		return lc.Cache.TakeCtx(ctx, dest, key, queryFn)
+	})
+}
+
+func (lc *localCache) TakeExCtx(ctx context.Context, dest interface{}, key string,
+	queryFn func(ctx context.Context, v interface{}, expires time.Duration) error) error {
+	return lc.load(key, dest, func() error {
+		return lc.Cache.TakeExCtx(ctx, dest, key, queryFn)
+	})
+}
+
+// load 先查本地缓存，未命中时通过 fn 从 redis 加载到 dest，加载期间键未被失效才写入本地缓存
+func (lc *localCache) load(key string, dest interface{}, fn func() error) error {
+	if !lc.enabled(key) {
+		return fn()
+	}
+
+	if lc.getLocal(key, dest) {
+		return nil
+	}
+
+	generation := lc.invalidator.beginLoad(key)
+	if err := fn(); err != nil {
+		lc.invalidator.endLoad(key, generation, nil, nil)
+		return err
+	}
+
+	data, err := jsoniter.Marshal(dest)
+	if err != nil {
+		logx.Errorf("JSON 编排本地缓存失败，键：%s，错误：%v", key, err)
+		lc.invalidator.endLoad(key, generation, nil, nil)
+		return nil
+	}
+
+	lc.invalidator.endLoad(key, generation, lc.local, data)
+	return nil
+}
+
+func (lc *localCache) enabled(key string) bool {
+	for _, prefix := range lc.excludes {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (lc *localCache) getLocal(key string, dest interface{}) bool {
+	val, ok := lc.local.Get(key)
+	if !ok {
+		lc.stat.IncrLocalMiss()
+		return false
+	}
+
+	if err := jsoniter.Unmarshal(val.([]byte), dest); err != nil {
+		logx.Errorf("JSON 解编排本地缓存失败，键：%s，错误：%v", key, err)
+		lc.local.Del(key)
+		lc.stat.IncrLocalMiss()
+		return false
+	}
+
+	lc.stat.IncrLocalHit()
+	return true
+}
+
+// invalidate 失效本进程内所有本地缓存中的 keys，并通知其他实例
+func (lc *localCache) invalidate(keys ...string) {
+	lc.invalidator.delLocal(keys)
+	lc.invalidator.publish(keys)
+}
+
+// addInvalidator 将本地缓存加入 r 和 channel 对应的订阅，没有则新建订阅
+func addInvalidator(r *redis.Redis, channel string, cache *collection.Cache) *invalidator {
+	invalidatorsLock.Lock()
+	defer invalidatorsLock.Unlock()
+
+	key := r.Addr + "/" + channel
+	inv, ok := invalidators[key]
+	if !ok {
+		inv = &invalidator{
+			key:     key,
+			redis:   r,
+			channel: channel,
+			done:    syncx.NewDoneChan(),
+			loads:   make(map[string]*loadGeneration),
+		}
+		threading.GoSafe(inv.subscribe)
+		invalidators[key] = inv
+	}
+
+	inv.lock.Lock()
+	inv.caches = append(inv.caches, cache)
+	inv.lock.Unlock()
+
+	return inv
+}
+
+// remove 移除本地缓存，没有本地缓存时关闭订阅
+func (inv *invalidator) remove(cache *collection.Cache) {
+	invalidatorsLock.Lock()
+	defer invalidatorsLock.Unlock()
+
+	inv.lock.Lock()
+	for i, c := range inv.caches {
+		if c == cache {
+			inv.caches = append(inv.caches[:i], inv.caches[i+1:]...)
+			break
+		}
+	}
+	empty := len(inv.caches) == 0
+	inv.lock.Unlock()
+
+	// 已关闭的订阅可能被同名的新订阅取代
+	if empty && invalidators[inv.key] == inv {
+		delete(invalidators, inv.key)
+		inv.done.Close()
+	}
+}
+
+// beginLoad 标记开始从 redis 加载 key，返回当前的失效次数
+func (inv *invalidator) beginLoad(key string) uint64 {
+	inv.lock.Lock()
+	defer inv.lock.Unlock()
+
+	load, ok := inv.loads[key]
+	if !ok {
+		load = new(loadGeneration)
+		inv.loads[key] = load
+	}
+	load.refs++
+
+	return load.generation
+}
+
+// endLoad 结束加载 key，加载期间 key 未被失效时将 data 写入本地缓存 cache
+func (inv *invalidator) endLoad(key string, generation uint64, cache *collection.Cache, data []byte) {
+	inv.lock.Lock()
+	defer inv.lock.Unlock()
+
+	load := inv.loads[key]
+	if cache != nil && load.generation == generation {
+		cache.Set(key, data)
+	}
+	if load.refs--; load.refs == 0 {
+		delete(inv.loads, key)
+	}
+}
+
+func (inv *invalidator) delLocal(keys []string) {
+	inv.lock.Lock()
+	defer inv.lock.Unlock()
+
+	for _, key := range keys {
+		if load, ok := inv.loads[key]; ok {
+			load.generation++
+		}
+		for _, cache := range inv.caches {
+			cache.Del(key)
+		}
+	}
+}
+
+func (inv *invalidator) publish(keys []string) {
+	msg, err := jsoniter.MarshalToString(invalidateMessage{
+		Source: localId,
+		Keys:   keys,
+	})
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+
+	// 广播失败时其他实例的本地缓存最迟在过期后失效
+	if _, err = inv.redis.Publish(inv.channel, msg); err != nil {
+		logx.Errorf("广播本地缓存失效失败，keys: %q, 错误: %v", formatKeys(keys), err)
+	}
+}
+
+func (inv *invalidator) subscribe() {
+	var sub *redis.Subscription
+	for {
+		var err error
+		if sub, err = inv.redis.Subscribe(inv.channel); err == nil {
+			break
+		}
+
+		logx.Errorf("订阅本地缓存失效广播失败，%v 后重试：%v", subscribeRetryInterval, err)
+		select {
+		case <-inv.done.Done():
+			return
+		case <-time.After(subscribeRetryInterval):
+		}
+	}
+
+	// 订阅不再使用时关闭，消息通道随之关闭
+	threading.GoSafe(func() {
+		<-inv.done.Done()
+		if err := sub.Close(); err != nil {
+			logx.Error(err)
+		}
+	})
+
+	for msg := range sub.Channel() {
+		var m invalidateMessage
+		if err := jsoniter.UnmarshalFromString(msg.Payload, &m); err != nil {
+			logx.Errorf("无效的本地缓存失效广播：%s，错误：%v", msg.Payload, err)
+			continue
+		}
+
+		if m.Source != localId {
+			inv.delLocal(m.Keys)
+		}
+	}
+}
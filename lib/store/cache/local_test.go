@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
+	"git.zc0901.com/go/god/lib/syncx"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestNotFound = errors.New("not found")
+
+func TestLocalCache_Take(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	stat := NewCacheStat("local")
+	c := NewCacheNode(r, syncx.NewSingleFlight(), stat, errTestNotFound,
+		WithLocalCache(time.Minute, 10), WithLocalChannel("local-take"))
+	_, ok := c.(*localCache)
+	assert.True(t, ok)
+
+	var queries int32
+	query := func(v interface{}) error {
+		atomic.AddInt32(&queries, 1)
+		*v.(*string) = "value"
+		return nil
+	}
+
+	for i := 0; i < 3; i++ {
+		var val string
+		assert.Nil(t, c.Take(&val, "any", query))
+		assert.Equal(t, "value", val)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queries))
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&stat.LocalHit))
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&stat.LocalMiss))
+
+	// redis 中的值被其他途径修改，本地缓存仍命中，删除后重新从 redis 读取
+	assert.Nil(t, r.Set("any", `"changed"`))
+	var val string
+	assert.Nil(t, c.Get("any", &val))
+	assert.Equal(t, "value", val)
+	assert.Nil(t, c.Del("any"))
+	assert.Nil(t, r.Set("any", `"changed"`))
+	assert.Nil(t, c.Get("any", &val))
+	assert.Equal(t, "changed", val)
+}
+
+func TestLocalCache_Exclude(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	stat := NewCacheStat("local")
+	c := NewCacheNode(r, syncx.NewSingleFlight(), stat, errTestNotFound,
+		WithLocalCache(time.Minute, 10), WithoutLocalCache("order:"), WithLocalChannel("local-exclude"))
+
+	assert.Nil(t, c.Set("order:1", "paid"))
+	var val string
+	assert.Nil(t, c.Get("order:1", &val))
+	assert.Nil(t, r.Set("order:1", `"refunded"`))
+	assert.Nil(t, c.Get("order:1", &val))
+	assert.Equal(t, "refunded", val)
+	assert.Equal(t, uint64(0), atomic.LoadUint64(&stat.LocalHit)+atomic.LoadUint64(&stat.LocalMiss))
+}
+
+func TestLocalCache_Broadcast(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	c := NewCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("local"), errTestNotFound,
+		WithLocalCache(time.Minute, 10), WithLocalChannel("local-broadcast"))
+	assert.Nil(t, c.Set("any", "value"))
+	var val string
+	assert.Nil(t, c.Get("any", &val))
+
+	// 等待失效广播订阅生效
+	inv := c.(*localCache).invalidator
+	assert.Eventually(t, func() bool {
+		n, err := r.Publish(inv.channel, "{}")
+		return err == nil && n > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// 模拟其他实例删除缓存后发出的失效广播
+	assert.Nil(t, r.Set("any", `"changed"`))
+	msg, err := jsoniter.MarshalToString(invalidateMessage{
+		Source: "other",
+		Keys:   []string{"any"},
+	})
+	assert.Nil(t, err)
+	_, err = r.Publish(inv.channel, msg)
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		var val string
+		return c.Get("any", &val) == nil && val == "changed"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLocalCache_InvalidateWhileLoading(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	c := NewCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("local"), errTestNotFound,
+		WithLocalCache(time.Minute, 10), WithLocalChannel("local-loading"))
+	lc := c.(*localCache)
+
+	// 从 redis 加载期间键被失效，加载到的值不写入本地缓存
+	var val string
+	assert.Nil(t, c.Take(&val, "any", func(v interface{}) error {
+		*v.(*string) = "value"
+		lc.invalidator.delLocal([]string{"any"})
+		return nil
+	}))
+	assert.Equal(t, "value", val)
+	_, ok := lc.local.Get("any")
+	assert.False(t, ok)
+	assert.Empty(t, lc.invalidator.loads)
+
+	assert.Nil(t, c.Get("any", &val))
+	_, ok = lc.local.Get("any")
+	assert.True(t, ok)
+}
+
+func TestLocalCache_Release(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	first := NewCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("local"), errTestNotFound,
+		WithLocalCache(time.Minute, 10), WithLocalChannel("local-release")).(*localCache)
+	second := NewCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("local"), errTestNotFound,
+		WithLocalCache(time.Minute, 10), WithLocalChannel("local-release")).(*localCache)
+	inv := first.invalidator
+	assert.True(t, inv == second.invalidator)
+	assert.Eventually(t, func() bool {
+		n, err := r.Publish(inv.channel, "{}")
+		return err == nil && n > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// 仍有本地缓存时保留订阅
+	inv.remove(first.local)
+	invalidatorsLock.Lock()
+	assert.True(t, invalidators[inv.key] == inv)
+	invalidatorsLock.Unlock()
+
+	// 最后一个本地缓存移除后关闭订阅
+	inv.remove(second.local)
+	invalidatorsLock.Lock()
+	_, ok := invalidators[inv.key]
+	invalidatorsLock.Unlock()
+	assert.False(t, ok)
+	assert.Eventually(t, func() bool {
+		n, err := r.Publish(inv.channel, "{}")
+		return err == nil && n == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewCacheNode_WithoutLocal(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	c := NewCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("local"), errTestNotFound)
+	_, ok := c.(node)
+	assert.True(t, ok)
+}
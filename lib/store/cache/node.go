@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -12,6 +13,7 @@ import (
 	"git.zc0901.com/go/god/lib/stat"
 	"git.zc0901.com/go/god/lib/store/redis"
 	"git.zc0901.com/go/god/lib/syncx"
+	"git.zc0901.com/go/god/lib/timex"
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -32,10 +34,17 @@ type node struct {
 	rnd             *rand.Rand
 	lock            *sync.Mutex
 	errNotFound     error
+	refreshBeta     float64
+	staleExpires    time.Duration
 }
 
+// NewCacheNode 新建一个 redis 缓存节点，通过 WithLocalCache 可在其前增加本地一级缓存。
 func NewCacheNode(r *redis.Redis, barrier syncx.SingleFlight, stat *Stat, errNotFound error, opts ...Option) Cache {
 	o := newOptions(opts...)
+	return withLocalCache(newCacheNode(r, barrier, stat, errNotFound, o), r, stat, o)
+}
+
+func newCacheNode(r *redis.Redis, barrier syncx.SingleFlight, stat *Stat, errNotFound error, o Options) node {
 	return node{
 		redis:           r,
 		barrier:         barrier,
@@ -46,6 +55,8 @@ func NewCacheNode(r *redis.Redis, barrier syncx.SingleFlight, stat *Stat, errNot
 		rnd:             rand.New(rand.NewSource(time.Now().UnixNano())),
 		lock:            new(sync.Mutex),
 		errNotFound:     errNotFound,
+		refreshBeta:     o.RefreshBeta,
+		staleExpires:    o.StaleExpires,
 	}
 }
 
@@ -113,8 +124,20 @@ func (n node) GetBits(key string, offset []int64) (map[int64]bool, error) {
 
 // Take 拿key对应的dest缓存，拿不到缓存就查库并缓存
 func (n node) Take(dest interface{}, key string, queryFn func(interface{}) error) error {
-	return n.doTake(dest, key, queryFn, func(value interface{}) error {
-		return n.Set(key, value)
+	return n.doTake(dest, key, queryFn, nil, func(value interface{}, delta time.Duration) error {
+		return n.setWithMeta(key, value, n.aroundDuration(n.expires), delta)
+	})
+}
+
+// TakeCtx 同 Take，启用提前刷新或过期后返回旧值时，queryFn 还会在后台刷新缓存，见 IsRefresh
+func (n node) TakeCtx(ctx context.Context, dest interface{}, key string,
+	queryFn func(ctx context.Context, v interface{}) error) error {
+	return n.doTake(dest, key, func(v interface{}) error {
+		return queryFn(ctx, v)
+	}, func(v interface{}) error {
+		return queryFn(refreshContext(ctx), v)
+	}, func(value interface{}, delta time.Duration) error {
+		return n.setWithMeta(key, value, n.aroundDuration(n.expires), delta)
 	})
 }
 
@@ -124,9 +147,22 @@ func (n node) TakeEx(dest interface{}, key string, queryFn func(interface{}, tim
 	return n.doTake(dest, key, func(value interface{}) error {
 		// 读库
 		return queryFn(value, expires)
-	}, func(newVal interface{}) error {
+	}, nil, func(newVal interface{}, delta time.Duration) error {
 		// 设置缓存有效期
-		return n.SetEx(key, newVal, expires)
+		return n.setWithMeta(key, newVal, expires, delta)
+	})
+}
+
+// TakeExCtx 同 TakeEx，启用提前刷新或过期后返回旧值时，queryFn 还会在后台刷新缓存，见 IsRefresh
+func (n node) TakeExCtx(ctx context.Context, dest interface{}, key string,
+	queryFn func(ctx context.Context, v interface{}, expires time.Duration) error) error {
+	expires := n.aroundDuration(n.expires)
+	return n.doTake(dest, key, func(v interface{}) error {
+		return queryFn(ctx, v, expires)
+	}, func(v interface{}) error {
+		return queryFn(refreshContext(ctx), v, expires)
+	}, func(newVal interface{}, delta time.Duration) error {
+		return n.setWithMeta(key, newVal, expires, delta)
 	})
 }
 
@@ -142,24 +178,31 @@ func (n node) asyncRetryDelCache(keys ...string) {
 }
 
 func (n node) doGet(key string, dest interface{}) error {
+	_, err := n.doGetWithMeta(key, dest)
+	return err
+}
+
+// doGetWithMeta 读取缓存，同时返回缓存值的刷新元数据
+func (n node) doGetWithMeta(key string, dest interface{}) (*cacheMeta, error) {
 	n.stat.IncrTotal()
 	result, err := n.redis.Get(key)
 	if err != nil {
 		n.stat.IncrMiss()
-		return err
+		return nil, err
 	}
 
 	if len(result) == 0 {
 		n.stat.IncrMiss()
-		return n.errNotFound
+		return nil, n.errNotFound
 	}
 
 	n.stat.IncrHit()
 	if result == notFoundPlaceholder {
-		return errPlaceholder
+		return nil, errPlaceholder
 	}
 
-	return n.processCache(key, result, dest)
+	meta, data := parseMeta(result)
+	return meta, n.processCache(key, data, dest)
 }
 
 func (n node) doMGet(keys []string, dest []interface{}) error {
@@ -184,10 +227,18 @@ func (n node) doMGet(keys []string, dest []interface{}) error {
 	return nil
 }
 
-func (n node) doTake(dest interface{}, key string, queryFn func(newVal interface{}) error, cacheValFn func(newVal interface{}) error) error {
+// doTake 读取缓存，读不到时用 queryFn 查库并缓存。
+// refreshFn 用于后台刷新缓存，为 nil 时不在后台刷新，过期的旧值按未命中处理。
+func (n node) doTake(dest interface{}, key string, queryFn, refreshFn func(newVal interface{}) error,
+	cacheValFn func(newVal interface{}, delta time.Duration) error) error {
 	// 防缓存击穿 barrier -> SingleFlight
 	result, hit, err := n.barrier.Do(key, func() (interface{}, error) {
-		if err := n.doGet(key, dest); err != nil {
+		meta, err := n.doGetWithMeta(key, dest)
+		if err == nil && meta != nil && refreshFn == nil && meta.expired() {
+			resetValue(dest)
+			err = n.errNotFound
+		}
+		if err != nil {
 			if err == errPlaceholder {
 				return nil, n.errNotFound
 			} else if err != n.errNotFound {
@@ -196,6 +247,7 @@ func (n node) doTake(dest interface{}, key string, queryFn func(newVal interface
 			}
 
 			// 查库
+			start := timex.Now()
 			if err := queryFn(dest); err == n.errNotFound {
 				// 防缓存穿透
 				if err = n.setWithNotFound(key); err != nil {
@@ -209,9 +261,12 @@ func (n node) doTake(dest interface{}, key string, queryFn func(newVal interface
 			}
 
 			// 缓存数据库新查询值
-			if err = cacheValFn(dest); err != nil {
+			if err = cacheValFn(dest, timex.Since(start)); err != nil {
 				logx.Error(err)
 			}
+		} else if meta != nil && refreshFn != nil && n.shouldRefresh(meta) {
+			// 返回当前值，同时在后台提前刷新或刷新已过期的旧值
+			n.asyncRefresh(key, dest, refreshFn, cacheValFn)
 		}
 
 		return jsoniter.Marshal(dest)
@@ -254,6 +309,8 @@ func (n node) processCaches(values []string, dest []interface{}, keys ...string)
 			continue
 		}
 
+		_, value = parseMeta(value)
+
 		var v interface{}
 		err := jsoniter.UnmarshalFromString(value, &v)
 		if err == nil {
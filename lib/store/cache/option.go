@@ -5,6 +5,7 @@ import "time"
 const (
 	defaultExpires         = time.Hour * 24 * 7
 	defaultNotFoundExpires = time.Minute // 防缓存穿透，设置未找到记录一分钟过期
+	defaultLocalChannel    = "god:cache:invalidate"
 )
 
 type (
@@ -12,6 +13,12 @@ type (
 	Options struct {
 		Expires         time.Duration
 		NotFoundExpires time.Duration
+		LocalExpires    time.Duration // 本地一级缓存过期时间，为 0 时不启用
+		LocalLimit      int           // 本地一级缓存最大条数
+		LocalExcludes   []string      // 不使用本地一级缓存的键前缀
+		LocalChannel    string        // 本地一级缓存失效广播频道
+		RefreshBeta     float64       // 提前刷新系数，为 0 时不提前刷新
+		StaleExpires    time.Duration // 过期后仍可返回旧值的时长，为 0 时不返回旧值
 	}
 
 	// Option 自定义缓存选项的函数。
@@ -30,6 +37,9 @@ func newOptions(opts ...Option) Options {
 	if o.NotFoundExpires <= 0 {
 		o.NotFoundExpires = defaultNotFoundExpires
 	}
+	if len(o.LocalChannel) == 0 {
+		o.LocalChannel = defaultLocalChannel
+	}
 
 	return o
 }
@@ -47,3 +57,50 @@ func WithNotFoundExpires(expires time.Duration) Option {
 		o.NotFoundExpires = expires
 	}
 }
+
+// WithLocalCache 返回一个启用本地一级缓存的函数。
+// 本地缓存位于 redis 之前，最多缓存 limit 条，每条缓存 expires 后过期，
+// 删除或更新缓存时通过 redis 发布订阅通知其他实例失效本地缓存。
+func WithLocalCache(expires time.Duration, limit int) Option {
+	return func(o *Options) {
+		o.LocalExpires = expires
+		o.LocalLimit = limit
+	}
+}
+
+// WithoutLocalCache 返回一个指定键前缀不使用本地一级缓存的函数，适用于一致性要求高的数据。
+func WithoutLocalCache(prefixes ...string) Option {
+	return func(o *Options) {
+		o.LocalExcludes = append(o.LocalExcludes, prefixes...)
+	}
+}
+
+// WithLocalChannel 返回一个自定义本地一级缓存失效广播频道的函数。
+func WithLocalChannel(channel string) Option {
+	return func(o *Options) {
+		o.LocalChannel = channel
+	}
+}
+
+// WithEarlyRefresh 返回一个启用概率提前刷新的函数。
+// TakeCtx 和 TakeExCtx 读到缓存时，按上次查库耗时和剩余有效期计算刷新概率（XFetch 算法），
+// 越临近过期、查库越慢越可能在后台提前刷新，避免热点缓存同时过期瞬间冲击数据库。
+// beta 通常取 1，大于 1 时更早刷新。
+// 后台刷新在调用返回后执行查询函数，传入新建的值和不会被取消的上下文，见 IsRefresh；
+// Take 和 TakeEx 的查询函数不带上下文，不在后台刷新。
+// 启用后缓存值带有刷新元数据，滚动发布时旧版本实例会把它当作无效缓存重新查库，宜在全部实例升级后再启用。
+func WithEarlyRefresh(beta float64) Option {
+	return func(o *Options) {
+		o.RefreshBeta = beta
+	}
+}
+
+// WithStaleWhileRevalidate 返回一个启用过期后返回旧值的函数。
+// 缓存过期后的 stale 时长内，TakeCtx 和 TakeExCtx 直接返回旧值，同时在后台刷新一次，
+// 后台刷新的约定同 WithEarlyRefresh；Take 和 TakeEx 不返回过期的旧值，而是同步查库。
+// 缓存值格式的兼容性同 WithEarlyRefresh。
+func WithStaleWhileRevalidate(stale time.Duration) Option {
+	return func(o *Options) {
+		o.StaleExpires = stale
+	}
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.zc0901.com/go/god/lib/contextx"
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/threading"
+	"git.zc0901.com/go/god/lib/timex"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// 带刷新元数据的缓存值前缀，JSON 值不会以该字符开头。
+	// 格式为 ~过期时间毫秒,查库耗时毫秒,JSON值，读取时同时兼容不带元数据的 JSON 值。
+	// 不支持该格式的旧版本实例会把它当作无效缓存删除后重新查库，直接读 redis 的程序需要自行去掉前缀。
+	metaPrefix = '~'
+
+	refreshKeyPrefix    = "refresh:"
+	refreshLockSuffix   = ":refresh"
+	refreshLockDuration = 10 // 跨实例刷新锁的秒数，防止多个实例同时刷新同一个键
+)
+
+type (
+	// cacheMeta 缓存值的刷新元数据
+	cacheMeta struct {
+		expiry time.Time     // 逻辑过期时间，redis 中的实际过期时间还要加上旧值可用时长
+		delta  time.Duration // 上次查库耗时
+	}
+
+	// 后台刷新的上下文键
+	refreshKey struct{}
+)
+
+// IsRefresh 判断 TakeCtx 和 TakeExCtx 的查询函数是否在后台刷新缓存。
+// 后台刷新在 Take 返回之后进行，查询函数收到的是新建的值，而不是调用方的 dest，
+// 上下文只保留调用方上下文中的值，不会被取消，查询函数不能写入调用方的变量。
+func IsRefresh(ctx context.Context) bool {
+	refresh, ok := ctx.Value(refreshKey{}).(bool)
+	return ok && refresh
+}
+
+func refreshContext(ctx context.Context) context.Context {
+	return context.WithValue(contextx.ValueOnlyFrom(ctx), refreshKey{}, true)
+}
+
+// expired 判断缓存值是否已过逻辑过期时间
+func (m *cacheMeta) expired() bool {
+	return !time.Now().Before(m.expiry)
+}
+
+// resetValue 把 dest 指向的值重置为零值
+func resetValue(dest interface{}) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
+
+// formatMeta 为缓存值加上刷新元数据
+func formatMeta(data []byte, expiry time.Time, delta time.Duration) string {
+	var b strings.Builder
+	b.WriteByte(metaPrefix)
+	b.WriteString(strconv.FormatInt(expiry.UnixNano()/int64(time.Millisecond), 10))
+	b.WriteByte(',')
+	b.WriteString(strconv.FormatInt(int64(delta/time.Millisecond), 10))
+	b.WriteByte(',')
+	b.Write(data)
+	return b.String()
+}
+
+// parseMeta 拆分缓存值中的刷新元数据，没有元数据时 meta 为 nil
+func parseMeta(value string) (meta *cacheMeta, data string) {
+	if len(value) == 0 || value[0] != metaPrefix {
+		return nil, value
+	}
+
+	fields := strings.SplitN(value[1:], ",", 3)
+	if len(fields) != 3 {
+		return nil, value
+	}
+
+	expiry, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, value
+	}
+	delta, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil, value
+	}
+
+	return &cacheMeta{
+		expiry: time.Unix(0, expiry*int64(time.Millisecond)),
+		delta:  time.Duration(delta) * time.Millisecond,
+	}, fields[2]
+}
+
+func (n node) refreshEnabled() bool {
+	return n.refreshBeta > 0 || n.staleExpires > 0
+}
+
+// setWithMeta 缓存查库结果，启用刷新时同时保存过期时间和查库耗时
+func (n node) setWithMeta(key string, value interface{}, expires, delta time.Duration) error {
+	if !n.refreshEnabled() {
+		return n.SetEx(key, value, expires)
+	}
+
+	data, err := jsoniter.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	// redis 的过期秒数至少为 1
+	seconds := int((expires + n.staleExpires).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	expiry := time.Now().Add(expires)
+	return n.redis.SetEx(key, formatMeta(data, expiry, delta), seconds)
+}
+
+// shouldRefresh 判断是否需要在后台刷新缓存：
+// 已过逻辑过期时间的旧值总是需要刷新，未过期时按 XFetch 算法
+// now - delta * beta * ln(rand()) >= expiry 决定是否提前刷新。
+func (n node) shouldRefresh(meta *cacheMeta) bool {
+	if meta.expired() {
+		return true
+	}
+	if n.refreshBeta <= 0 || meta.delta <= 0 {
+		return false
+	}
+
+	n.lock.Lock()
+	r := n.rnd.Float64()
+	n.lock.Unlock()
+	if r <= 0 {
+		return true
+	}
+
+	gap := float64(meta.delta) * n.refreshBeta * -math.Log(r)
+	return gap >= float64(time.Until(meta.expiry))
+}
+
+// asyncRefresh 在后台刷新一次缓存，进程内通过 barrier、跨实例通过 redis 锁保证同一键只有一个刷新
+func (n node) asyncRefresh(key string, dest interface{}, queryFn func(newVal interface{}) error,
+	cacheValFn func(newVal interface{}, delta time.Duration) error) {
+	typ := reflect.TypeOf(dest).Elem()
+	threading.GoSafe(func() {
+		_, _, _ = n.barrier.Do(refreshKeyPrefix+key, func() (interface{}, error) {
+			n.refresh(key, reflect.New(typ).Interface(), queryFn, cacheValFn)
+			return nil, nil
+		})
+	})
+}
+
+func (n node) refresh(key string, val interface{}, queryFn func(newVal interface{}) error,
+	cacheValFn func(newVal interface{}, delta time.Duration) error) {
+	lockKey := key + refreshLockSuffix
+	ok, err := n.redis.SetNXEx(lockKey, "1", refreshLockDuration)
+	if err != nil {
+		logx.Errorf("获取缓存刷新锁失败，键：%s，错误：%v", key, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if _, err := n.redis.Del(lockKey); err != nil {
+			logx.Errorf("释放缓存刷新锁失败，键：%s，错误：%v", key, err)
+		}
+	}()
+
+	start := timex.Now()
+	if err = queryFn(val); err == n.errNotFound {
+		if err = n.setWithNotFound(key); err != nil {
+			logx.Error(err)
+		}
+		return
+	} else if err != nil {
+		n.stat.IncrDbFails()
+		logx.Errorf("后台刷新缓存查库失败，键：%s，错误：%v", key, err)
+		return
+	}
+
+	if err = cacheValFn(val, timex.Since(start)); err != nil {
+		logx.Error(err)
+	}
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
+	"git.zc0901.com/go/god/lib/syncx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMeta(t *testing.T) {
+	expiry := time.Unix(1600000000, 123000000)
+	value := formatMeta([]byte(`{"name":"god"}`), expiry, 25*time.Millisecond)
+	meta, data := parseMeta(value)
+	assert.NotNil(t, meta)
+	assert.True(t, expiry.Equal(meta.expiry))
+	assert.Equal(t, 25*time.Millisecond, meta.delta)
+	assert.Equal(t, `{"name":"god"}`, data)
+
+	for _, v := range []string{`{"name":"god"}`, `"~abc"`, "~abc", "~1,2", ""} {
+		meta, data = parseMeta(v)
+		assert.Nil(t, meta)
+		assert.Equal(t, v, data)
+	}
+}
+
+func TestNode_ShouldRefresh(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	n := newCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("refresh"), errTestNotFound,
+		newOptions(WithStaleWhileRevalidate(time.Minute)))
+	assert.True(t, n.shouldRefresh(&cacheMeta{expiry: time.Now().Add(-time.Second)}))
+	assert.False(t, n.shouldRefresh(&cacheMeta{expiry: time.Now().Add(time.Second), delta: time.Hour}))
+
+	n.refreshBeta = 1
+	assert.True(t, n.shouldRefresh(&cacheMeta{expiry: time.Now().Add(time.Millisecond), delta: time.Hour}))
+	assert.False(t, n.shouldRefresh(&cacheMeta{expiry: time.Now().Add(time.Hour)}))
+}
+
+func TestNode_StaleWhileRevalidate(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	c := NewCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("refresh"), errTestNotFound,
+		WithStaleWhileRevalidate(time.Minute))
+	var queries int32
+	ctx, cancel := context.WithCancel(context.Background())
+	query := func(ctx context.Context, v interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		atomic.AddInt32(&queries, 1)
+		*v.(*string) = "fresh"
+		return nil
+	}
+
+	// 已过期但仍在旧值可用期内，直接返回旧值并在后台刷新，刷新不受调用方上下文取消的影响
+	assert.Nil(t, r.Set("any", formatMeta([]byte(`"stale"`), time.Now().Add(-time.Second), time.Millisecond)))
+	var val string
+	assert.Nil(t, c.TakeCtx(ctx, &val, "any", query))
+	cancel()
+	assert.Equal(t, "stale", val)
+
+	assert.Eventually(t, func() bool {
+		var val string
+		return c.Get("any", &val) == nil && val == "fresh"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queries))
+
+	// 刷新后未过期，不再查库
+	assert.Nil(t, c.TakeCtx(context.Background(), &val, "any", query))
+	assert.Equal(t, "fresh", val)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queries))
+
+	// redis 中的过期时间包含旧值可用时长
+	value, err := r.Get("any")
+	assert.Nil(t, err)
+	meta, _ := parseMeta(value)
+	assert.NotNil(t, meta)
+	ttl, err := r.TTL("any")
+	assert.Nil(t, err)
+	assert.True(t, time.Duration(ttl)*time.Second > time.Until(meta.expiry)+time.Minute-2*time.Second)
+}
+
+func TestNode_EarlyRefresh(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	c := NewCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("refresh"), errTestNotFound,
+		WithEarlyRefresh(1))
+
+	// 查库耗时远大于剩余有效期，必然提前刷新
+	assert.Nil(t, r.Set("any", formatMeta([]byte(`"old"`), time.Now().Add(time.Second), time.Hour)))
+	var val string
+	assert.Nil(t, c.TakeExCtx(context.Background(), &val, "any",
+		func(ctx context.Context, v interface{}, expires time.Duration) error {
+			// 后台刷新时收到的是新建的值
+			assert.True(t, IsRefresh(ctx))
+			assert.True(t, v != &val)
+			*v.(*string) = "new"
+			return nil
+		}))
+	assert.Equal(t, "old", val)
+
+	assert.Eventually(t, func() bool {
+		var val string
+		return c.Get("any", &val) == nil && val == "new"
+	}, time.Second, 10*time.Millisecond)
+
+	// 刷新锁已释放
+	ok, err := r.Exists("any" + refreshLockSuffix)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestNode_TakeWithoutRefresh(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	c := NewCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("refresh"), errTestNotFound,
+		WithEarlyRefresh(1), WithStaleWhileRevalidate(time.Minute))
+
+	// Take 的查询函数可能写入调用方的变量，不在后台刷新，过期的旧值同步查库
+	assert.Nil(t, r.Set("any", formatMeta([]byte(`"stale"`), time.Now().Add(-time.Second), time.Millisecond)))
+	var val string
+	assert.Nil(t, c.Take(&val, "any", func(v interface{}) error {
+		*v.(*string) = "fresh"
+		return nil
+	}))
+	assert.Equal(t, "fresh", val)
+
+	// 临近过期也不提前刷新
+	assert.Nil(t, r.Set("any", formatMeta([]byte(`"old"`), time.Now().Add(time.Second), time.Hour)))
+	assert.Nil(t, c.TakeEx(&val, "any", func(v interface{}, expires time.Duration) error {
+		t.Fatal("不应查库")
+		return nil
+	}))
+	assert.Equal(t, "old", val)
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestNode_SetWithMetaMinExpires(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	n := newCacheNode(r, syncx.NewSingleFlight(), NewCacheStat("refresh"), errTestNotFound,
+		newOptions(WithEarlyRefresh(1)))
+	assert.Nil(t, n.setWithMeta("any", "value", 100*time.Millisecond, time.Millisecond))
+	ttl, err := r.TTL("any")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, ttl)
+}
@@ -16,6 +16,9 @@ type Stat struct {
 	Hit     uint64 // 一分钟命中数
 	Miss    uint64 // 一分钟未命中数
 	DbFails uint64 // 一分钟查库失败数
+
+	LocalHit  uint64 // 一分钟本地一级缓存命中数
+	LocalMiss uint64 // 一分钟本地一级缓存未命中数
 }
 
 func NewCacheStat(name string) *Stat {
@@ -31,6 +34,8 @@ func (s *Stat) Loop() {
 	for {
 		select {
 		case <-ticker.C:
+			s.statLocal()
+
 			total := atomic.SwapUint64(&s.Total, 0)
 			if total == 0 {
 				continue
@@ -61,3 +66,25 @@ func (s *Stat) IncrMiss() {
 func (s *Stat) IncrDbFails() {
 	atomic.AddUint64(&s.DbFails, 1)
 }
+
+func (s *Stat) IncrLocalHit() {
+	atomic.AddUint64(&s.LocalHit, 1)
+}
+
+func (s *Stat) IncrLocalMiss() {
+	atomic.AddUint64(&s.LocalMiss, 1)
+}
+
+// statLocal 输出本地一级缓存统计，未命中的请求会继续计入 redis 缓存统计
+func (s *Stat) statLocal() {
+	hit := atomic.SwapUint64(&s.LocalHit, 0)
+	miss := atomic.SwapUint64(&s.LocalMiss, 0)
+	total := hit + miss
+	if total == 0 {
+		return
+	}
+
+	percent := 100 * float32(hit) / float32(total)
+	logx.Statf("本地缓存(%s) - 一分钟请求数: %d, 命中率: %.1f%%, 命中: %d, 未命中: %d",
+		s.name, total, percent, hit, miss)
+}
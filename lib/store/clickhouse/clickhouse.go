@@ -1,8 +1,15 @@
 package clickhouse
 
-import "git.zc0901.com/go/god/lib/store/sqlx"
+import (
+	"git.zc0901.com/go/god/lib/store/sqlx"
+	// 注册 clickhouse 驱动
+	_ "github.com/ClickHouse/clickhouse-go"
+)
 
-// New 创建 ClickHouse 数据库实例
+const clickHouseDriverName = "clickhouse"
+
+// New 创建 ClickHouse 数据库实例，
+// dataSourceName 形如 "tcp://127.0.0.1:9000?database=default&username=default&password="
 func New(dataSourceName string, opts ...sqlx.Option) sqlx.Conn {
-	return sqlx.NewConn("clickhouse", dataSourceName, opts...)
+	return sqlx.NewConn(clickHouseDriverName, dataSourceName, opts...)
 }
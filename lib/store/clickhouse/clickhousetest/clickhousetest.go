@@ -0,0 +1,266 @@
+package clickhousetest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"git.zc0901.com/go/god/lib/store/sqlx"
+	"git.zc0901.com/go/god/lib/stringx"
+)
+
+const driverName = "clickhousetest"
+
+var (
+	// ErrInsertNotInBatch 与 ClickHouse 驱动一致，insert 语句只能在批处理事务中预编译执行
+	ErrInsertNotInBatch = errors.New("insert statement supported only in the batch mode (use begin/commit)")
+
+	servers sync.Map
+)
+
+type (
+	// Server 进程内的 ClickHouse 替身，记录批量写入的数据，并按预设结果响应查询
+	Server struct {
+		lock    sync.Mutex
+		batches map[string][][][]interface{}
+		execs   []string
+		results []result
+	}
+
+	result struct {
+		query   string
+		columns []string
+		rows    [][]interface{}
+	}
+
+	fakeDriver struct{}
+
+	fakeConn struct {
+		server *Server
+		tx     *fakeTx
+	}
+
+	fakeTx struct {
+		conn    *fakeConn
+		pending map[string][][]interface{}
+	}
+
+	fakeStmt struct {
+		conn  *fakeConn
+		query string
+	}
+
+	fakeRows struct {
+		columns []string
+		rows    [][]interface{}
+		index   int
+	}
+)
+
+func init() {
+	sql.Register(driverName, fakeDriver{})
+}
+
+// CreateClickHouse 创建 ClickHouse 替身和连接到它的 sqlx.Conn，
+// 可直接传给 clickhouse.NewWriter 或业务模型做单元测试。
+func CreateClickHouse() (sqlx.Conn, *Server) {
+	server := &Server{
+		batches: make(map[string][][][]interface{}),
+	}
+	dsn := stringx.RandId()
+	servers.Store(dsn, server)
+
+	return sqlx.NewConn(driverName, dsn), server
+}
+
+// Batches 返回写入指定表的数据块，每个数据块为一次提交的所有行
+func (s *Server) Batches(table string) [][][]interface{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([][][]interface{}(nil), s.batches[table]...)
+}
+
+// Rows 返回写入指定表的所有行
+func (s *Server) Rows(table string) [][]interface{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var rows [][]interface{}
+	for _, batch := range s.batches[table] {
+		rows = append(rows, batch...)
+	}
+	return rows
+}
+
+// Execs 返回执行过的非写入语句，如建表、删除分区等
+func (s *Server) Execs() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]string(nil), s.execs...)
+}
+
+// SetResult 预设查询结果，语句包含 query 时返回 columns 和 rows，后设置的优先匹配
+func (s *Server) SetResult(query string, columns []string, rows ...[]interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.results = append([]result{{
+		query:   query,
+		columns: columns,
+		rows:    rows,
+	}}, s.results...)
+}
+
+func (s *Server) commit(pending map[string][][]interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for table, rows := range pending {
+		s.batches[table] = append(s.batches[table], rows)
+	}
+}
+
+func (s *Server) exec(query string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.execs = append(s.execs, query)
+}
+
+func (s *Server) query(query string) *fakeRows {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, r := range s.results {
+		if strings.Contains(query, r.query) {
+			return &fakeRows{
+				columns: r.columns,
+				rows:    r.rows,
+			}
+		}
+	}
+
+	return &fakeRows{}
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	server, ok := servers.Load(dsn)
+	if !ok {
+		return nil, errors.New("clickhousetest: 未知的数据源 " + dsn)
+	}
+
+	return &fakeConn{server: server.(*Server)}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if isInsert(query) && c.tx == nil {
+		return nil, ErrInsertNotInBatch
+	}
+
+	return &fakeStmt{
+		conn:  c,
+		query: query,
+	}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.tx = &fakeTx{
+		conn:    c,
+		pending: make(map[string][][]interface{}),
+	}
+	return c.tx, nil
+}
+
+// CheckNamedValue 与 ClickHouse 驱动一样接受数组等任意类型的参数
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.conn.server.commit(tx.pending)
+	tx.conn.tx = nil
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.conn.tx = nil
+	return nil
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if !isInsert(s.query) {
+		s.conn.server.exec(s.query)
+		return driver.RowsAffected(0), nil
+	}
+
+	tx := s.conn.tx
+	if tx == nil {
+		return nil, ErrInsertNotInBatch
+	}
+
+	row := make([]interface{}, len(args))
+	for i, arg := range args {
+		row[i] = arg
+	}
+	table := tableName(s.query)
+	tx.pending[table] = append(tx.pending[table], row)
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return s.conn.server.query(s.query), nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.rows) {
+		return io.EOF
+	}
+
+	for i, v := range r.rows[r.index] {
+		if i < len(dest) {
+			dest[i] = v
+		}
+	}
+	r.index++
+	return nil
+}
+
+func isInsert(query string) bool {
+	fields := strings.Fields(query)
+	return len(fields) > 2 && strings.EqualFold(fields[0], "insert") && strings.EqualFold(fields[1], "into")
+}
+
+// tableName 从 insert into table (a, b) 语句中获取表名
+func tableName(query string) string {
+	name := strings.Fields(query)[2]
+	if pos := strings.IndexByte(name, '('); pos >= 0 {
+		name = name[:pos]
+	}
+	return name
+}
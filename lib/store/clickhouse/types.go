@@ -0,0 +1,67 @@
+package clickhouse
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	ch "github.com/ClickHouse/clickhouse-go"
+)
+
+// Array 包装数组字段的值，如 []string、[]int64 等
+func Array(v interface{}) interface{} {
+	return ch.Array(v)
+}
+
+// ArrayDate 包装 Array(Date) 字段的值
+func ArrayDate(v []time.Time) interface{} {
+	return ch.ArrayDate(v)
+}
+
+// ArrayDateTime 包装 Array(DateTime) 字段的值
+func ArrayDateTime(v []time.Time) interface{} {
+	return ch.ArrayDateTime(v)
+}
+
+// SplitMap 将 map 按键排序拆分为键、值两个数组。
+// 驱动暂不支持 Map 类型，映射数据通常以 Nested(key String, value String)
+// 或 keys Array(String)、values Array(String) 两列存储。
+func SplitMap(m map[string]string) (keys, values []string) {
+	keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values = make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, m[k])
+	}
+
+	return keys, values
+}
+
+// JoinMap 将 SplitMap 拆分的键、值两个数组还原为 map，多余的键或值被忽略
+func JoinMap(keys, values []string) map[string]string {
+	m := make(map[string]string, len(keys))
+	for i, k := range keys {
+		if i >= len(values) {
+			break
+		}
+		m[k] = values[i]
+	}
+
+	return m
+}
+
+// DateTime64 返回指定精度的 DateTime64 字面量表达式，用于查询条件。
+// 驱动绑定 time.Time 参数时只保留到秒，需要毫秒及以上精度比较时使用该表达式拼接语句。
+// 时间统一转为 UTC，避免服务端不识别 Local 等时区名称。
+func DateTime64(t time.Time, precision int) string {
+	layout := "2006-01-02 15:04:05"
+	if precision > 0 {
+		layout += "." + fmt.Sprintf("%0*d", precision, 0)
+	}
+
+	return fmt.Sprintf("toDateTime64('%s', %d, 'UTC')", t.UTC().Format(layout), precision)
+}
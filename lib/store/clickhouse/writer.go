@@ -0,0 +1,178 @@
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"git.zc0901.com/go/god/lib/executors"
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/store/sqlx"
+)
+
+const (
+	// 默认每批写入行数，ClickHouse 建议每次写入至少上千行
+	defaultBatchSize = 10000
+
+	// 默认最长刷新间隔
+	defaultFlushInterval = time.Second
+
+	valuesTag = "values"
+)
+
+var (
+	ErrNoColumns      = errors.New("写入语句中没有找到字段列表")
+	ErrColumnMismatch = errors.New("写入值个数与字段个数不匹配")
+)
+
+type (
+	// Writer ClickHouse 批量写入器。
+	// ClickHouse 不适合逐行写入，写入器缓冲行数据，行数达到批次大小或到达刷新间隔时，
+	// 在一个批处理事务中将缓冲的行作为一个数据块提交。
+	Writer struct {
+		conn     sqlx.Conn
+		stmt     string
+		numCols  int
+		handler  ResultHandler
+		executor *executors.BulkExecutor
+	}
+
+	// ResultHandler 批次写入结果处理器，rows 为该批次的行数
+	ResultHandler func(rows int, err error)
+
+	// WriterOption 自定义批量写入器的方法
+	WriterOption func(options *writerOptions)
+
+	writerOptions struct {
+		batchSize     int
+		flushInterval time.Duration
+		handler       ResultHandler
+	}
+)
+
+// NewWriter 新建批量写入器，stmt 形如 "insert into events (id, name, tags)"，
+// 也可带上 values 子句，如 "insert into events (id, name, tags) values (?, ?, ?)"。
+func NewWriter(conn sqlx.Conn, stmt string, opts ...WriterOption) (*Writer, error) {
+	options := writerOptions{
+		batchSize:     defaultBatchSize,
+		flushInterval: defaultFlushInterval,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	insertStmt, numCols, err := parseInsertStmt(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		conn:    conn,
+		stmt:    insertStmt,
+		numCols: numCols,
+		handler: options.handler,
+	}
+	w.executor = executors.NewBulkExecutor(w.execute, executors.WithBulkSize(options.batchSize),
+		executors.WithBulkInterval(options.flushInterval))
+
+	return w, nil
+}
+
+// WithBatchSize 自定义每批写入的行数
+func WithBatchSize(size int) WriterOption {
+	return func(options *writerOptions) {
+		options.batchSize = size
+	}
+}
+
+// WithFlushInterval 自定义最长刷新间隔
+func WithFlushInterval(interval time.Duration) WriterOption {
+	return func(options *writerOptions) {
+		options.flushInterval = interval
+	}
+}
+
+// WithResultHandler 自定义批次写入结果处理器，默认仅记录错误日志
+func WithResultHandler(handler ResultHandler) WriterOption {
+	return func(options *writerOptions) {
+		options.handler = handler
+	}
+}
+
+// Write 写入一行，values 与字段顺序一致。
+// 数组字段使用 Array 等方法包装，时间字段直接传 time.Time，DateTime64 的精度由字段类型决定。
+func (w *Writer) Write(values ...interface{}) error {
+	if len(values) != w.numCols {
+		return ErrColumnMismatch
+	}
+
+	return w.executor.Add(values)
+}
+
+// Flush 立即写入缓冲的行
+func (w *Writer) Flush() {
+	w.executor.Flush()
+}
+
+func (w *Writer) execute(tasks []interface{}) {
+	if len(tasks) == 0 {
+		return
+	}
+
+	err := w.conn.Transact(func(tx sqlx.TxSession) error {
+		// ClickHouse 驱动在批处理事务中预编译 insert 语句，逐行追加到数据块，提交时一次发送
+		stmt, err := tx.Prepare(w.stmt)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, task := range tasks {
+			if _, err := stmt.Exec(task.([]interface{})...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if w.handler != nil {
+		w.handler(len(tasks), err)
+	} else if err != nil {
+		logx.Errorf("[ClickHouse 批量写入] SQL: %s, 行数: %d, 错误: %s", w.stmt, len(tasks), err)
+	}
+}
+
+// parseInsertStmt 解析写入语句，返回带 values 占位符的语句和字段个数
+func parseInsertStmt(stmt string) (string, int, error) {
+	stmt = strings.TrimSpace(stmt)
+	lowerStmt := strings.ToLower(stmt)
+
+	if pos := strings.Index(lowerStmt, valuesTag); pos > 0 {
+		numArgs := strings.Count(lowerStmt[pos:], "?")
+		if numArgs == 0 {
+			return "", 0, fmt.Errorf("没有变量占位符: %q", stmt)
+		}
+		return stmt, numArgs, nil
+	}
+
+	left := strings.IndexByte(stmt, '(')
+	right := strings.LastIndexByte(stmt, ')')
+	if left < 0 || right < left {
+		return "", 0, ErrNoColumns
+	}
+
+	var numCols int
+	for _, col := range strings.Split(stmt[left+1:right], ",") {
+		if len(strings.TrimSpace(col)) > 0 {
+			numCols++
+		}
+	}
+	if numCols == 0 {
+		return "", 0, ErrNoColumns
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", numCols), ", ")
+	return fmt.Sprintf("%s values (%s)", stmt, placeholders), numCols, nil
+}
@@ -0,0 +1,90 @@
+package clickhouse
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"git.zc0901.com/go/god/lib/store/clickhouse/clickhousetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInsertStmt(t *testing.T) {
+	stmt, numCols, err := parseInsertStmt("insert into events (id, name, tags)")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, numCols)
+	assert.Equal(t, "insert into events (id, name, tags) values (?, ?, ?)", stmt)
+
+	stmt, numCols, err = parseInsertStmt("INSERT INTO events (id, name) VALUES (?, ?)")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numCols)
+	assert.Equal(t, "INSERT INTO events (id, name) VALUES (?, ?)", stmt)
+
+	_, _, err = parseInsertStmt("insert into events")
+	assert.Equal(t, ErrNoColumns, err)
+}
+
+func TestWriter(t *testing.T) {
+	conn, server := clickhousetest.CreateClickHouse()
+	var lock sync.Mutex
+	var written []int
+	w, err := NewWriter(conn, "insert into events (id, name, tags)", WithBatchSize(2),
+		WithFlushInterval(time.Hour), WithResultHandler(func(rows int, err error) {
+			assert.Nil(t, err)
+			lock.Lock()
+			written = append(written, rows)
+			lock.Unlock()
+		}))
+	assert.Nil(t, err)
+
+	assert.Equal(t, ErrColumnMismatch, w.Write(1, "a"))
+	assert.Nil(t, w.Write(int64(1), "a", Array([]string{"x"})))
+	assert.Nil(t, w.Write(int64(2), "b", Array([]string{"y", "z"})))
+	assert.Nil(t, w.Write(int64(3), "c", Array([]string{})))
+	w.Flush()
+
+	assert.Eventually(t, func() bool {
+		return len(server.Batches("events")) == 2
+	}, time.Second, 10*time.Millisecond)
+	rows := server.Rows("events")
+	assert.Equal(t, 3, len(rows))
+	assert.Equal(t, []string{"y", "z"}, rows[1][2])
+	lock.Lock()
+	assert.ElementsMatch(t, []int{2, 1}, written)
+	lock.Unlock()
+}
+
+func TestQueryOnFake(t *testing.T) {
+	conn, server := clickhousetest.CreateClickHouse()
+	server.SetResult("from events", []string{"id", "name"}, []interface{}{int64(1), "a"},
+		[]interface{}{int64(2), "b"})
+
+	var events []struct {
+		Id   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	assert.Nil(t, conn.Query(&events, "select id, name from events where id > ?", 0))
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "b", events[1].Name)
+
+	_, err := conn.Exec("alter table events drop partition 202101")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alter table events drop partition 202101"}, server.Execs())
+
+	_, err = conn.Exec("insert into events (id, name) values (?, ?)", 1, "a")
+	assert.Equal(t, clickhousetest.ErrInsertNotInBatch, err)
+}
+
+func TestSplitMap(t *testing.T) {
+	keys, values := SplitMap(map[string]string{"b": "2", "a": "1"})
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.Equal(t, []string{"1", "2"}, values)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, JoinMap(keys, values))
+	assert.Equal(t, map[string]string{"a": "1"}, JoinMap(keys, values[:1]))
+}
+
+func TestDateTime64(t *testing.T) {
+	tm := time.Date(2021, 3, 4, 5, 6, 7, 123456000, time.UTC)
+	assert.Equal(t, "toDateTime64('2021-03-04 05:06:07.123', 3, 'UTC')", DateTime64(tm, 3))
+	assert.Equal(t, "toDateTime64('2021-03-04 05:06:07', 0, 'UTC')", DateTime64(tm, 0))
+}
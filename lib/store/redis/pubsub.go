@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/syncx"
+	"git.zc0901.com/go/god/lib/threading"
+	red "github.com/go-redis/redis"
+)
+
+const (
+	defaultSubscriptionSize = 100
+	receiveTimeout          = 30 * time.Second
+	maxReconnectBackoff     = 5 * time.Second
+	minReconnectBackoff     = 100 * time.Millisecond
+	// 底层客户端被关闭后 go-redis 返回的错误，此时无法再重连
+	clientClosedError = "redis: client is closed"
+)
+
+type (
+	// Message 订阅收到的一条消息
+	Message = red.Message
+
+	// Subscription 一个订阅，断线后自动重连并重新订阅，通过 Channel 获取消息。
+	Subscription struct {
+		pubsub *red.PubSub
+		ch     chan *Message
+		done   *syncx.DoneChan
+	}
+
+	// 单机和集群客户端都实现了订阅
+	subscriber interface {
+		Subscribe(channels ...string) *red.PubSub
+		PSubscribe(channels ...string) *red.PubSub
+	}
+)
+
+// Publish 向频道发布消息，返回收到消息的订阅者数量。
+func (r *Redis) Publish(channel string, message interface{}) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.Publish(channel, message).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// Subscribe 订阅一个或多个频道。
+func (r *Redis) Subscribe(channels ...string) (*Subscription, error) {
+	return r.subscribe(func(s subscriber) *red.PubSub {
+		return s.Subscribe(channels...)
+	})
+}
+
+// PSubscribe 按模式订阅一个或多个频道，如 news.*。
+func (r *Redis) PSubscribe(patterns ...string) (*Subscription, error) {
+	return r.subscribe(func(s subscriber) *red.PubSub {
+		return s.PSubscribe(patterns...)
+	})
+}
+
+func (r *Redis) subscribe(fn func(s subscriber) *red.PubSub) (*Subscription, error) {
+	client, err := getClient(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := client.(subscriber)
+	if !ok {
+		return nil, fmt.Errorf("redis 客户端 %T 不支持订阅", client)
+	}
+
+	pubsub := fn(s)
+	// 等待订阅确认，确保返回时订阅已生效
+	if _, err := pubsub.Receive(); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	return newSubscription(pubsub), nil
+}
+
+func newSubscription(pubsub *red.PubSub) *Subscription {
+	sub := &Subscription{
+		pubsub: pubsub,
+		ch:     make(chan *Message, defaultSubscriptionSize),
+		done:   syncx.NewDoneChan(),
+	}
+	threading.GoSafe(sub.receive)
+
+	return sub
+}
+
+// Channel 返回接收消息的通道，Close 后该通道会被关闭。
+func (s *Subscription) Channel() <-chan *Message {
+	return s.ch
+}
+
+// Subscribe 追加订阅频道。
+func (s *Subscription) Subscribe(channels ...string) error {
+	return s.pubsub.Subscribe(channels...)
+}
+
+// PSubscribe 追加模式订阅。
+func (s *Subscription) PSubscribe(patterns ...string) error {
+	return s.pubsub.PSubscribe(patterns...)
+}
+
+// Unsubscribe 取消订阅频道，不传参数则取消所有频道。
+func (s *Subscription) Unsubscribe(channels ...string) error {
+	return s.pubsub.Unsubscribe(channels...)
+}
+
+// PUnsubscribe 取消模式订阅，不传参数则取消所有模式。
+func (s *Subscription) PUnsubscribe(patterns ...string) error {
+	return s.pubsub.PUnsubscribe(patterns...)
+}
+
+// Close 关闭订阅。
+func (s *Subscription) Close() error {
+	s.done.Close()
+	return s.pubsub.Close()
+}
+
+func (s *Subscription) receive() {
+	defer close(s.ch)
+
+	backoff := minReconnectBackoff
+	for {
+		msg, err := s.pubsub.ReceiveTimeout(receiveTimeout)
+		if s.closed() {
+			return
+		}
+
+		if err != nil {
+			if err.Error() == clientClosedError {
+				logx.Error("redis 客户端已关闭，订阅结束")
+				return
+			}
+			if e, ok := err.(net.Error); ok && e.Timeout() {
+				// 长时间无消息，主动探活，失败时下次接收会触发重连
+				if err = s.pubsub.Ping(); err == nil {
+					continue
+				}
+			}
+
+			// go-redis 会在下次接收时重新建连并恢复订阅
+			logx.Errorf("redis 订阅接收出错，%v 后重试：%s", backoff, err)
+			select {
+			case <-s.done.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < maxReconnectBackoff {
+				backoff <<= 1
+			}
+			continue
+		}
+
+		backoff = minReconnectBackoff
+		if m, ok := msg.(*Message); ok {
+			select {
+			case s.ch <- m:
+			case <-s.done.Done():
+				return
+			}
+		}
+	}
+}
+
+func (s *Subscription) closed() bool {
+	select {
+	case <-s.done.Done():
+		return true
+	default:
+		return false
+	}
+}
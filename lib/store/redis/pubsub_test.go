@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedis_Subscribe(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		sub, err := client.Subscribe("news")
+		assert.Nil(t, err)
+
+		n, err := client.Publish("news", "hello")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		select {
+		case msg := <-sub.Channel():
+			assert.Equal(t, "news", msg.Channel)
+			assert.Equal(t, "hello", msg.Payload)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for message")
+		}
+
+		assert.Nil(t, sub.Close())
+		select {
+		case _, ok := <-sub.Channel():
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("channel not closed")
+		}
+	})
+}
+
+func TestRedis_PSubscribe(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		sub, err := client.PSubscribe("news.*")
+		assert.Nil(t, err)
+		defer sub.Close()
+
+		_, err = client.Publish("news.sport", "goal")
+		assert.Nil(t, err)
+
+		select {
+		case msg := <-sub.Channel():
+			assert.Equal(t, "news.*", msg.Pattern)
+			assert.Equal(t, "news.sport", msg.Channel)
+			assert.Equal(t, "goal", msg.Payload)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for message")
+		}
+	})
+}
@@ -0,0 +1,314 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"git.zc0901.com/go/god/lib/logx"
+	"git.zc0901.com/go/god/lib/stringx"
+	"git.zc0901.com/go/god/lib/threading"
+	red "github.com/go-redis/redis"
+)
+
+const (
+	// 仅当锁值为自身令牌时才删除，避免误删他人的锁
+	delLockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+else
+    return 0
+end`
+	// 仅当锁值为自身令牌时才续期
+	renewLockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+    return 0
+end`
+	lockScript = `return redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2])`
+
+	lockTokenLen         = 16
+	defaultLockSeconds   = 30
+	defaultRetryInterval = 50 * time.Millisecond
+	tolerance            = 500 // 毫秒
+)
+
+var (
+	// ErrLockNotHeld 表示释放或续期时当前实例并未持有锁。
+	ErrLockNotHeld = errors.New("redis 锁未被持有")
+	// ErrLockAcquireTimeout 表示在上下文截止前未能获得锁。
+	ErrLockAcquireTimeout = errors.New("redis 锁获取超时")
+)
+
+type (
+	// RedisLock 基于 Redis 的分布式锁。
+	// 每个实例持有一个随机令牌，仅令牌匹配时才能释放或续期。
+	RedisLock struct {
+		store         *Redis
+		key           string
+		id            string
+		seconds       int
+		retryInterval time.Duration
+		reentrant     bool
+		watchdog      bool
+
+		lock    sync.Mutex
+		holds   int
+		stopDog chan struct{}
+	}
+
+	// LockOption 自定义 RedisLock 的方法。
+	LockOption func(rl *RedisLock)
+)
+
+// NewRedisLock 返回一个基于 store 的分布式锁。
+func NewRedisLock(store *Redis, key string, opts ...LockOption) *RedisLock {
+	rl := &RedisLock{
+		store:         store,
+		key:           key,
+		id:            stringx.Randn(lockTokenLen),
+		seconds:       defaultLockSeconds,
+		retryInterval: defaultRetryInterval,
+	}
+
+	for _, opt := range opts {
+		opt(rl)
+	}
+
+	return rl
+}
+
+// WithLockExpire 自定义锁的过期秒数。
+func WithLockExpire(seconds int) LockOption {
+	return func(rl *RedisLock) {
+		if seconds > 0 {
+			rl.seconds = seconds
+		}
+	}
+}
+
+// WithLockRetryInterval 自定义 Acquire 的重试间隔。
+func WithLockRetryInterval(interval time.Duration) LockOption {
+	return func(rl *RedisLock) {
+		if interval > 0 {
+			rl.retryInterval = interval
+		}
+	}
+}
+
+// WithLockReentrant 允许同一个锁实例重复获取，获取几次就需要释放几次。
+func WithLockReentrant() LockOption {
+	return func(rl *RedisLock) {
+		rl.reentrant = true
+	}
+}
+
+// WithLockWatchdog 持有期间自动续期，适用于耗时不确定的临界区。
+func WithLockWatchdog() LockOption {
+	return func(rl *RedisLock) {
+		rl.watchdog = true
+	}
+}
+
+// Key 返回锁的键。
+func (rl *RedisLock) Key() string {
+	return rl.key
+}
+
+// SetExpire 设置锁的过期秒数，对下一次获取生效。
+func (rl *RedisLock) SetExpire(seconds int) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	if seconds > 0 {
+		rl.seconds = seconds
+	}
+}
+
+// TryAcquire 尝试获取锁，不等待。
+func (rl *RedisLock) TryAcquire() (bool, error) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	if rl.holds > 0 {
+		if !rl.reentrant {
+			return false, nil
+		}
+
+		// 重入时顺便续期，同时确认锁仍归自己所有
+		ok, err := rl.renew()
+		if err != nil {
+			return false, err
+		} else if !ok {
+			// 锁已过期或被他人持有，重置持有状态，以便之后重新获取
+			rl.lost()
+			return false, nil
+		}
+
+		rl.holds++
+		return true, nil
+	}
+
+	resp, err := rl.store.Eval(lockScript, []string{rl.key}, rl.id, rl.expireMillis())
+	if err == red.Nil {
+		return false, nil
+	} else if err != nil {
+		logx.Errorf("获取锁 %s 出错：%s", rl.key, err.Error())
+		return false, err
+	} else if resp == nil {
+		return false, nil
+	}
+
+	reply, ok := resp.(string)
+	if !ok || reply != "OK" {
+		logx.Errorf("获取锁 %s 的返回值未知：%v", rl.key, resp)
+		return false, nil
+	}
+
+	rl.holds = 1
+	if rl.watchdog {
+		rl.startWatchdog()
+	}
+
+	return true, nil
+}
+
+// Acquire 阻塞获取锁，直到成功、出错或 ctx 结束。
+func (rl *RedisLock) Acquire(ctx context.Context) error {
+	for {
+		ok, err := rl.TryAcquire()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		// 加入抖动，避免多个等待者同时重试
+		interval := rl.retryInterval + time.Duration(rand.Int63n(int64(rl.retryInterval)))
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if ctx.Err() == context.DeadlineExceeded {
+				return ErrLockAcquireTimeout
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Release 释放锁。可重入锁仅在最后一次释放时才真正删除。
+func (rl *RedisLock) Release() (bool, error) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	if rl.holds == 0 {
+		return false, ErrLockNotHeld
+	}
+
+	rl.holds--
+	if rl.holds > 0 {
+		return true, nil
+	}
+
+	rl.stopWatchdog()
+	resp, err := rl.store.Eval(delLockScript, []string{rl.key}, rl.id)
+	if err != nil {
+		return false, err
+	}
+
+	reply, ok := resp.(int64)
+	if !ok {
+		return false, nil
+	}
+
+	return reply == 1, nil
+}
+
+// Renew 手动续期，返回锁是否仍归当前实例所有。
+func (rl *RedisLock) Renew() (bool, error) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	if rl.holds == 0 {
+		return false, ErrLockNotHeld
+	}
+
+	ok, err := rl.renew()
+	if err == nil && !ok {
+		rl.lost()
+	}
+
+	return ok, err
+}
+
+func (rl *RedisLock) renew() (bool, error) {
+	resp, err := rl.store.Eval(renewLockScript, []string{rl.key}, rl.id, rl.expireMillis())
+	if err != nil {
+		return false, err
+	}
+
+	reply, ok := resp.(int64)
+	if !ok {
+		return false, nil
+	}
+
+	return reply == 1, nil
+}
+
+// lost 锁已过期或被他人持有时，重置持有状态并停止续期
+func (rl *RedisLock) lost() {
+	rl.holds = 0
+	rl.stopWatchdog()
+}
+
+func (rl *RedisLock) expireMillis() string {
+	// 多给一点余量，避免刚到期就被他人抢占
+	return strconv.Itoa(rl.seconds*int(time.Second/time.Millisecond) + tolerance)
+}
+
+func (rl *RedisLock) startWatchdog() {
+	stop := make(chan struct{})
+	rl.stopDog = stop
+	interval := time.Duration(rl.seconds) * time.Second / 3
+
+	threading.GoSafe(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				rl.lock.Lock()
+				if rl.stopDog != stop {
+					rl.lock.Unlock()
+					return
+				}
+				ok, err := rl.renew()
+				if err != nil {
+					logx.Errorf("续期锁 %s 出错：%s", rl.key, err.Error())
+				} else if !ok {
+					// 锁已过期或被他人持有，停止续期
+					logx.Errorf("锁 %s 已丢失，停止续期", rl.key)
+					rl.lost()
+					rl.lock.Unlock()
+					return
+				}
+				rl.lock.Unlock()
+			}
+		}
+	})
+}
+
+func (rl *RedisLock) stopWatchdog() {
+	if rl.stopDog != nil {
+		close(rl.stopDog)
+		rl.stopDog = nil
+	}
+}
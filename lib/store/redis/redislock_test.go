@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisLock(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := "lock:test"
+		first := NewRedisLock(client, key, WithLockExpire(5))
+		firstAcquire, err := first.TryAcquire()
+		assert.Nil(t, err)
+		assert.True(t, firstAcquire)
+
+		second := NewRedisLock(client, key)
+		secondAcquire, err := second.TryAcquire()
+		assert.Nil(t, err)
+		assert.False(t, secondAcquire)
+
+		// 非持有者释放不会删除他人的锁
+		_, err = second.Release()
+		assert.Equal(t, ErrLockNotHeld, err)
+
+		release, err := first.Release()
+		assert.Nil(t, err)
+		assert.True(t, release)
+
+		secondAcquire, err = second.TryAcquire()
+		assert.Nil(t, err)
+		assert.True(t, secondAcquire)
+		release, err = second.Release()
+		assert.Nil(t, err)
+		assert.True(t, release)
+	})
+}
+
+func TestRedisLock_NotReentrant(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		rl := NewRedisLock(client, "lock:plain")
+		ok, err := rl.TryAcquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		ok, err = rl.TryAcquire()
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		ok, err = rl.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestRedisLock_Reentrant(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := "lock:reentrant"
+		rl := NewRedisLock(client, key, WithLockReentrant())
+		for i := 0; i < 3; i++ {
+			ok, err := rl.TryAcquire()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+		}
+
+		for i := 0; i < 2; i++ {
+			ok, err := rl.Release()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			exists, err := client.Exists(key)
+			assert.Nil(t, err)
+			assert.True(t, exists)
+		}
+
+		ok, err := rl.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		exists, err := client.Exists(key)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestRedisLock_Acquire(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := "lock:acquire"
+		first := NewRedisLock(client, key)
+		assert.Nil(t, first.Acquire(context.Background()))
+
+		second := NewRedisLock(client, key, WithLockRetryInterval(10*time.Millisecond))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, ErrLockAcquireTimeout, second.Acquire(ctx))
+
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			_, _ = first.Release()
+		}()
+		ctx2, cancel2 := context.WithTimeout(context.Background(), time.Second)
+		defer cancel2()
+		assert.Nil(t, second.Acquire(ctx2))
+		ok, err := second.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestRedisLock_Renew(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := "lock:renew"
+		rl := NewRedisLock(client, key, WithLockExpire(1), WithLockWatchdog())
+		_, err := rl.Renew()
+		assert.Equal(t, ErrLockNotHeld, err)
+
+		ok, err := rl.TryAcquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		ok, err = rl.Renew()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ttl, err := client.TTL(key)
+		assert.Nil(t, err)
+		assert.True(t, ttl > 0)
+
+		ok, err = rl.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestRedisLock_ReentrantLost(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := "lock:reentrant:lost"
+		rl := NewRedisLock(client, key, WithLockReentrant())
+		ok, err := rl.TryAcquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		// 锁已过期，重入失败后可以重新获取
+		_, err = client.Del(key)
+		assert.Nil(t, err)
+		ok, err = rl.TryAcquire()
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		ok, err = rl.TryAcquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = rl.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestRedisLock_Watchdog(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	key := "lock:watchdog"
+	client := NewRedis(s.Addr(), StandaloneMode)
+	rl := NewRedisLock(client, key, WithLockExpire(1), WithLockWatchdog())
+	ok, err := rl.TryAcquire()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// 持有时间超过过期时间，看门狗每 1/3 过期时间续期一次
+	for i := 0; i < 3; i++ {
+		time.Sleep(400 * time.Millisecond)
+		s.FastForward(time.Second)
+		assert.True(t, s.Exists(key))
+	}
+
+	ok, err = rl.Release()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.False(t, s.Exists(key))
+
+	rl.lock.Lock()
+	assert.Nil(t, rl.stopDog)
+	rl.lock.Unlock()
+}
@@ -0,0 +1,302 @@
+package redis
+
+import (
+	"strings"
+
+	red "github.com/go-redis/redis"
+)
+
+// 消费组已存在时 redis 返回的错误标识
+const busyGroupPrefix = "BUSYGROUP"
+
+type (
+	// XMessage 流中的一条消息
+	XMessage = red.XMessage
+	// XStream 某个流中读取到的一批消息
+	XStream = red.XStream
+	// XAddArgs XAdd 的参数
+	XAddArgs = red.XAddArgs
+	// XReadArgs XRead 的参数
+	XReadArgs = red.XReadArgs
+	// XReadGroupArgs XReadGroup 的参数
+	XReadGroupArgs = red.XReadGroupArgs
+	// XPending 消费组待确认消息的汇总信息
+	XPending = red.XPending
+	// XPendingExt 待确认消息的明细
+	XPendingExt = red.XPendingExt
+	// XPendingExtArgs XPendingExt 的参数
+	XPendingExtArgs = red.XPendingExtArgs
+	// XClaimArgs XClaim 的参数
+	XClaimArgs = red.XClaimArgs
+)
+
+// XAdd 向流中追加一条消息，返回消息ID。
+func (r *Redis) XAdd(args *XAddArgs) (id string, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		id, err = client.XAdd(args).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XLen 返回流中的消息数。
+func (r *Redis) XLen(stream string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XLen(stream).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XDel 删除流中指定ID的消息，返回删除的条数。
+func (r *Redis) XDel(stream string, ids ...string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XDel(stream, ids...).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XRange 返回流中ID在 [start, stop] 区间的消息，- 和 + 分别表示最小和最大ID。
+func (r *Redis) XRange(stream, start, stop string) (val []XMessage, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XRange(stream, start, stop).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XRangeN 返回流中ID在 [start, stop] 区间的最多 count 条消息。
+func (r *Redis) XRangeN(stream, start, stop string, count int64) (val []XMessage, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XRangeN(stream, start, stop, count).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XRead 从一个或多个流中读取消息，阻塞读取超时返回 Nil。
+func (r *Redis) XRead(args *XReadArgs) (val []XStream, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XRead(args).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XGroupCreate 创建消费组，消费组已存在时不报错。
+// start 为 $ 表示只消费新消息，为 0 表示从头消费。
+func (r *Redis) XGroupCreate(stream, group, start string) error {
+	return r.xGroupCreate(stream, group, start, false)
+}
+
+// XGroupCreateMkStream 创建消费组，流不存在时自动创建，消费组已存在时不报错。
+func (r *Redis) XGroupCreateMkStream(stream, group, start string) error {
+	return r.xGroupCreate(stream, group, start, true)
+}
+
+// XGroupDestroy 删除消费组。
+func (r *Redis) XGroupDestroy(stream, group string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XGroupDestroy(stream, group).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XGroupDelConsumer 从消费组中删除消费者，返回该消费者的待确认消息数。
+func (r *Redis) XGroupDelConsumer(stream, group, consumer string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XGroupDelConsumer(stream, group, consumer).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XReadGroup 以消费组中某个消费者的身份读取消息，阻塞读取超时返回 Nil。
+func (r *Redis) XReadGroup(args *XReadGroupArgs) (val []XStream, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XReadGroup(args).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XAck 确认消息已被消费，返回确认成功的条数。
+func (r *Redis) XAck(stream, group string, ids ...string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XAck(stream, group, ids...).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XPending 返回消费组待确认消息的汇总信息。
+func (r *Redis) XPending(stream, group string) (val *XPending, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XPending(stream, group).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XPendingExt 返回消费组待确认消息的明细，包括空闲时长和投递次数。
+func (r *Redis) XPendingExt(args *XPendingExtArgs) (val []XPendingExt, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XPendingExt(args).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XClaim 将空闲超过 MinIdle 的待确认消息转移给指定消费者。
+func (r *Redis) XClaim(args *XClaimArgs) (val []XMessage, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XClaim(args).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XClaimJustID 同 XClaim，但只返回消息ID。
+func (r *Redis) XClaimJustID(args *XClaimArgs) (val []string, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XClaimJustID(args).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XTrim 将流裁剪到最多 maxLen 条消息，返回删除的条数。
+func (r *Redis) XTrim(stream string, maxLen int64) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XTrim(stream, maxLen).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XTrimApprox 将流近似裁剪到 maxLen 条消息，性能优于 XTrim。
+func (r *Redis) XTrimApprox(stream string, maxLen int64) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = client.XTrimApprox(stream, maxLen).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+func (r *Redis) xGroupCreate(stream, group, start string, mkStream bool) error {
+	return r.brk.DoWithAcceptable(func() error {
+		client, err := getClient(r)
+		if err != nil {
+			return err
+		}
+
+		if mkStream {
+			err = client.XGroupCreateMkStream(stream, group, start).Err()
+		} else {
+			err = client.XGroupCreate(stream, group, start).Err()
+		}
+		if err != nil && strings.Contains(err.Error(), busyGroupPrefix) {
+			return nil
+		}
+
+		return err
+	}, acceptable)
+}
@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedis_XAdd(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		_, err := NewRedis(client.Addr, "").XAdd(&XAddArgs{Stream: "s"})
+		assert.NotNil(t, err)
+
+		id, err := client.XAdd(&XAddArgs{
+			Stream: "s",
+			Values: map[string]interface{}{"name": "kevin"},
+		})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, id)
+		_, err = client.XAdd(&XAddArgs{
+			Stream: "s",
+			Values: map[string]interface{}{"name": "bob"},
+		})
+		assert.Nil(t, err)
+
+		n, err := client.XLen("s")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), n)
+
+		msgs, err := client.XRange("s", "-", "+")
+		assert.Nil(t, err)
+		assert.Len(t, msgs, 2)
+		assert.Equal(t, id, msgs[0].ID)
+		assert.Equal(t, "kevin", msgs[0].Values["name"])
+
+		msgs, err = client.XRangeN("s", "-", "+", 1)
+		assert.Nil(t, err)
+		assert.Len(t, msgs, 1)
+	})
+}
+
+func TestRedis_XReadGroup(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		assert.NotNil(t, client.XGroupCreate("s", "g", "0"))
+		assert.Nil(t, client.XGroupCreateMkStream("s", "g", "0"))
+		// 消费组已存在时不报错
+		assert.Nil(t, client.XGroupCreate("s", "g", "0"))
+
+		id, err := client.XAdd(&XAddArgs{
+			Stream: "s",
+			Values: map[string]interface{}{"k": "v"},
+		})
+		assert.Nil(t, err)
+
+		streams, err := client.XReadGroup(&XReadGroupArgs{
+			Group:    "g",
+			Consumer: "c",
+			Streams:  []string{"s", ">"},
+			Count:    10,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, streams, 1)
+		assert.Equal(t, "s", streams[0].Stream)
+		assert.Len(t, streams[0].Messages, 1)
+		assert.Equal(t, id, streams[0].Messages[0].ID)
+
+		n, err := client.XAck("s", "g", id)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		streams, err = client.XRead(&XReadArgs{
+			Streams: []string{"s", "0"},
+			Count:   10,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, streams, 1)
+	})
+}
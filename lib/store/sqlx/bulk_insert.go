@@ -36,6 +36,9 @@ type (
 
 		// 定时调度器
 		dispatcher *executors.PeriodicalExecutor
+
+		// 值格式化方法，按数据库方言生成字面量
+		format func(query string, args ...interface{}) (string, error)
 	}
 
 	// 批量插入语句结构
@@ -65,44 +68,41 @@ type (
 
 // NewBulkInserter 新建批量插入器
 func NewBulkInserter(c Conn, stmt string) (*BulkInserter, error) {
-	insertStmt, err := parseBulkInsertStmt(stmt)
-	if err != nil {
-		return nil, err
-	}
-
-	manager := &insertManager{
-		conn: c,
-		stmt: insertStmt,
-	}
-
-	return &BulkInserter{
-		stmt:       insertStmt,
-		manager:    manager,
-		dispatcher: executors.NewPeriodicalExecutor(flushInterval, manager),
-	}, nil
+	return newBulkInserter(c, stmt)
 }
 
 // NewBulkInserterWithTx 新建批量插入器（带有事务）
 func NewBulkInserterWithTx(c TxSession, stmt string) (*BulkInserter, error) {
+	return newBulkInserter(c, stmt)
+}
+
+func newBulkInserter(s Session, stmt string) (*BulkInserter, error) {
 	insertStmt, err := parseBulkInsertStmt(stmt)
 	if err != nil {
 		return nil, err
 	}
 
 	manager := &insertManager{
-		conn: c,
+		conn: s,
 		stmt: insertStmt,
 	}
 
+	// PostgreSQL 的布尔值和字符串转义规则与 MySQL 不同
+	formatter := format
+	if isPostgres(s) {
+		formatter = formatPostgres
+	}
+
 	return &BulkInserter{
 		stmt:       insertStmt,
 		manager:    manager,
 		dispatcher: executors.NewPeriodicalExecutor(flushInterval, manager),
+		format:     formatter,
 	}, nil
 }
 
 func (bi *BulkInserter) Insert(args ...interface{}) error {
-	value, err := format(bi.stmt.valueFormat, args...)
+	value, err := bi.format(bi.stmt.valueFormat, args...)
 	if err != nil {
 		return err
 	}
@@ -189,6 +189,8 @@ func parseBulkInsertStmt(stmt string) (bulkStmt, error) {
 	// insert into users values
 	// (1, "张三")
 	// (2, "李四")
+	//
+	// PostgreSQL 也可写作 insert into users(id, name) values ($1, $2)
 
 	lowerStmt := strings.ToLower(stmt)
 	valuesPos := strings.Index(lowerStmt, valuesTag)
@@ -225,8 +227,9 @@ func parseBulkInsertStmt(stmt string) (bulkStmt, error) {
 		right = strings.IndexByte(lowerStmt[valuesPos+left:], ')')
 		if right > 0 {
 			values := lowerStmt[valuesPos+left : valuesPos+left+right]
-			for _, x := range values {
-				if x == '?' {
+			for i := 0; i < len(values); i++ {
+				if values[i] == '?' ||
+					values[i] == '$' && i+1 < len(values) && values[i+1] >= '0' && values[i+1] <= '9' {
 					numArgs++
 				}
 			}
@@ -1,7 +1,9 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
+	"reflect"
 	"time"
 
 	"git.zc0901.com/go/god/lib/store/cache"
@@ -26,9 +28,15 @@ type (
 	ExecFn  func(conn Conn) (sql.Result, error)     // 常规的写库函数
 	QueryFn func(conn Conn, dest interface{}) error // 常规的读库函数
 
+	ExecCtxFn  func(ctx context.Context, conn Conn) (sql.Result, error)     // 带上下文的写库函数
+	QueryCtxFn func(ctx context.Context, conn Conn, dest interface{}) error // 带上下文的读库函数
+
 	GetKeyOfPKFn   func(pk interface{}) string                                   // 取主键的缓存键
 	IndexQueryFn   func(conn Conn, dest interface{}) (pk interface{}, err error) // 按索引查行结果
 	PrimaryQueryFn func(conn Conn, dest, pk interface{}) error                   // 按主键查行结果
+
+	IndexQueryCtxFn   func(ctx context.Context, conn Conn, dest interface{}) (pk interface{}, err error) // 带上下文按索引查行结果
+	PrimaryQueryCtxFn func(ctx context.Context, conn Conn, dest, pk interface{}) error                   // 带上下文按主键查行结果
 )
 
 func NewCachedConn(conn Conn, rds *redis.Redis, opts ...cache.Option) CachedConn {
@@ -79,7 +87,14 @@ func (cc CachedConn) GetBits(key string, offset []int64) (map[int64]bool, error)
 
 // Exec 执行增、删、改，并清空 keys 对应的缓存
 func (cc CachedConn) Exec(exec ExecFn, keys ...string) (sql.Result, error) {
-	result, err := exec(cc.conn)
+	return cc.ExecCtx(context.Background(), func(_ context.Context, conn Conn) (sql.Result, error) {
+		return exec(conn)
+	}, keys...)
+}
+
+// ExecCtx 带上下文执行增、删、改，并清空 keys 对应的缓存
+func (cc CachedConn) ExecCtx(ctx context.Context, exec ExecCtxFn, keys ...string) (sql.Result, error) {
+	result, err := exec(ctx, cc.conn)
 	if err != nil {
 		return nil, err
 	}
@@ -93,27 +108,68 @@ func (cc CachedConn) Exec(exec ExecFn, keys ...string) (sql.Result, error) {
 
 // ExecNoCache 无缓存执行增、删、改
 func (cc CachedConn) ExecNoCache(query string, args ...interface{}) (sql.Result, error) {
-	return cc.conn.Exec(query, args...)
+	return cc.ExecNoCacheCtx(context.Background(), query, args...)
+}
+
+// ExecNoCacheCtx 带上下文无缓存执行增、删、改
+func (cc CachedConn) ExecNoCacheCtx(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return cc.conn.ExecCtx(ctx, query, args...)
 }
 
 // Query 先按 key 从缓存拿，拿不到则查库、写缓存并返回新值
 func (cc CachedConn) Query(dest interface{}, key string, query QueryFn) error {
-	return cc.cache.Take(dest, key, func(dbValue interface{}) error {
-		return query(cc.conn, dbValue)
+	return cc.cache.Take(dest, key, func(v interface{}) error {
+		return query(cc.conn, v)
+	})
+}
+
+// QueryCtx 带上下文查询，先按 key 从缓存拿，拿不到则查库、写缓存并返回新值。
+// 缓存启用提前刷新时 query 还会在后台执行，此时只能写入传入的 dest，并使用传入的上下文，见 cache.IsRefresh。
+func (cc CachedConn) QueryCtx(ctx context.Context, dest interface{}, key string, query QueryCtxFn) error {
+	return cc.cache.TakeCtx(ctx, dest, key, func(ctx context.Context, v interface{}) error {
+		return query(ctx, cc.conn, v)
 	})
 }
 
 // QueryNoCache 无缓存查询，直接读库
 func (cc CachedConn) QueryNoCache(dest interface{}, query string, args ...interface{}) error {
-	return cc.conn.Query(dest, query, args...)
+	return cc.QueryNoCacheCtx(context.Background(), dest, query, args...)
+}
+
+// QueryNoCacheCtx 带上下文无缓存查询，直接读库
+func (cc CachedConn) QueryNoCacheCtx(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return cc.conn.QueryCtx(ctx, dest, query, args...)
 }
 
 func (cc CachedConn) Transact(fn func(tx TxSession) error) error {
 	return cc.conn.Transact(fn)
 }
 
+// TransactCtx 带上下文执行事务
+func (cc CachedConn) TransactCtx(ctx context.Context, fn TransactCtxFn) error {
+	return cc.conn.TransactCtx(ctx, fn)
+}
+
 func (cc CachedConn) QueryIndex(dest interface{}, indexKey string, getKeyOfPK GetKeyOfPKFn,
 	indexQuery IndexQueryFn, primaryQuery PrimaryQueryFn) error {
+	// 不带上下文的查询函数可能写入调用方的变量，不在后台刷新缓存
+	return cc.queryIndex(context.Background(), false, dest, indexKey, getKeyOfPK,
+		func(_ context.Context, conn Conn, v interface{}) (interface{}, error) {
+			return indexQuery(conn, v)
+		}, func(_ context.Context, conn Conn, v, pk interface{}) error {
+			return primaryQuery(conn, v, pk)
+		})
+}
+
+// QueryIndexCtx 带上下文按索引查询，同 QueryIndex。
+// 缓存启用提前刷新时查询函数还会在后台执行，此时只能写入传入的 dest，并使用传入的上下文，见 cache.IsRefresh。
+func (cc CachedConn) QueryIndexCtx(ctx context.Context, dest interface{}, indexKey string, getKeyOfPK GetKeyOfPKFn,
+	indexQuery IndexQueryCtxFn, primaryQuery PrimaryQueryCtxFn) error {
+	return cc.queryIndex(ctx, true, dest, indexKey, getKeyOfPK, indexQuery, primaryQuery)
+}
+
+func (cc CachedConn) queryIndex(ctx context.Context, refresh bool, dest interface{}, indexKey string,
+	getKeyOfPK GetKeyOfPKFn, indexQuery IndexQueryCtxFn, primaryQuery PrimaryQueryCtxFn) error {
 	var id interface{}
 	var found bool
 
@@ -121,14 +177,25 @@ func (cc CachedConn) QueryIndex(dest interface{}, indexKey string, getKeyOfPK Ge
 	getKeyOfPK = toInt64Key(getKeyOfPK)
 
 	// 缓存中，索引键找不到主键需要查库（此时做索引查行记录）
-	if err := cc.cache.TakeEx(&id, indexKey, func(newVal interface{}, expires time.Duration) (err error) {
-		id, err = indexQuery(cc.conn, dest)
+	if err := cc.takeEx(ctx, refresh, &id, indexKey, func(ctx context.Context, newVal interface{},
+		expires time.Duration) error {
+		// 后台刷新时行记录查到新建的值中，不能写入调用方的 dest
+		refreshing := cache.IsRefresh(ctx)
+		row := dest
+		if refreshing {
+			row = reflect.New(reflect.TypeOf(dest).Elem()).Interface()
+		}
+
+		pk, err := indexQuery(ctx, cc.conn, row)
 		if err != nil {
-			return
+			return err
 		}
 
-		found = true
-		return cc.cache.SetEx(getKeyOfPK(id), dest, expires+safeGapBetweenIndexAndPrimary)
+		*newVal.(*interface{}) = pk
+		if !refreshing {
+			found = true
+		}
+		return cc.cache.SetEx(getKeyOfPK(pk), row, expires+safeGapBetweenIndexAndPrimary)
 	}); err != nil {
 		return err
 	}
@@ -139,8 +206,32 @@ func (cc CachedConn) QueryIndex(dest interface{}, indexKey string, getKeyOfPK Ge
 	}
 
 	// 通过索引建能直接查到主键，则直接做主键查询
-	return cc.cache.Take(dest, getKeyOfPK(id), func(interface{}) error {
-		return primaryQuery(cc.conn, dest, id)
+	return cc.take(ctx, refresh, dest, getKeyOfPK(id), func(ctx context.Context, v interface{}) error {
+		return primaryQuery(ctx, cc.conn, v, id)
+	})
+}
+
+// take 读取缓存，refresh 为 false 时不在后台刷新
+func (cc CachedConn) take(ctx context.Context, refresh bool, dest interface{}, key string,
+	query func(ctx context.Context, v interface{}) error) error {
+	if refresh {
+		return cc.cache.TakeCtx(ctx, dest, key, query)
+	}
+
+	return cc.cache.Take(dest, key, func(v interface{}) error {
+		return query(ctx, v)
+	})
+}
+
+// takeEx 读取缓存，refresh 为 false 时不在后台刷新
+func (cc CachedConn) takeEx(ctx context.Context, refresh bool, dest interface{}, key string,
+	query func(ctx context.Context, v interface{}, expires time.Duration) error) error {
+	if refresh {
+		return cc.cache.TakeExCtx(ctx, dest, key, query)
+	}
+
+	return cc.cache.TakeEx(dest, key, func(v interface{}, expires time.Duration) error {
+		return query(ctx, v, expires)
 	})
 }
 
@@ -1,6 +1,7 @@
 package sqlx
 
 import (
+	"context"
 	"fmt"
 	"sync/atomic"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"git.zc0901.com/go/god/lib/logx"
 	"git.zc0901.com/go/god/lib/store/cache"
 	"git.zc0901.com/go/god/lib/store/redis"
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -174,6 +176,58 @@ func TestCachedConn_QueryIndex_NoCache(t *testing.T) {
 	})
 }
 
+func TestCachedConn_QueryIndexRefresh(t *testing.T) {
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	// 系数足够大时每次读到缓存都会在后台提前刷新
+	c := NewCachedConn(nil, r, cache.WithExpires(time.Minute), cache.WithEarlyRefresh(1e9))
+	var queries int32
+	indexQuery := func(ctx context.Context, conn Conn, v interface{}) (interface{}, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// 留出查库耗时，以便计算提前刷新的概率
+		time.Sleep(2 * time.Millisecond)
+		*v.(*Profile2) = Profile2{
+			ID:       1,
+			Nickname: fmt.Sprintf("v%d", atomic.AddInt32(&queries, 1)),
+		}
+		return int64(1), nil
+	}
+	primaryQuery := func(ctx context.Context, conn Conn, v, pk interface{}) error {
+		t.Fatal("不应按主键查库")
+		return nil
+	}
+	getKeyOfPK := func(pk interface{}) string {
+		return fmt.Sprintf("%s%v", cacheUserIdPrefix, pk)
+	}
+	indexKey := cacheUserNicknamePrefix + "refresh"
+
+	var profile Profile2
+	assert.Nil(t, c.QueryIndexCtx(context.Background(), &profile, indexKey, getKeyOfPK, indexQuery, primaryQuery))
+	assert.Equal(t, "v1", profile.Nickname)
+
+	// 请求结束后上下文已取消，后台刷新仍需查库成功，且不能改动调用方的值
+	ctx, cancel := context.WithCancel(context.Background())
+	var cached Profile2
+	assert.Nil(t, c.QueryIndexCtx(ctx, &cached, indexKey, getKeyOfPK, indexQuery, primaryQuery))
+	cancel()
+	assert.Equal(t, "v1", cached.Nickname)
+
+	assert.Eventually(t, func() bool {
+		var row Profile2
+		return c.GetCache(getKeyOfPK(1), &row) == nil && row.Nickname == "v2"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "v1", cached.Nickname)
+
+	var pk int64
+	assert.Nil(t, c.GetCache(indexKey, &pk))
+	assert.Equal(t, int64(1), pk)
+}
+
 func TestCachedConn_QueryNoCache(t *testing.T) {
 	type AreaInfo struct {
 		Id         uint8  `db:"id"`          // 区域字典表id
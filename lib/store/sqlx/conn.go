@@ -1,6 +1,7 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"time"
@@ -25,25 +26,33 @@ var (
 
 type (
 	// Session 该接口表示一个原始数据库连接或事务的会话。
+	// 带 Ctx 后缀的方法会将 ctx 的取消和超时传递给数据库驱动，并在日志中附带链路信息。
 	Session interface {
 		Query(dest interface{}, query string, args ...interface{}) error
+		QueryCtx(ctx context.Context, dest interface{}, query string, args ...interface{}) error
 		Exec(query string, args ...interface{}) (sql.Result, error)
+		ExecCtx(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 		Prepare(query string) (StmtSession, error)
+		PrepareCtx(ctx context.Context, query string) (StmtSession, error)
 	}
 
 	// 提供内部查询和执行的会话接口
 	session interface {
-		Query(query string, args ...interface{}) (*sql.Rows, error)
-		Exec(query string, args ...interface{}) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	}
 
 	// TransactFn 事务内部执行函数，传入事务会话
 	TransactFn func(tx TxSession) error
 
+	// TransactCtxFn 带上下文的事务内部执行函数，传入事务上下文和事务会话
+	TransactCtxFn func(ctx context.Context, tx TxSession) error
+
 	// Conn 提供外部数据库会话和事务的接口
 	Conn interface {
 		Session
 		Transact(fn TransactFn) error
+		TransactCtx(ctx context.Context, fn TransactCtxFn) error
 	}
 
 	// conn 线程安全。提供内部使用的数据库连接，封装查询、执行、事务及断路器支持。
@@ -53,6 +62,7 @@ type (
 		beginTx        beginTxFn       // 可开始事务
 		brk            breaker.Breaker // 断路器，用于后端故障拒绝服务
 		accept         func(reqError error) bool
+		postgres       bool // 是否为 PostgreSQL，是则执行前改写占位符
 	}
 
 	// Option 是一个可选的数据库增强函数
@@ -61,13 +71,17 @@ type (
 
 // NewConn 新建指定数据库驱动和DSN的连接
 func NewConn(driverName, dataSourceName string, opts ...Option) Conn {
-	prefectDSN(&dataSourceName)
+	// 仅 MySQL 需要补全时间解析参数，PostgreSQL、ClickHouse 等不识别这些参数
+	if driverName == mysqlDriverName {
+		prefectDSN(&dataSourceName)
+	}
 
 	c := &conn{
 		driverName:     driverName,
 		dataSourceName: dataSourceName,
 		beginTx:        beginTx,
 		brk:            breaker.NewBreaker(),
+		postgres:       driverName == postgresDriverName,
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -78,6 +92,12 @@ func NewConn(driverName, dataSourceName string, opts ...Option) Conn {
 // Query 如果 dest 字段不写tag的话，系统按顺序配对，此时需要与sql中的查询字段顺序一致
 // 如果 dest 字段写了tag的话，系统按名称配对，此时可以和sql中的查询字段顺序不同
 func (c *conn) Query(dest interface{}, query string, args ...interface{}) error {
+	return c.QueryCtx(context.Background(), dest, query, args...)
+}
+
+// QueryCtx 带上下文查询，同 Query
+func (c *conn) QueryCtx(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	query = c.bind(query)
 	var scanError error
 	return c.brk.DoWithAcceptable(func() error {
 		// 获取数据库连接
@@ -88,7 +108,7 @@ func (c *conn) Query(dest interface{}, query string, args ...interface{}) error
 		}
 
 		// 做数据库查询
-		return doQuery(db, func(rows *sql.Rows) error {
+		return doQuery(ctx, db, func(rows *sql.Rows) error {
 			scanError = scan(dest, rows)
 			return scanError
 		}, query, args...)
@@ -97,7 +117,13 @@ func (c *conn) Query(dest interface{}, query string, args ...interface{}) error
 	})
 }
 
-func (c *conn) Exec(query string, args ...interface{}) (result sql.Result, err error) {
+func (c *conn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.ExecCtx(context.Background(), query, args...)
+}
+
+// ExecCtx 带上下文执行，同 Exec
+func (c *conn) ExecCtx(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	query = c.bind(query)
 	err = c.brk.DoWithAcceptable(func() error {
 		// 获取数据库连接
 		var db *sql.DB
@@ -108,13 +134,19 @@ func (c *conn) Exec(query string, args ...interface{}) (result sql.Result, err e
 		}
 
 		// 做数据库执行
-		result, err = doExec(db, query, args...)
+		result, err = doExec(ctx, db, query, args...)
 		return err
 	}, c.acceptable)
 	return
 }
 
-func (c *conn) Prepare(query string) (stmt StmtSession, err error) {
+func (c *conn) Prepare(query string) (StmtSession, error) {
+	return c.PrepareCtx(context.Background(), query)
+}
+
+// PrepareCtx 带上下文预编译，同 Prepare
+func (c *conn) PrepareCtx(ctx context.Context, query string) (stmt StmtSession, err error) {
+	query = c.bind(query)
 	err = c.brk.DoWithAcceptable(func() error {
 		// 获取数据库连接
 		var db *sql.DB
@@ -125,7 +157,7 @@ func (c *conn) Prepare(query string) (stmt StmtSession, err error) {
 		}
 
 		// 预编译查询语句
-		st, err := db.Prepare(query)
+		st, err := db.PrepareContext(ctx, query)
 		if err != nil {
 			return err
 		}
@@ -143,18 +175,40 @@ func (c *conn) Prepare(query string) (stmt StmtSession, err error) {
 
 // Transact 执行事务，有错自动回滚，无错自动提交。
 func (c *conn) Transact(fn TransactFn) error {
+	return c.TransactCtx(context.Background(), func(_ context.Context, tx TxSession) error {
+		return fn(tx)
+	})
+}
+
+// TransactCtx 带上下文执行事务，ctx 取消时事务自动回滚。
+func (c *conn) TransactCtx(ctx context.Context, fn TransactCtxFn) error {
 	return c.brk.DoWithAcceptable(func() error {
-		return doTx(c, c.beginTx, fn)
+		return doTx(ctx, c, c.beginTx, fn)
 	}, c.acceptable)
 }
 
 func (c *conn) acceptable(reqError error) bool {
+	// 调用方主动取消不代表数据库故障，不计入熔断
 	ok := reqError == nil ||
 		reqError == sql.ErrNoRows ||
-		reqError == sql.ErrTxDone
+		reqError == sql.ErrTxDone ||
+		reqError == context.Canceled
 	if c.accept == nil {
 		return ok
 	} else {
 		return ok || c.accept(reqError)
 	}
 }
+
+// bind 按数据库方言改写占位符
+func (c *conn) bind(query string) string {
+	if c.postgres {
+		return rebind(query)
+	}
+
+	return query
+}
+
+func (c *conn) isPostgres() bool {
+	return c.postgres
+}
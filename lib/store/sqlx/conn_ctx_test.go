@@ -0,0 +1,92 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ctxTestDriver = "sqlx-ctx-test"
+
+func init() {
+	sql.Register(ctxTestDriver, ctxTestDriverImpl{})
+}
+
+func TestConn_Ctx(t *testing.T) {
+	c := NewConn(ctxTestDriver, "ctx-test")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var dest int
+	assert.Equal(t, context.Canceled, c.QueryCtx(ctx, &dest, "select 1"))
+	_, err := c.ExecCtx(ctx, "update t set a = ?", 1)
+	assert.Equal(t, context.Canceled, err)
+	_, err = c.PrepareCtx(ctx, "select 1")
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, context.Canceled, c.TransactCtx(ctx, func(ctx context.Context, tx TxSession) error {
+		return nil
+	}))
+
+	// 未取消的上下文可正常执行
+	result, err := c.ExecCtx(context.Background(), "update t set a = ?", 1)
+	assert.Nil(t, err)
+	affected, err := result.RowsAffected()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), affected)
+	assert.Nil(t, c.TransactCtx(context.Background(), func(ctx context.Context, tx TxSession) error {
+		_, err := tx.ExecCtx(ctx, "update t set a = ?", 2)
+		return err
+	}))
+}
+
+type (
+	ctxTestDriverImpl struct{}
+	ctxTestConn       struct{}
+	ctxTestStmt       struct{}
+	ctxTestTx         struct{}
+)
+
+func (ctxTestDriverImpl) Open(string) (driver.Conn, error) {
+	return ctxTestConn{}, nil
+}
+
+func (ctxTestConn) Prepare(string) (driver.Stmt, error) {
+	return ctxTestStmt{}, nil
+}
+
+func (ctxTestConn) Close() error {
+	return nil
+}
+
+func (ctxTestConn) Begin() (driver.Tx, error) {
+	return ctxTestTx{}, nil
+}
+
+func (ctxTestStmt) Close() error {
+	return nil
+}
+
+func (ctxTestStmt) NumInput() int {
+	return -1
+}
+
+func (ctxTestStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (ctxTestStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (ctxTestTx) Commit() error {
+	return nil
+}
+
+func (ctxTestTx) Rollback() error {
+	return io.EOF
+}
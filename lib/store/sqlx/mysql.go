@@ -6,13 +6,15 @@ import (
 )
 
 const (
+	mysqlDriverName = "mysql"
+
 	ErrDuplicateEntryCode uint16 = 1062
 )
 
 // NewMySQL 创建 MySQL 数据库实例
 func NewMySQL(dataSourceName string, opts ...Option) Conn {
 	opts = append(opts, withMySQLAcceptable())
-	return NewConn("mysql", dataSourceName, opts...)
+	return NewConn(mysqlDriverName, dataSourceName, opts...)
 }
 
 func withMySQLAcceptable() Option {
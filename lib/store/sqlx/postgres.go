@@ -0,0 +1,246 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.zc0901.com/go/god/lib/mapping"
+	"github.com/lib/pq"
+)
+
+const (
+	postgresDriverName = "postgres"
+
+	// ErrPgUniqueViolationCode PostgreSQL 唯一约束冲突错误码
+	ErrPgUniqueViolationCode = "23505"
+
+	// PostgreSQL 完整性约束错误类别，如唯一键、外键、非空约束冲突
+	pgIntegrityConstraintViolation = "23"
+)
+
+type (
+	// 可识别 PostgreSQL 方言的会话
+	postgresSession interface {
+		isPostgres() bool
+	}
+
+	// pgTxSession PostgreSQL 事务会话，执行前改写占位符
+	pgTxSession struct {
+		TxSession
+	}
+)
+
+// NewPostgres 创建 PostgreSQL 数据库实例。
+// 语句中依然可以使用 ? 占位符，执行前会改写为 $1, $2...，jsonb 的 ? 运算符需写作 ??；
+// 已使用 $n 占位符的语句保持不变，同一语句中不要混用 ? 与 $n 占位符。
+func NewPostgres(dataSourceName string, opts ...Option) Conn {
+	opts = append(opts, withPostgresAcceptable())
+	return NewConn(postgresDriverName, dataSourceName, opts...)
+}
+
+// NewPostgresWithReplicas 新建 PostgreSQL 读写分离连接
+func NewPostgresWithReplicas(primary string, replicas []string, opts ...RwOption) Conn {
+	opts = append([]RwOption{WithConnOptions(withPostgresAcceptable())}, opts...)
+	return NewRwConn(postgresDriverName, primary, replicas, opts...)
+}
+
+func withPostgresAcceptable() Option {
+	return func(c *conn) {
+		c.accept = postgresAcceptable
+	}
+}
+
+func postgresAcceptable(reqError error) bool {
+	if reqError == nil {
+		return true
+	}
+
+	pqError, ok := reqError.(*pq.Error)
+	if !ok {
+		return false
+	}
+
+	// 违反完整性约束属于业务错误，不代表数据库故障
+	return pqError.Code.Class() == pgIntegrityConstraintViolation
+}
+
+// isPostgres 判断会话是否使用 PostgreSQL 方言
+func isPostgres(s Session) bool {
+	ps, ok := s.(postgresSession)
+	return ok && ps.isPostgres()
+}
+
+// rebind 将 ? 占位符改写为 PostgreSQL 的 $n 占位符，
+// 跳过引号、-- 与 /* */ 注释及 $tag$ 美元符引用内的内容。
+// jsonb 的 ?、?|、?& 运算符需写作 ??、??|、??&，改写后还原为单个 ?。
+// 语句中已有 $n 占位符时视为原生 PostgreSQL 语句，整体原样返回，不改写 ? 也不还原 ??。
+func rebind(query string) string {
+	if strings.IndexByte(query, '?') < 0 {
+		return query
+	}
+
+	var b strings.Builder
+	var argIdx int
+	bytes := len(query)
+
+	for i := 0; i < bytes; i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"':
+			// 引号内原样保留，'' 转义会先关闭再打开引号，结果不变
+			end := strings.IndexByte(query[i+1:], ch)
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case ch == '-' && i+1 < bytes && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+1])
+			i += end
+		case ch == '/' && i+1 < bytes && query[i+1] == '*':
+			end := blockCommentEnd(query, i)
+			b.WriteString(query[i:end])
+			i = end - 1
+		case ch == '$' && i+1 < bytes && query[i+1] >= '0' && query[i+1] <= '9':
+			return query
+		case ch == '$':
+			end := dollarQuoteEnd(query, i)
+			b.WriteString(query[i:end])
+			i = end - 1
+		case ch == '?' && i+1 < bytes && query[i+1] == '?':
+			b.WriteByte('?')
+			i++
+		case ch == '?':
+			argIdx++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(argIdx))
+		default:
+			b.WriteByte(ch)
+		}
+	}
+
+	return b.String()
+}
+
+// blockCommentEnd 返回从 start 开始的块注释结束后的位置，PostgreSQL 的块注释可以嵌套
+func blockCommentEnd(query string, start int) int {
+	var depth int
+	for i := start; i+1 < len(query); i++ {
+		switch {
+		case query[i] == '/' && query[i+1] == '*':
+			depth++
+			i++
+		case query[i] == '*' && query[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return len(query)
+}
+
+// dollarQuoteEnd 返回从 start 开始的 $tag$...$tag$ 字符串结束后的位置，不是美元符引用时只跳过 $ 本身
+func dollarQuoteEnd(query string, start int) int {
+	// 标识符中间的 $ 不是美元符引用，如 a$b$
+	if start > 0 && isDollarTagChar(query[start-1], false) {
+		return start + 1
+	}
+
+	i := start + 1
+	for i < len(query) && isDollarTagChar(query[i], i == start+1) {
+		i++
+	}
+	if i >= len(query) || query[i] != '$' {
+		return start + 1
+	}
+
+	tag := query[start : i+1]
+	end := strings.Index(query[i+1:], tag)
+	if end < 0 {
+		return len(query)
+	}
+
+	return i + 1 + end + len(tag)
+}
+
+func isDollarTagChar(ch byte, first bool) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= 0x80 ||
+		!first && ch >= '0' && ch <= '9'
+}
+
+// formatPostgres 按 PostgreSQL 的字面量规则格式化查询字符串和参数
+func formatPostgres(query string, args ...interface{}) (string, error) {
+	return formatWith(writePgValue, query, args...)
+}
+
+func writePgValue(buf *strings.Builder, arg interface{}) {
+	switch v := arg.(type) {
+	case nil:
+		buf.WriteString("NULL")
+	case bool:
+		if v {
+			buf.WriteString("TRUE")
+		} else {
+			buf.WriteString("FALSE")
+		}
+	case string:
+		buf.WriteByte('\'')
+		buf.WriteString(escapePg(v))
+		buf.WriteByte('\'')
+	case []byte:
+		buf.WriteByte('\'')
+		buf.WriteString(escapePg(string(v)))
+		buf.WriteByte('\'')
+	case time.Time:
+		buf.WriteByte('\'')
+		buf.WriteString(v.Format(time.RFC3339Nano))
+		buf.WriteByte('\'')
+	default:
+		buf.WriteString(mapping.Repr(v))
+	}
+}
+
+// escapePg 字符串转义，PostgreSQL 默认开启 standard_conforming_strings，只需双写单引号
+func escapePg(str string) string {
+	return strings.ReplaceAll(str, "'", "''")
+}
+
+func (tx pgTxSession) Query(dest interface{}, query string, args ...interface{}) error {
+	return tx.TxSession.Query(dest, rebind(query), args...)
+}
+
+func (tx pgTxSession) QueryCtx(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return tx.TxSession.QueryCtx(ctx, dest, rebind(query), args...)
+}
+
+func (tx pgTxSession) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.TxSession.Exec(rebind(query), args...)
+}
+
+func (tx pgTxSession) ExecCtx(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.TxSession.ExecCtx(ctx, rebind(query), args...)
+}
+
+func (tx pgTxSession) Prepare(query string) (StmtSession, error) {
+	return tx.TxSession.Prepare(rebind(query))
+}
+
+func (tx pgTxSession) PrepareCtx(ctx context.Context, query string) (StmtSession, error) {
+	return tx.TxSession.PrepareCtx(ctx, rebind(query))
+}
+
+func (tx pgTxSession) isPostgres() bool {
+	return true
+}
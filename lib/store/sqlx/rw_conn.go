@@ -0,0 +1,271 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math/rand"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"git.zc0901.com/go/god/lib/breaker"
+	"git.zc0901.com/go/god/lib/timex"
+)
+
+const (
+	// RoundRobin 轮询选择从库
+	RoundRobin = "round_robin"
+	// P2C 随机选两个从库，取进行中请求较少的一个
+	P2C = "p2c"
+
+	defaultStickiness = time.Second
+)
+
+type (
+	// 强制主库的上下文键
+	primaryKey struct{}
+	// 写后读粘滞会话的上下文键
+	sessionKey struct{}
+
+	// rwConn 读写分离连接：写、事务和预编译走主库，查询走健康的从库。
+	rwConn struct {
+		primary    Conn
+		replicas   []*replica
+		policy     string
+		stickiness time.Duration
+		next       uint64
+	}
+
+	// 写后读粘滞会话
+	stickySession struct {
+		lastWrite int64 // 会话内最后一次写主库的相对时间
+	}
+
+	// 从库，每个从库有独立的断路器
+	replica struct {
+		conn     Conn
+		inflight int64
+	}
+
+	rwOptions struct {
+		policy     string
+		stickiness time.Duration
+		connOpts   []Option
+	}
+
+	// RwOption 自定义读写分离连接的方法
+	RwOption func(opts *rwOptions)
+)
+
+// NewRwConn 新建读写分离连接，replicas 为空时等同于 NewConn。
+// 写后读一致性只在 WithSession 的会话内保证：不带会话的写入不会让后续查询走主库，
+// 写后立即读的场景需使用 WithSession 或 WithPrimary 返回的上下文。
+func NewRwConn(driverName, primary string, replicas []string, opts ...RwOption) Conn {
+	options := rwOptions{
+		policy:     RoundRobin,
+		stickiness: defaultStickiness,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	c := &rwConn{
+		primary:    NewConn(driverName, primary, options.connOpts...),
+		policy:     options.policy,
+		stickiness: options.stickiness,
+	}
+	for _, dsn := range replicas {
+		connOpts := append([]Option{withBreakerName(desensitize(dsn))}, options.connOpts...)
+		c.replicas = append(c.replicas, &replica{
+			conn: NewConn(driverName, dsn, connOpts...),
+		})
+	}
+
+	return c
+}
+
+// NewMySQLWithReplicas 新建 MySQL 读写分离连接
+func NewMySQLWithReplicas(primary string, replicas []string, opts ...RwOption) Conn {
+	opts = append([]RwOption{WithConnOptions(withMySQLAcceptable())}, opts...)
+	return NewRwConn(mysqlDriverName, primary, replicas, opts...)
+}
+
+// WithReplicaPolicy 自定义从库选择策略，可选 RoundRobin 和 P2C。
+func WithReplicaPolicy(policy string) RwOption {
+	return func(opts *rwOptions) {
+		opts.policy = policy
+	}
+}
+
+// WithStickiness 自定义写后读粘滞时长，同一会话内写主库后该时长内的查询也走主库，<=0 表示不粘滞。
+// 粘滞只对 WithSession 返回的上下文生效，Exec、Query 等不带上下文的方法及普通上下文的写入不会粘滞。
+func WithStickiness(stickiness time.Duration) RwOption {
+	return func(opts *rwOptions) {
+		opts.stickiness = stickiness
+	}
+}
+
+// WithConnOptions 为主库和从库连接附加选项
+func WithConnOptions(opts ...Option) RwOption {
+	return func(rwOpts *rwOptions) {
+		rwOpts.connOpts = append(rwOpts.connOpts, opts...)
+	}
+}
+
+// WithPrimary 返回强制查询主库的上下文，用于写后立即读且不能容忍从库延迟的场景。
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// WithSession 返回带写后读粘滞会话的上下文，通常每个请求一个会话。
+// 用该上下文写主库后，粘滞时长内同一会话的查询也走主库，其他会话仍查询从库。
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, new(stickySession))
+}
+
+func withBreakerName(name string) Option {
+	return func(c *conn) {
+		c.brk = breaker.NewBreaker(breaker.WithName(name))
+	}
+}
+
+func (c *rwConn) Query(dest interface{}, query string, args ...interface{}) error {
+	return c.QueryCtx(context.Background(), dest, query, args...)
+}
+
+// QueryCtx 从库查询，从库全部不可用、上下文要求或处于写后粘滞期时查询主库。
+// 未使用 WithSession 时即使刚写过主库也查询从库，可能读到复制延迟前的旧数据。
+func (c *rwConn) QueryCtx(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if c.usePrimary(ctx) {
+		return c.primary.QueryCtx(ctx, dest, query, args...)
+	}
+
+	for _, r := range c.pick() {
+		atomic.AddInt64(&r.inflight, 1)
+		err := r.conn.QueryCtx(ctx, dest, query, args...)
+		atomic.AddInt64(&r.inflight, -1)
+		// 该从库已熔断或连接不可用，换下一个
+		if isConnError(err) {
+			continue
+		}
+
+		return err
+	}
+
+	return c.primary.QueryCtx(ctx, dest, query, args...)
+}
+
+func (c *rwConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.ExecCtx(context.Background(), query, args...)
+}
+
+// ExecCtx 主库执行
+func (c *rwConn) ExecCtx(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer c.markWrite(ctx)
+	return c.primary.ExecCtx(ctx, query, args...)
+}
+
+func (c *rwConn) Prepare(query string) (StmtSession, error) {
+	return c.PrepareCtx(context.Background(), query)
+}
+
+// PrepareCtx 主库预编译，预编译语句可能用于写入
+func (c *rwConn) PrepareCtx(ctx context.Context, query string) (StmtSession, error) {
+	defer c.markWrite(ctx)
+	return c.primary.PrepareCtx(ctx, query)
+}
+
+func (c *rwConn) Transact(fn TransactFn) error {
+	return c.TransactCtx(context.Background(), func(_ context.Context, tx TxSession) error {
+		return fn(tx)
+	})
+}
+
+// TransactCtx 主库执行事务
+func (c *rwConn) TransactCtx(ctx context.Context, fn TransactCtxFn) error {
+	defer c.markWrite(ctx)
+	return c.primary.TransactCtx(ctx, fn)
+}
+
+func (c *rwConn) isPostgres() bool {
+	return isPostgres(c.primary)
+}
+
+func (c *rwConn) markWrite(ctx context.Context) {
+	if c.stickiness <= 0 {
+		return
+	}
+
+	if s, ok := ctx.Value(sessionKey{}).(*stickySession); ok {
+		atomic.StoreInt64(&s.lastWrite, int64(timex.Now()))
+	}
+}
+
+func (c *rwConn) usePrimary(ctx context.Context) bool {
+	if len(c.replicas) == 0 {
+		return true
+	}
+
+	if force, ok := ctx.Value(primaryKey{}).(bool); ok && force {
+		return true
+	}
+
+	if c.stickiness <= 0 {
+		return false
+	}
+
+	s, ok := ctx.Value(sessionKey{}).(*stickySession)
+	if !ok {
+		return false
+	}
+
+	lastWrite := atomic.LoadInt64(&s.lastWrite)
+	return lastWrite > 0 && timex.Since(time.Duration(lastWrite)) < c.stickiness
+}
+
+// isConnError 判断是否为从库熔断或连接级错误，此时可换其他从库查询
+func isConnError(err error) bool {
+	if err == breaker.ErrServiceUnavailable || err == driver.ErrBadConn {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// 按策略返回从库的尝试顺序
+func (c *rwConn) pick() []*replica {
+	n := len(c.replicas)
+	ordered := make([]*replica, 0, n)
+
+	switch c.policy {
+	case P2C:
+		if n == 1 {
+			return c.replicas
+		}
+
+		a := rand.Intn(n)
+		b := rand.Intn(n - 1)
+		if b >= a {
+			b++
+		}
+		if atomic.LoadInt64(&c.replicas[b].inflight) < atomic.LoadInt64(&c.replicas[a].inflight) {
+			a, b = b, a
+		}
+		ordered = append(ordered, c.replicas[a], c.replicas[b])
+		for i, r := range c.replicas {
+			if i != a && i != b {
+				ordered = append(ordered, r)
+			}
+		}
+	default:
+		start := int(atomic.AddUint64(&c.next, 1) % uint64(n))
+		for i := 0; i < n; i++ {
+			ordered = append(ordered, c.replicas[(start+i)%n])
+		}
+	}
+
+	return ordered
+}
@@ -0,0 +1,153 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const rwTestDriver = "sqlx-rw-test"
+
+func init() {
+	sql.Register(rwTestDriver, rwTestDriverImpl{})
+}
+
+func TestRwConn_Query(t *testing.T) {
+	c := NewRwConn(rwTestDriver, "primary", []string{"replica1", "replica2"},
+		WithStickiness(100*time.Millisecond))
+
+	served := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		var dsn string
+		assert.Nil(t, c.Query(&dsn, "select dsn"))
+		served[dsn]++
+	}
+	assert.Equal(t, map[string]int{"replica1": 2, "replica2": 2}, served)
+
+	var dsn string
+	assert.Nil(t, c.QueryCtx(WithPrimary(context.Background()), &dsn, "select dsn"))
+	assert.Equal(t, "primary", dsn)
+
+	// 同一会话写后粘滞期内读主库
+	ctx := WithSession(context.Background())
+	_, err := c.ExecCtx(ctx, "update t set a = 1")
+	assert.Nil(t, err)
+	assert.Nil(t, c.QueryCtx(ctx, &dsn, "select dsn"))
+	assert.Equal(t, "primary", dsn)
+
+	// 其他会话和没有会话的查询不受影响
+	assert.Nil(t, c.QueryCtx(WithSession(context.Background()), &dsn, "select dsn"))
+	assert.NotEqual(t, "primary", dsn)
+	assert.Nil(t, c.Query(&dsn, "select dsn"))
+	assert.NotEqual(t, "primary", dsn)
+
+	time.Sleep(150 * time.Millisecond)
+	assert.Nil(t, c.QueryCtx(ctx, &dsn, "select dsn"))
+	assert.NotEqual(t, "primary", dsn)
+}
+
+func TestRwConn_ReplicaDown(t *testing.T) {
+	c := NewRwConn(rwTestDriver, "primary", []string{"down", "replica1"})
+	for i := 0; i < 4; i++ {
+		var dsn string
+		assert.Nil(t, c.Query(&dsn, "select dsn"))
+		assert.Equal(t, "replica1", dsn)
+	}
+
+	c = NewRwConn(rwTestDriver, "primary", []string{"down"})
+	var dsn string
+	assert.Nil(t, c.Query(&dsn, "select dsn"))
+	assert.Equal(t, "primary", dsn)
+}
+
+func TestRwConn_P2C(t *testing.T) {
+	c := NewRwConn(rwTestDriver, "primary", []string{"replica1", "replica2", "replica3"},
+		WithReplicaPolicy(P2C))
+	for i := 0; i < 10; i++ {
+		var dsn string
+		assert.Nil(t, c.Query(&dsn, "select dsn"))
+		assert.NotEqual(t, "primary", dsn)
+	}
+}
+
+func TestRwConn_NoReplicas(t *testing.T) {
+	c := NewRwConn(rwTestDriver, "primary", nil)
+	var dsn string
+	assert.Nil(t, c.Query(&dsn, "select dsn"))
+	assert.Equal(t, "primary", dsn)
+}
+
+type (
+	rwTestDriverImpl struct{}
+	rwTestConn       struct{ dsn string }
+	rwTestStmt       struct{ dsn string }
+	rwTestRows       struct {
+		dsn  string
+		done bool
+	}
+)
+
+func (rwTestDriverImpl) Open(dsn string) (driver.Conn, error) {
+	// 去掉 prefectDSN 追加的参数
+	if pos := strings.IndexByte(dsn, '?'); pos >= 0 {
+		dsn = dsn[:pos]
+	}
+	if dsn == "down" {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return rwTestConn{dsn: dsn}, nil
+}
+
+func (c rwTestConn) Prepare(string) (driver.Stmt, error) {
+	return rwTestStmt{dsn: c.dsn}, nil
+}
+
+func (rwTestConn) Close() error {
+	return nil
+}
+
+func (rwTestConn) Begin() (driver.Tx, error) {
+	return ctxTestTx{}, nil
+}
+
+func (rwTestStmt) Close() error {
+	return nil
+}
+
+func (rwTestStmt) NumInput() int {
+	return -1
+}
+
+func (rwTestStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (s rwTestStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &rwTestRows{dsn: s.dsn}, nil
+}
+
+func (r *rwTestRows) Columns() []string {
+	return []string{"dsn"}
+}
+
+func (r *rwTestRows) Close() error {
+	return nil
+}
+
+func (r *rwTestRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	r.done = true
+	dest[0] = r.dsn
+	return nil
+}
@@ -1,6 +1,7 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -14,12 +15,14 @@ type (
 	StmtSession interface {
 		Close() error
 		Exec(args ...interface{}) (sql.Result, error)
+		ExecCtx(ctx context.Context, args ...interface{}) (sql.Result, error)
 		Query(dest interface{}, args ...interface{}) error
+		QueryCtx(ctx context.Context, dest interface{}, args ...interface{}) error
 	}
 
 	stmtConn interface {
-		Exec(args ...interface{}) (sql.Result, error)
-		Query(args ...interface{}) (*sql.Rows, error)
+		ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error)
+		QueryContext(ctx context.Context, args ...interface{}) (*sql.Rows, error)
 	}
 
 	// 封装内部使用的预编译语句
@@ -34,32 +37,43 @@ func (s statement) Close() error {
 }
 
 func (s statement) Exec(args ...interface{}) (sql.Result, error) {
-	return doExecStmt(s.stmt, s.query, args...)
+	return s.ExecCtx(context.Background(), args...)
+}
+
+// ExecCtx 带上下文执行预编译语句
+func (s statement) ExecCtx(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	return doExecStmt(ctx, s.stmt, s.query, args...)
 }
 
 func (s statement) Query(dest interface{}, args ...interface{}) error {
-	return doQueryStmt(s.stmt, func(rows *sql.Rows) error {
+	return s.QueryCtx(context.Background(), dest, args...)
+}
+
+// QueryCtx 带上下文执行预编译查询
+func (s statement) QueryCtx(ctx context.Context, dest interface{}, args ...interface{}) error {
+	return doQueryStmt(ctx, s.stmt, func(rows *sql.Rows) error {
 		return scan(dest, rows)
 	}, s.query, args...)
 }
 
 // doQueryStmt 执行预编译查询语句
-func doQueryStmt(conn stmtConn, scanner func(rows *sql.Rows) error, query string, args ...interface{}) error {
+func doQueryStmt(ctx context.Context, conn stmtConn, scanner func(rows *sql.Rows) error, query string, args ...interface{}) error {
 	stmt, err := format(query, args...)
 	if err != nil {
 		return err
 	}
 
 	startTime := timex.Now()
-	rows, err := conn.Query(args...)
+	rows, err := conn.QueryContext(ctx, args...)
 	duration := timex.Since(startTime)
 	if duration > slowThreshold {
-		logx.WithDuration(duration).Slowf("[SQL] doExecStmt: 慢查询 —— %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Slowf("[SQL] doExecStmt: 慢查询 —— %s", stmt)
 	} else {
-		logx.WithDuration(duration).Infof("[SQL] doExecStmt: %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Infof("[SQL] doExecStmt: %s", stmt)
 	}
 	if err != nil {
-		logSqlError(stmt, err)
+		logSqlError(ctx, stmt, err)
+		return err
 	}
 	defer rows.Close()
 
@@ -67,29 +81,29 @@ func doQueryStmt(conn stmtConn, scanner func(rows *sql.Rows) error, query string
 }
 
 // doExecStmt 执行预编译语句
-func doExecStmt(conn stmtConn, query string, args ...interface{}) (sql.Result, error) {
+func doExecStmt(ctx context.Context, conn stmtConn, query string, args ...interface{}) (sql.Result, error) {
 	stmt, err := format(query, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	startTime := timex.Now()
-	result, err := conn.Exec(args...)
+	result, err := conn.ExecContext(ctx, args...)
 	duration := timex.Since(startTime)
 	if duration > slowThreshold {
-		logx.WithDuration(duration).Slowf("[SQL] doExecStmt: 慢查询 —— %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Slowf("[SQL] doExecStmt: 慢查询 —— %s", stmt)
 	} else {
-		logx.WithDuration(duration).Infof("[SQL] doExecStmt: %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Infof("[SQL] doExecStmt: %s", stmt)
 	}
 	if err != nil {
-		logSqlError(stmt, err)
+		logSqlError(ctx, stmt, err)
 	}
 
 	return result, err
 }
 
 // doQuery 执行查询语句
-func doQuery(db session, scanner func(*sql.Rows) error, query string, args ...interface{}) error {
+func doQuery(ctx context.Context, db session, scanner func(*sql.Rows) error, query string, args ...interface{}) error {
 	// 格式化后的查询字符串
 	stmt, err := format(query, args...)
 	if err != nil {
@@ -98,17 +112,17 @@ func doQuery(db session, scanner func(*sql.Rows) error, query string, args ...in
 
 	// 带有慢查询检测
 	startTime := time.Now()
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	duration := time.Since(startTime)
 
 	if duration > slowThreshold {
-		logx.WithDuration(duration).Slowf("[SQL] 慢查询 - %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Slowf("[SQL] 慢查询 - %s", stmt)
 	} else {
-		logx.WithDuration(duration).Infof("[SQL] 查询: %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Infof("[SQL] 查询: %s", stmt)
 	}
 
 	if err != nil {
-		logSqlError(stmt, err)
+		logSqlError(ctx, stmt, err)
 		return err
 	}
 
@@ -121,7 +135,7 @@ func doQuery(db session, scanner func(*sql.Rows) error, query string, args ...in
 }
 
 // 执行语句
-func doExec(db session, query string, args ...interface{}) (sql.Result, error) {
+func doExec(ctx context.Context, db session, query string, args ...interface{}) (sql.Result, error) {
 	// 格式化后的查询字符串
 	stmt, err := format(query, args...)
 	if err != nil {
@@ -149,17 +163,17 @@ func doExec(db session, query string, args ...interface{}) (sql.Result, error) {
 
 	// 带有慢查询检测
 	startTime := time.Now()
-	result, err := db.Exec(query, nvArgs...)
+	result, err := db.ExecContext(ctx, query, nvArgs...)
 	duration := time.Since(startTime)
 
 	if duration > slowThreshold {
-		logx.WithDuration(duration).Slowf("[SQL] 慢执行(%v) - %+v", duration, stmt)
+		logx.WithContext(ctx).WithDuration(duration).Slowf("[SQL] 慢执行(%v) - %+v", duration, stmt)
 	} else {
-		logx.WithDuration(duration).Infof("[SQL] 执行: %+v", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Infof("[SQL] 执行: %+v", stmt)
 	}
 
 	if err != nil {
-		logSqlError(stmt, err)
+		logSqlError(ctx, stmt, err)
 	}
 
 	return result, err
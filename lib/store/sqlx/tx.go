@@ -1,13 +1,14 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
 
 type (
 	// 开启一个数据库事务
-	beginTxFn func(*sql.DB) (TxSession, error)
+	beginTxFn func(ctx context.Context, db *sql.DB) (TxSession, error)
 
 	// TxSession 该接口表示一个数据库事务的会话。
 	TxSession interface {
@@ -24,19 +25,34 @@ type (
 
 // Query 带事务查询
 func (tx txSession) Query(dest interface{}, query string, args ...interface{}) error {
-	return doQuery(tx.Tx, func(rows *sql.Rows) error {
+	return tx.QueryCtx(context.Background(), dest, query, args...)
+}
+
+// QueryCtx 带上下文和事务查询
+func (tx txSession) QueryCtx(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return doQuery(ctx, tx.Tx, func(rows *sql.Rows) error {
 		return scan(dest, rows)
 	}, query, args...)
 }
 
 // Exec 带事务执行
 func (tx txSession) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return doExec(tx.Tx, query, args...)
+	return tx.ExecCtx(context.Background(), query, args...)
+}
+
+// ExecCtx 带上下文和事务执行
+func (tx txSession) ExecCtx(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return doExec(ctx, tx.Tx, query, args...)
 }
 
 // Prepare 带事务创建预编译语句
 func (tx txSession) Prepare(query string) (StmtSession, error) {
-	stmt, err := tx.Tx.Prepare(query)
+	return tx.PrepareCtx(context.Background(), query)
+}
+
+// PrepareCtx 带上下文和事务创建预编译语句
+func (tx txSession) PrepareCtx(ctx context.Context, query string) (StmtSession, error) {
+	stmt, err := tx.Tx.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -47,8 +63,8 @@ func (tx txSession) Prepare(query string) (StmtSession, error) {
 	}, nil
 }
 
-func beginTx(db *sql.DB) (TxSession, error) {
-	if tx, err := db.Begin(); err != nil {
+func beginTx(ctx context.Context, db *sql.DB) (TxSession, error) {
+	if tx, err := db.BeginTx(ctx, nil); err != nil {
 		return nil, err
 	} else {
 		return txSession{Tx: tx}, nil
@@ -56,7 +72,7 @@ func beginTx(db *sql.DB) (TxSession, error) {
 }
 
 // doTx 执行一个事务
-func doTx(c *conn, beginTx beginTxFn, transact TransactFn) (err error) {
+func doTx(ctx context.Context, c *conn, beginTx beginTxFn, transact TransactCtxFn) (err error) {
 	var db *sql.DB
 	db, err = getConn(c.driverName, c.dataSourceName)
 	if err != nil {
@@ -65,10 +81,13 @@ func doTx(c *conn, beginTx beginTxFn, transact TransactFn) (err error) {
 	}
 
 	var tx TxSession
-	tx, err = beginTx(db)
+	tx, err = beginTx(ctx, db)
 	if err != nil {
 		return
 	}
+	if c.postgres {
+		tx = pgTxSession{TxSession: tx}
+	}
 
 	defer func() {
 		if p := recover(); p != nil {
@@ -86,5 +105,5 @@ func doTx(c *conn, beginTx beginTxFn, transact TransactFn) (err error) {
 		}
 	}()
 
-	return transact(tx)
+	return transact(ctx, tx)
 }
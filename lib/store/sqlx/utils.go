@@ -1,6 +1,7 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -23,6 +24,15 @@ type UpdateArgs struct {
 }
 
 func ExtractUpdateArgs(allFieldList []string, updateMap g.Map) (*UpdateArgs, error) {
+	return extractUpdateArgs(allFieldList, updateMap, "`")
+}
+
+// ExtractPgUpdateArgs 同 ExtractUpdateArgs，字段名使用 PostgreSQL 的双引号包裹
+func ExtractPgUpdateArgs(allFieldList []string, updateMap g.Map) (*UpdateArgs, error) {
+	return extractUpdateArgs(allFieldList, updateMap, `"`)
+}
+
+func extractUpdateArgs(allFieldList []string, updateMap g.Map, quote string) (*UpdateArgs, error) {
 	vid, ok := updateMap["id"]
 	id := gconv.Int64(vid)
 	if !ok || id == 0 {
@@ -37,8 +47,8 @@ func ExtractUpdateArgs(allFieldList []string, updateMap g.Map) (*UpdateArgs, err
 			continue
 		}
 
-		if !strings.HasPrefix(field, "`") {
-			field = fmt.Sprintf("`%s`", field)
+		if !strings.HasPrefix(field, quote) {
+			field = quote + field + quote
 		}
 
 		if stringx.Contains(allFieldList, field) {
@@ -56,6 +66,11 @@ func ExtractUpdateArgs(allFieldList []string, updateMap g.Map) (*UpdateArgs, err
 
 // format 格式查询字符串和参数
 func format(query string, args ...interface{}) (string, error) {
+	return formatWith(writeValue, query, args...)
+}
+
+// formatWith 使用指定的值写入方法格式化查询字符串和参数，支持 ? 和 $n 两种占位符
+func formatWith(write func(buf *strings.Builder, arg interface{}), query string, args ...interface{}) (string, error) {
 	numArgs := len(args)
 	if numArgs == 0 {
 		return query, nil
@@ -73,11 +88,11 @@ func format(query string, args ...interface{}) (string, error) {
 				return "", fmt.Errorf("错误：SQL中有 %d 个问号(?)，但提供的参数不够", argIdx)
 			}
 
-			writeValue(&b, args[argIdx])
+			write(&b, args[argIdx])
 			argIdx++
 		case '$':
-			var j int
-			for j := i + 1; j < bytes; j++ {
+			j := i + 1
+			for ; j < bytes; j++ {
 				char := query[j]
 				if char < '0' || char > '9' {
 					break
@@ -97,8 +112,10 @@ func format(query string, args ...interface{}) (string, error) {
 					return "", fmt.Errorf("错误：SQL index %d 越界", index)
 				}
 
-				writeValue(&b, args[index])
+				write(&b, args[index])
 				i = j - 1
+			} else {
+				b.WriteByte(ch)
 			}
 		default:
 			b.WriteByte(ch)
@@ -177,9 +194,9 @@ func prefectDSN(dataSourceName *string) {
 	}
 }
 
-func logSqlError(sql string, err error) {
+func logSqlError(ctx context.Context, sql string, err error) {
 	if err != nil && err != ErrNotFound {
-		logx.Errorf("[SQL] %s >>> %s", err.Error(), sql)
+		logx.WithContext(ctx).Errorf("[SQL] %s >>> %s", err.Error(), sql)
 	}
 }
 
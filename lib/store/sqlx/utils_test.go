@@ -20,6 +20,12 @@ func TestFormat(t *testing.T) {
 			args:  []interface{}{true, "133"},
 			want:  "select name, age from users where bool=1 and phone='133'",
 		},
+		{
+			name:  "postgres 序号占位符",
+			query: "select name, age from users where bool=$1 and phone=$2 and price=$",
+			args:  []interface{}{true, "133"},
+			want:  "select name, age from users where bool=1 and phone='133' and price=$",
+		},
 	}
 
 	for _, test := range tests {
@@ -71,12 +71,21 @@ func Remove(strings []string, strs ...string) []string {
 }
 
 func RemoveDBFields(strings []string, strs ...string) []string {
+	return removeQuotedFields(strings, "`", strs...)
+}
+
+// RemovePgDBFields 同 RemoveDBFields，字段名为 PostgreSQL 的双引号包裹形式
+func RemovePgDBFields(strings []string, strs ...string) []string {
+	return removeQuotedFields(strings, `"`, strs...)
+}
+
+func removeQuotedFields(strings []string, quote string, strs ...string) []string {
 	out := append([]string(nil), strings...)
 
 	for _, str := range strs {
 		var n int
 		for _, v := range out {
-			if v != "`"+str+"`" {
+			if v != quote+str+quote {
 				out[n] = v
 				n++
 			}
@@ -4,6 +4,9 @@ import (
 	"git.zc0901.com/go/god/lib/discovery"
 	"git.zc0901.com/go/god/rpc/internal"
 	"git.zc0901.com/go/god/rpc/internal/auth"
+	"git.zc0901.com/go/god/rpc/internal/balancer/consistenthash"
+	"git.zc0901.com/go/god/rpc/internal/balancer/p2c"
+	"git.zc0901.com/go/god/rpc/internal/clientinterceptors"
 	"google.golang.org/grpc"
 	"log"
 	"time"
@@ -12,11 +15,26 @@ import (
 var (
 	WithDialOption             = internal.WithDialOption
 	WithTimeout                = internal.WithTimeout
+	WithHealthCheck            = internal.WithHealthCheck
+	WithRetry                  = internal.WithRetry
+	WithBalancer               = internal.WithBalancer
+	WithRoute                  = internal.WithRoute
 	WithUnaryClientInterceptor = internal.WithUnaryClientInterceptor
+
+	// WithHashKey 设置请求的哈希键，使用 consistent_hash 负载均衡器时相同哈希键的请求尽量路由到同一服务端
+	WithHashKey = consistenthash.WithHashKey
+	// WithRequestRoute 设置单个请求的路由条件，未设置的字段沿用客户端配置的路由条件
+	WithRequestRoute = p2c.WithRoute
 )
 
 type (
 	ClientOption = internal.ClientOption
+	// RetryConf 客户端重试配置
+	RetryConf = clientinterceptors.RetryConf
+	// MethodRetryConf 按方法覆盖的重试配置
+	MethodRetryConf = clientinterceptors.MethodRetryConf
+	// Route 请求的路由条件
+	Route = p2c.Route
 
 	Client interface {
 		Conn() *grpc.ClientConn
@@ -43,6 +61,10 @@ func MustNewClient(conf ClientConf, options ...ClientOption) Client {
 
 // MustNewClient 根据配置文件新建rpc客户端，出错直接退出。
 func NewClient(c ClientConf, options ...ClientOption) (Client, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
 	var opts []ClientOption
 	if c.HasCredential() {
 		opts = append(opts, WithDialOption(grpc.WithPerRPCCredentials(&auth.Credential{
@@ -53,6 +75,18 @@ func NewClient(c ClientConf, options ...ClientOption) (Client, error) {
 	if c.Timeout > 0 {
 		opts = append(opts, WithTimeout(time.Duration(c.Timeout)*time.Millisecond))
 	}
+	if c.Retry.MaxAttempts > 1 || len(c.Retry.Methods) > 0 {
+		opts = append(opts, WithRetry(c.Retry))
+	}
+	if len(c.Balancer) > 0 {
+		opts = append(opts, WithBalancer(c.Balancer))
+	}
+	if !c.Route.IsZero() {
+		opts = append(opts, WithRoute(c.Route))
+	}
+	if c.HealthCheck {
+		opts = append(opts, WithHealthCheck())
+	}
 	opts = append(opts, options...)
 
 	var client Client
@@ -9,23 +9,33 @@ import (
 type (
 	// ServerConf Rpc服务端配置
 	ServerConf struct {
-		service.Conf                     // 服务配置
-		ListenOn      string             // rpc监听地址和端口，如：127.0.0.1:8888
-		Etcd          discovery.EtcdConf `json:",optional"`                   // etcd相关配置
-		Auth          bool               `json:",optional"`                   // 是否开启rpc通信鉴权，若是则Redis配置必填
-		Redis         redis.KeyConf      `json:",optional"`                   // rpc通信及安全的redis配置
-		StrictControl bool               `json:",optional"`                   // 是否为严格模式，若是且遇到鉴权错误则抛出异常
-		Timeout       int64              `json:",default=2000"`               // 默认超时时长为2000毫秒，<=0则意味支持无限期等待
-		CpuThreshold  int64              `json:",default=900,range=[0:1000]"` // cpu降载阈值，默认900，支持区间为0-1000
+		service.Conf                             // 服务配置
+		ListenOn          string                 // rpc监听地址和端口，如：127.0.0.1:8888
+		Etcd              discovery.EtcdConf     `json:",optional"`                   // etcd相关配置
+		Auth              bool                   `json:",optional"`                   // 是否开启rpc通信鉴权，若是则Redis配置必填
+		Redis             redis.KeyConf          `json:",optional"`                   // rpc通信及安全的redis配置
+		StrictControl     bool                   `json:",optional"`                   // 是否为严格模式，若是且遇到鉴权错误则抛出异常
+		Timeout           int64                  `json:",default=2000"`               // 默认超时时长为2000毫秒，<=0则意味支持无限期等待
+		CpuThreshold      int64                  `json:",default=900,range=[0:1000]"` // cpu降载阈值，默认900，支持区间为0-1000
+		StreamTimeout     int64                  `json:",optional"`                   // 流的总时长上限（毫秒），<=0则不限制
+		StreamIdleTimeout int64                  `json:",optional"`                   // 流两次收发消息之间的最长间隔（毫秒），<=0则不限制
+		Health            bool                   `json:",optional"`                   // 是否注册grpc.health.v1健康检查服务，停机和过载时报告NOT_SERVING
+		Reflection        bool                   `json:",optional"`                   // 是否注册服务反射，便于grpcurl调试
+		Channelz          bool                   `json:",optional"`                   // 是否注册channelz服务
+		Meta              discovery.EndpointMeta `json:",optional"`                   // 发布到服务发现的节点元数据，如可用区、版本、权重和标签，需先升级所有客户端再设置
 	}
 
 	// ClientConf Rpc客户端配置
 	ClientConf struct {
-		Etcd      discovery.EtcdConf `json:",optional"`
-		Endpoints []string           `json:",optional=!Etcd"`
-		App       string             `json:",optional"`
-		Token     string             `json:",optional"`
-		Timeout   int64              `json:",default=2000"`
+		Etcd        discovery.EtcdConf `json:",optional"`
+		Endpoints   []string           `json:",optional=!Etcd"`
+		App         string             `json:",optional"`
+		Token       string             `json:",optional"`
+		Timeout     int64              `json:",default=2000"`
+		HealthCheck bool               `json:",optional"`                                  // 是否启用客户端健康检查，跳过报告NOT_SERVING的服务端
+		Retry       RetryConf          `json:",optional"`                                  // 失败重试和请求对冲配置
+		Balancer    string             `json:",optional,options=p2c_ewma|consistent_hash"` // 负载均衡器，默认p2c_ewma，consistent_hash按WithHashKey设置的哈希键路由
+		Route       Route              `json:",optional"`                                  // p2c_ewma的路由条件，如客户端所在可用区、要调用的服务端版本和标签
 	}
 )
 
@@ -64,6 +74,11 @@ func (sc ServerConf) Validate() error {
 	return sc.Redis.Validate()
 }
 
+// Validate 验证客户端重试配置
+func (cc ClientConf) Validate() error {
+	return cc.Retry.Validate()
+}
+
 // HasCredential 判断客户端配置是否存在App+token凭证
 func (cc ClientConf) HasCredential() bool {
 	return len(cc.App) > 0 && len(cc.Token) > 0
@@ -0,0 +1,40 @@
+package consistenthash
+
+import (
+	"context"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+const (
+	// Name 一致性哈希平衡器的名字
+	Name = "consistent_hash"
+
+	loadFactor = 1.25 // 有界负载系数，连接的飞行请求数不超过平均值的 loadFactor 倍
+	maxRehash  = 8    // 连接超载时重新哈希查找其他连接的最大次数
+)
+
+type hashKey struct{}
+
+func init() {
+	balancer.Register(newBuilder())
+}
+
+func newBuilder() balancer.Builder {
+	return base.NewBalancerBuilder(Name, new(pickerBuilder), base.Config{HealthCheck: true})
+}
+
+// WithHashKey 设置请求的哈希键，相同哈希键的请求尽量路由到同一服务端
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKey{}, key)
+}
+
+func hashKeyFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	key, ok := ctx.Value(hashKey{}).(string)
+	return key, ok && len(key) > 0
+}
@@ -0,0 +1,119 @@
+package consistenthash
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestPicker_PickNil(t *testing.T) {
+	picker := new(pickerBuilder).Build(base.PickerBuildInfo{})
+	_, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            context.Background(),
+	})
+	assert.Equal(t, balancer.ErrNoSubConnAvailable, err)
+}
+
+func TestPicker_PickWithHashKey(t *testing.T) {
+	picker := buildPicker(10)
+	ctx := WithHashKey(context.Background(), "user-1")
+	first := pick(t, picker, ctx)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, first, pick(t, picker, ctx))
+	}
+}
+
+func TestPicker_PickWithoutHashKey(t *testing.T) {
+	picker := buildPicker(4)
+	dist := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		dist[pick(t, picker, context.Background())]++
+	}
+	assert.Len(t, dist, 4)
+	for _, count := range dist {
+		assert.Equal(t, 25, count)
+	}
+}
+
+func TestPicker_BoundedLoad(t *testing.T) {
+	picker := buildPicker(4)
+	ctx := WithHashKey(context.Background(), "hot")
+
+	var results []balancer.PickResult
+	dist := make(map[balancer.SubConn]int)
+	for i := 0; i < 100; i++ {
+		result, err := picker.Pick(balancer.PickInfo{FullMethodName: "/", Ctx: ctx})
+		assert.Nil(t, err)
+		results = append(results, result)
+		dist[result.SubConn]++
+	}
+
+	// 热点键超出负载上限后分流到其他连接
+	assert.True(t, len(dist) > 1)
+	for _, count := range dist {
+		assert.True(t, count <= 32, strconv.Itoa(count))
+	}
+
+	for _, result := range results {
+		result.Done(balancer.DoneInfo{})
+	}
+	assert.Equal(t, int64(0), picker.(*chPicker).inflight)
+}
+
+func TestPicker_Rebalance(t *testing.T) {
+	const keys = 1000
+	before := buildPicker(10)
+	after := buildPicker(11)
+
+	var moved int
+	for i := 0; i < keys; i++ {
+		ctx := WithHashKey(context.Background(), "user-"+strconv.Itoa(i))
+		oldAddr, newAddr := pick(t, before, ctx), pick(t, after, ctx)
+		if oldAddr != newAddr {
+			// 新增节点只接管部分哈希键
+			assert.Equal(t, "10", newAddr)
+			moved++
+		}
+	}
+	assert.True(t, moved > 0)
+	assert.True(t, moved < keys/5, strconv.Itoa(moved))
+}
+
+func buildPicker(candidates int) balancer.Picker {
+	ready := make(map[balancer.SubConn]base.SubConnInfo)
+	for i := 0; i < candidates; i++ {
+		ready[&mockSubConn{id: i}] = base.SubConnInfo{
+			Address: resolver.Address{
+				Addr: strconv.Itoa(i),
+			},
+		}
+	}
+
+	return new(pickerBuilder).Build(base.PickerBuildInfo{
+		ReadySCs: ready,
+	})
+}
+
+// pick 选择连接并立即完成请求，返回连接地址
+func pick(t *testing.T, picker balancer.Picker, ctx context.Context) string {
+	result, err := picker.Pick(balancer.PickInfo{FullMethodName: "/", Ctx: ctx})
+	assert.Nil(t, err)
+	result.Done(balancer.DoneInfo{})
+	return strconv.Itoa(result.SubConn.(*mockSubConn).id)
+}
+
+type mockSubConn struct {
+	id int
+}
+
+func (m *mockSubConn) UpdateAddresses(addresses []resolver.Address) {
+}
+
+func (m *mockSubConn) Connect() {
+}
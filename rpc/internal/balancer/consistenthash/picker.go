@@ -0,0 +1,113 @@
+package consistenthash
+
+import (
+	"math"
+	"strconv"
+	"sync/atomic"
+
+	"git.zc0901.com/go/god/lib/hash"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+type (
+	pickerBuilder struct{}
+
+	subConn struct {
+		addr     string
+		conn     balancer.SubConn
+		inflight int64 // 飞行中的数量
+		requests int64 // 请求数
+	}
+
+	// chPicker 一致性哈希选择器，服务端列表变化时重建，
+	// 只有增删节点上的哈希键会被重新分配，其余哈希键仍路由到原来的服务端
+	chPicker struct {
+		ring     *hash.ConsistentHash
+		conns    map[string]*subConn
+		list     []*subConn
+		inflight int64
+		next     uint64
+	}
+)
+
+func (b *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	p := &chPicker{
+		ring:  hash.NewConsistentHash(),
+		conns: make(map[string]*subConn),
+	}
+	for conn, connInfo := range info.ReadySCs {
+		c := &subConn{
+			addr: connInfo.Address.Addr,
+			conn: conn,
+		}
+		p.conns[c.addr] = c
+		p.list = append(p.list, c)
+		p.ring.Add(c.addr)
+	}
+
+	return p
+}
+
+func (p *chPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	var chosen *subConn
+	if key, ok := hashKeyFromContext(info.Ctx); ok {
+		chosen = p.pick(key)
+	} else {
+		// 没有哈希键时轮询
+		chosen = p.list[atomic.AddUint64(&p.next, 1)%uint64(len(p.list))]
+	}
+
+	atomic.AddInt64(&chosen.inflight, 1)
+	atomic.AddInt64(&chosen.requests, 1)
+	atomic.AddInt64(&p.inflight, 1)
+
+	return balancer.PickResult{
+		SubConn: chosen.conn,
+		Done: func(info balancer.DoneInfo) {
+			atomic.AddInt64(&chosen.inflight, -1)
+			atomic.AddInt64(&p.inflight, -1)
+		},
+	}, nil
+}
+
+// pick 返回哈希键对应的连接，连接超载时重新哈希查找其他连接，均超载则返回其中负载最低的连接
+func (p *chPicker) pick(key string) *subConn {
+	limit := p.limit()
+	var chosen *subConn
+	for i := 0; i <= maxRehash; i++ {
+		candidate := key
+		if i > 0 {
+			candidate = key + "#" + strconv.Itoa(i)
+		}
+
+		node, ok := p.ring.Get(candidate)
+		if !ok {
+			break
+		}
+
+		c := p.conns[node.(string)]
+		inflight := atomic.LoadInt64(&c.inflight)
+		if inflight < limit {
+			return c
+		}
+		if chosen == nil || inflight < atomic.LoadInt64(&chosen.inflight) {
+			chosen = c
+		}
+	}
+
+	if chosen == nil {
+		chosen = p.list[0]
+	}
+	return chosen
+}
+
+// limit 返回每个连接允许的飞行请求数上限
+func (p *chPicker) limit() int64 {
+	total := atomic.LoadInt64(&p.inflight) + 1
+	return int64(math.Ceil(loadFactor * float64(total) / float64(len(p.list))))
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"git.zc0901.com/go/god/lib/syncx"
+	"git.zc0901.com/go/god/rpc/internal/resolver"
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
 )
@@ -19,9 +20,16 @@ func (b *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 
 	var conns []*subConn
 	for conn, connInfo := range readySCs {
+		meta, _ := resolver.EndpointMeta(connInfo.Address)
+		weight := int64(meta.Weight)
+		if weight <= 0 || weight > topWeight {
+			weight = topWeight
+		}
 		conns = append(conns, &subConn{
 			addr:    connInfo.Address,
 			conn:    conn,
+			meta:    meta,
+			weight:  weight,
 			success: initSuccess,
 		})
 	}
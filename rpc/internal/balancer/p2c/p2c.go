@@ -18,6 +18,7 @@ const (
 	throttleSuccess = initSuccess / 2      // 健康检测阈值
 	penalty         = int64(math.MaxInt32) // 最大惩罚值
 	pickTimes       = 3
+	topWeight       = 100 // 最大权重，未设置权重的节点按最大权重计算
 	logInterval     = time.Minute
 )
 
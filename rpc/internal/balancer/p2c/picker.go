@@ -26,24 +26,29 @@ func (p *p2cPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	conns := p.conns
+	if route, ok := RouteFromContext(info.Ctx); ok && !route.IsZero() {
+		conns = p.candidates(route)
+	}
+
 	var chosen *subConn
-	switch len(p.conns) {
+	switch len(conns) {
 	case 0: // 没有连接
 		return balancer.PickResult{SubConn: nil, Done: nil}, balancer.ErrNoSubConnAvailable
 	case 1: // 一个连接
-		chosen = p.choose(p.conns[0], nil)
+		chosen = p.choose(conns[0], nil)
 	case 2: // 2个连接
-		chosen = p.choose(p.conns[0], p.conns[1])
+		chosen = p.choose(conns[0], conns[1])
 	default: // 2个以上连接，随机选举两个健康的并在其中选举1个
 		var c1, c2 *subConn
 		for i := 0; i < pickTimes; i++ {
-			a := p.r.Intn(len(p.conns))
-			b := p.r.Intn(len(p.conns) - 1)
+			a := p.r.Intn(len(conns))
+			b := p.r.Intn(len(conns) - 1)
 			if b >= a {
 				b++
 			}
-			c1 = p.conns[a]
-			c2 = p.conns[b]
+			c1 = conns[a]
+			c2 = conns[b]
 			if c1.healthy() && c2.healthy() {
 				break
 			}
@@ -61,6 +66,37 @@ func (p *p2cPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 	}, nil
 }
 
+// candidates 返回满足路由条件的连接：先按版本和标签筛选，再优先选择同可用区的健康连接，
+// 筛选结果为空时放宽条件，以免路由条件导致服务不可用
+func (p *p2cPicker) candidates(route Route) []*subConn {
+	conns := p.conns
+	if len(route.Version) > 0 || len(route.Tags) > 0 {
+		var matched []*subConn
+		for _, conn := range conns {
+			if conn.matches(route) {
+				matched = append(matched, conn)
+			}
+		}
+		if len(matched) > 0 {
+			conns = matched
+		}
+	}
+
+	if len(route.Zone) > 0 {
+		var local []*subConn
+		for _, conn := range conns {
+			if conn.meta.Zone == route.Zone && conn.healthy() {
+				local = append(local, conn)
+			}
+		}
+		if len(local) > 0 {
+			conns = local
+		}
+	}
+
+	return conns
+}
+
 // choose 从两个连接中选举一个用于使用
 func (p *p2cPicker) choose(c1, c2 *subConn) *subConn {
 	start := int64(timex.Now())
@@ -0,0 +1,49 @@
+package p2c
+
+import "context"
+
+type (
+	// Route 请求的路由条件
+	Route struct {
+		Zone    string            `json:",optional"` // 优先选择该可用区的健康节点，没有时选择其他可用区的节点
+		Version string            `json:",optional"` // 只选择该版本的节点，如金丝雀版本，没有匹配的节点时选择全部节点
+		Tags    map[string]string `json:",optional"` // 只选择包含全部标签的节点，没有匹配的节点时选择全部节点
+	}
+
+	routeKey struct{}
+)
+
+// WithRoute 设置请求的路由条件
+func WithRoute(ctx context.Context, route Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFromContext 返回请求的路由条件
+func RouteFromContext(ctx context.Context) (Route, bool) {
+	if ctx == nil {
+		return Route{}, false
+	}
+
+	route, ok := ctx.Value(routeKey{}).(Route)
+	return route, ok
+}
+
+// IsZero 判断路由条件是否为空
+func (r Route) IsZero() bool {
+	return len(r.Zone) == 0 && len(r.Version) == 0 && len(r.Tags) == 0
+}
+
+// Merge 用 r 中未设置的字段取 other 中的值
+func (r Route) Merge(other Route) Route {
+	if len(r.Zone) == 0 {
+		r.Zone = other.Zone
+	}
+	if len(r.Version) == 0 {
+		r.Version = other.Version
+	}
+	if len(r.Tags) == 0 {
+		r.Tags = other.Tags
+	}
+
+	return r
+}
@@ -0,0 +1,149 @@
+package p2c
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"git.zc0901.com/go/god/lib/discovery"
+	"git.zc0901.com/go/god/lib/syncx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestRoute_Merge(t *testing.T) {
+	route := Route{Version: "v2"}.Merge(Route{Zone: "az1", Version: "v1", Tags: map[string]string{"a": "b"}})
+	assert.Equal(t, Route{Zone: "az1", Version: "v2", Tags: map[string]string{"a": "b"}}, route)
+	assert.True(t, Route{}.IsZero())
+	assert.False(t, route.IsZero())
+}
+
+func TestRouteFromContext(t *testing.T) {
+	_, ok := RouteFromContext(context.Background())
+	assert.False(t, ok)
+
+	route, ok := RouteFromContext(WithRoute(context.Background(), Route{Zone: "az1"}))
+	assert.True(t, ok)
+	assert.Equal(t, "az1", route.Zone)
+}
+
+func TestPicker_Candidates(t *testing.T) {
+	picker := newRoutePicker(
+		discovery.EndpointMeta{Zone: "az1", Version: "v1"},
+		discovery.EndpointMeta{Zone: "az2", Version: "v1"},
+		discovery.EndpointMeta{Zone: "az2", Version: "v2", Tags: map[string]string{"lane": "canary"}},
+	)
+
+	tests := []struct {
+		name   string
+		route  Route
+		expect []string
+	}{
+		{
+			name:   "同可用区",
+			route:  Route{Zone: "az1"},
+			expect: []string{"0"},
+		},
+		{
+			name:   "可用区无节点",
+			route:  Route{Zone: "az3"},
+			expect: []string{"0", "1", "2"},
+		},
+		{
+			name:   "版本",
+			route:  Route{Version: "v1"},
+			expect: []string{"0", "1"},
+		},
+		{
+			name:   "版本和可用区",
+			route:  Route{Zone: "az2", Version: "v1"},
+			expect: []string{"1"},
+		},
+		{
+			name:   "标签",
+			route:  Route{Tags: map[string]string{"lane": "canary"}},
+			expect: []string{"2"},
+		},
+		{
+			name:   "版本无节点",
+			route:  Route{Version: "v3"},
+			expect: []string{"0", "1", "2"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var addrs []string
+			for _, conn := range picker.candidates(test.route) {
+				addrs = append(addrs, conn.addr.Addr)
+			}
+			assert.Equal(t, test.expect, addrs)
+		})
+	}
+}
+
+func TestPicker_CandidatesUnhealthyZone(t *testing.T) {
+	picker := newRoutePicker(
+		discovery.EndpointMeta{Zone: "az1"},
+		discovery.EndpointMeta{Zone: "az2"},
+	)
+	picker.conns[0].success = 0
+	assert.Equal(t, 2, len(picker.candidates(Route{Zone: "az1"})))
+}
+
+func TestPicker_PickWithRoute(t *testing.T) {
+	picker := newRoutePicker(
+		discovery.EndpointMeta{Zone: "az1"},
+		discovery.EndpointMeta{Zone: "az2"},
+		discovery.EndpointMeta{Zone: "az2"},
+	)
+
+	ctx := WithRoute(context.Background(), Route{Zone: "az1"})
+	for i := 0; i < 100; i++ {
+		result, err := picker.Pick(balancer.PickInfo{
+			FullMethodName: "/",
+			Ctx:            ctx,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, picker.conns[0].conn, result.SubConn)
+		result.Done(balancer.DoneInfo{})
+	}
+}
+
+func TestSubConn_LoadWithWeight(t *testing.T) {
+	heavy := &subConn{weight: topWeight, lag: 100}
+	light := &subConn{weight: topWeight / 4, lag: 100}
+	assert.Equal(t, heavy.load()*4, light.load())
+}
+
+func newRoutePicker(metas ...discovery.EndpointMeta) *p2cPicker {
+	var conns []*subConn
+	for i, meta := range metas {
+		conns = append(conns, &subConn{
+			addr:    resolver.Address{Addr: strconv.Itoa(i)},
+			conn:    &mockSubConn{id: i},
+			meta:    meta,
+			weight:  topWeight,
+			success: initSuccess,
+		})
+	}
+
+	return &p2cPicker{
+		conns: conns,
+		r:     rand.New(rand.NewSource(1)),
+		stamp: syncx.NewAtomicDuration(),
+	}
+}
+
+type mockSubConn struct {
+	id int
+}
+
+func (m *mockSubConn) UpdateAddresses(addresses []resolver.Address) {
+}
+
+func (m *mockSubConn) Connect() {
+}
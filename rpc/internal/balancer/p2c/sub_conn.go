@@ -4,6 +4,7 @@ import (
 	"math"
 	"sync/atomic"
 
+	"git.zc0901.com/go/god/lib/discovery"
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/resolver"
 )
@@ -11,6 +12,8 @@ import (
 type subConn struct {
 	addr     resolver.Address
 	conn     balancer.SubConn
+	meta     discovery.EndpointMeta
+	weight   int64  // 权重，1-100
 	lag      uint64 // 延迟
 	inflight int64  // 飞行中的数量
 	requests int64  // 请求数
@@ -29,7 +32,26 @@ func (c *subConn) load() int64 {
 	load := lag * (atomic.LoadInt64(&c.inflight) + 1)
 	if load == 0 {
 		return penalty
-	} else {
-		return load
 	}
+
+	// 按权重折算，权重越大负载越低，被选中的机会越多
+	if c.weight > 0 && c.weight < topWeight {
+		load = load * topWeight / c.weight
+	}
+	return load
+}
+
+// matches 判断连接是否满足路由条件中的版本和标签
+func (c *subConn) matches(route Route) bool {
+	if len(route.Version) > 0 && c.meta.Version != route.Version {
+		return false
+	}
+
+	for k, v := range route.Tags {
+		if c.meta.Tags[k] != v {
+			return false
+		}
+	}
+
+	return true
 }
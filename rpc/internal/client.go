@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	_ "git.zc0901.com/go/god/rpc/internal/balancer/consistenthash"
 	"git.zc0901.com/go/god/rpc/internal/balancer/p2c"
 	"git.zc0901.com/go/god/rpc/internal/clientinterceptors"
 	"git.zc0901.com/go/god/rpc/internal/resolver"
@@ -16,6 +17,9 @@ import (
 const (
 	dialTimeout = 3 * time.Second
 	separator   = '/'
+
+	// healthCheckConfig 启用客户端健康检查的服务配置，空服务名表示检查服务端整体状态
+	healthCheckConfig = `{"healthCheckConfig": {"serviceName": ""}}`
 )
 
 type (
@@ -27,6 +31,7 @@ type (
 	// ClientOptions 是RPC客户端选择项
 	ClientOptions struct {
 		Timeout     time.Duration
+		Retry       clientinterceptors.RetryConf
 		DialOptions []grpc.DialOption
 	}
 
@@ -71,11 +76,16 @@ func (c *client) buildDialOptions(opts ...ClientOption) []grpc.DialOption {
 			clientinterceptors.UnaryTraceInterceptor,               // 线路跟踪
 			clientinterceptors.DurationInterceptor,                 // 慢查询日志
 			clientinterceptors.PrometheusInterceptor,               // 监控报警
-			clientinterceptors.BreakerInterceptor,                  // 自动熔断
-			clientinterceptors.TimeoutInterceptor(cliOpts.Timeout), // 超时控制
+			clientinterceptors.TimeoutInterceptor(cliOpts.Timeout), // 超时控制，包含全部重试
+			clientinterceptors.RetryInterceptor(cliOpts.Retry),     // 失败重试
+			clientinterceptors.BreakerInterceptor,                  // 自动熔断，每次重试均计入
+		),
+		WithStreamClientInterceptors(
+			clientinterceptors.StreamTraceInterceptor,      // 线路跟踪
+			clientinterceptors.StreamDurationInterceptor,   // 失败日志
+			clientinterceptors.StreamPrometheusInterceptor, // 监控报警
+			clientinterceptors.StreamBreakerInterceptor,    // 自动熔断
 		),
-		// WithStreamClientInterceptors(
-		//	clientinterceptors.st),
 	}
 
 	return append(options, cliOpts.DialOptions...)
@@ -115,6 +125,35 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithBalancer 设置负载均衡器，默认为 p2c.Name
+func WithBalancer(name string) ClientOption {
+	return WithDialOption(grpc.WithBalancerName(name))
+}
+
+// WithRoute 设置客户端的路由条件，如优先调用同可用区的服务端、只调用指定版本的服务端
+func WithRoute(route p2c.Route) ClientOption {
+	return func(options *ClientOptions) {
+		options.DialOptions = append(options.DialOptions,
+			WithUnaryClientInterceptors(clientinterceptors.RouteInterceptor(route)),
+			WithStreamClientInterceptors(clientinterceptors.StreamRouteInterceptor(route)))
+	}
+}
+
+// WithRetry 启用失败重试和请求对冲
+func WithRetry(c clientinterceptors.RetryConf) ClientOption {
+	return func(options *ClientOptions) {
+		options.Retry = c
+	}
+}
+
+// WithHealthCheck 启用客户端健康检查，p2c 平衡器跳过 grpc.health.v1 报告为 NOT_SERVING 的连接。
+// 服务端未注册健康检查服务时视为健康。
+func WithHealthCheck() ClientOption {
+	return func(options *ClientOptions) {
+		options.DialOptions = append(options.DialOptions, grpc.WithDefaultServiceConfig(healthCheckConfig))
+	}
+}
+
 func WithUnaryClientInterceptor(interceptor grpc.UnaryClientInterceptor) ClientOption {
 	return func(options *ClientOptions) {
 		options.DialOptions = append(options.DialOptions, WithUnaryClientInterceptors(interceptor))
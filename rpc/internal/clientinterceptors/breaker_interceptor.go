@@ -15,3 +15,31 @@ func BreakerInterceptor(ctx context.Context, method string, req, reply interface
 		return invoker(ctx, method, req, reply, cc, opts...)
 	}, codes.Acceptable)
 }
+
+// StreamBreakerInterceptor 流式熔断拦截器，建流失败和流异常结束都计入熔断统计
+func StreamBreakerInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	breakerName := path.Join(cc.Target(), method)
+	promise, err := breaker.GetBreaker(breakerName).Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		resolve(promise, err)
+		return nil, err
+	}
+
+	return newFinishStream(ctx, stream, desc, func(err error) {
+		resolve(promise, err)
+	}), nil
+}
+
+func resolve(promise breaker.Promise, err error) {
+	if err == nil || codes.Acceptable(err) {
+		promise.Accept()
+	} else {
+		promise.Reject(err.Error())
+	}
+}
@@ -0,0 +1,70 @@
+package clientinterceptors
+
+import (
+	"context"
+	"testing"
+
+	"git.zc0901.com/go/god/lib/breaker"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStreamBreakerInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		desc      grpc.StreamDesc
+		streamErr error
+		recvErr   error
+	}{
+		{
+			name: "eof",
+		},
+		{
+			name: "client streams",
+			desc: grpc.StreamDesc{ClientStreams: true},
+		},
+		{
+			name:      "stream error",
+			streamErr: status.Error(codes.Unavailable, "unavailable"),
+		},
+		{
+			name:    "recv error",
+			recvErr: status.Error(codes.Internal, "internal"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cc := new(grpc.ClientConn)
+			stream, err := StreamBreakerInterceptor(context.Background(), &test.desc, cc, "/"+test.name,
+				func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+					opts ...grpc.CallOption) (grpc.ClientStream, error) {
+					if test.streamErr != nil {
+						return nil, test.streamErr
+					}
+					return &mockedStream{recvErr: test.recvErr}, nil
+				})
+			assert.Equal(t, test.streamErr, err)
+			if err == nil {
+				assert.Equal(t, test.recvErr, stream.RecvMsg(nil))
+			}
+		})
+	}
+}
+
+func TestStreamBreakerInterceptor_Open(t *testing.T) {
+	const method = "/open"
+	cc := new(grpc.ClientConn)
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+
+	var err error
+	for i := 0; i < 1000 && err != breaker.ErrServiceUnavailable; i++ {
+		_, err = StreamBreakerInterceptor(context.Background(), &grpc.StreamDesc{}, cc, method, streamer)
+	}
+	assert.Equal(t, breaker.ErrServiceUnavailable, err)
+}
@@ -31,3 +31,23 @@ func DurationInterceptor(ctx context.Context, method string, req, replay interfa
 
 	return err
 }
+
+// StreamDurationInterceptor rpc流式调用时长拦截器，记录建立失败和异常结束的流
+func StreamDurationInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	serviceName := path.Join(cc.Target(), method)
+	startTime := timex.Now()
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		logx.WithContext(ctx).WithDuration(timex.Since(startTime)).Errorf("[RPC] 建流失败 - %s - %s",
+			serviceName, err.Error())
+		return nil, err
+	}
+
+	return newFinishStream(ctx, stream, desc, func(err error) {
+		if err != nil {
+			logx.WithContext(ctx).WithDuration(timex.Since(startTime)).Errorf("[RPC] 流失败 - %s - %s",
+				serviceName, err.Error())
+		}
+	}), nil
+}
@@ -30,6 +30,36 @@ var (
 		Help:      "RPC客户端请求响应码计数器。",
 		Labels:    []string{"method", "code"},
 	})
+
+	metricClientStreamDur = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: clientNamespace,
+		Subsystem: "streams",
+		Name:      "duration_ms",
+		Help:      "RPC客户端流持续时长（毫秒）。",
+		Labels:    []string{"method"},
+		Buckets:   []float64{100, 1000, 10000, 60000, 300000, 1800000, 3600000},
+	})
+
+	metricClientStreamCodeTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: clientNamespace,
+		Subsystem: "streams",
+		Name:      "code_total",
+		Help:      "RPC客户端流响应码计数器。",
+		Labels:    []string{"method", "code"},
+	})
+
+	metricClientStreamMsgTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: clientNamespace,
+		Subsystem: "streams",
+		Name:      "msg_total",
+		Help:      "RPC客户端流消息计数器。",
+		Labels:    []string{"method", "direction"},
+	})
+)
+
+const (
+	directionRecv = "recv"
+	directionSend = "send"
 )
 
 func PrometheusInterceptor(ctx context.Context, method string, req, reply interface{},
@@ -44,3 +74,46 @@ func PrometheusInterceptor(ctx context.Context, method string, req, reply interf
 	metricClientReqCodeTotal.Inc(method, strconv.Itoa(int(status.Code(err))))
 	return err
 }
+
+// StreamPrometheusInterceptor 统计rpc客户端流的持续时长、状态代码和收发消息数
+func StreamPrometheusInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if !prometheus.Enabled() {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	startTime := timex.Now()
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		metricClientStreamDur.Observe(int64(timex.Since(startTime)/time.Millisecond), method)
+		metricClientStreamCodeTotal.Inc(method, strconv.Itoa(int(status.Code(err))))
+		return nil, err
+	}
+
+	return newFinishStream(ctx, &metricStream{ClientStream: stream, method: method}, desc, func(err error) {
+		metricClientStreamDur.Observe(int64(timex.Since(startTime)/time.Millisecond), method)
+		metricClientStreamCodeTotal.Inc(method, strconv.Itoa(int(status.Code(err))))
+	}), nil
+}
+
+// metricStream 统计收发消息数的客户端流
+type metricStream struct {
+	grpc.ClientStream
+	method string
+}
+
+func (s *metricStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		metricClientStreamMsgTotal.Inc(s.method, directionRecv)
+	}
+	return err
+}
+
+func (s *metricStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		metricClientStreamMsgTotal.Inc(s.method, directionSend)
+	}
+	return err
+}
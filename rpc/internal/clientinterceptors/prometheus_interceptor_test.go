@@ -6,6 +6,7 @@ import (
 	"git.zc0901.com/go/god/lib/prometheus"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"io"
 	"testing"
 )
 
@@ -45,3 +46,47 @@ func TestPromMetricInterceptor(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamPromMetricInterceptor(t *testing.T) {
+	tests := []struct {
+		name    string
+		enable  bool
+		recvErr error
+	}{
+		{
+			name:    "eof",
+			enable:  true,
+			recvErr: io.EOF,
+		},
+		{
+			name:    "with error",
+			enable:  true,
+			recvErr: errors.New("mock"),
+		},
+		{
+			name:    "disabled",
+			recvErr: io.EOF,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.enable {
+				prometheus.StartAgent(prometheus.Config{
+					Host: "localhost",
+					Path: "/",
+				})
+			}
+			cc := new(grpc.ClientConn)
+			mocked := &mockedStream{recvErr: test.recvErr}
+			stream, err := StreamPrometheusInterceptor(context.Background(), &grpc.StreamDesc{}, cc, "/foo",
+				func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+					opts ...grpc.CallOption) (grpc.ClientStream, error) {
+					return mocked, nil
+				})
+			assert.Nil(t, err)
+			assert.Nil(t, stream.SendMsg(nil))
+			assert.Equal(t, test.recvErr, stream.RecvMsg(nil))
+			assert.Equal(t, 1, mocked.sent)
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package clientinterceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"git.zc0901.com/go/god/lib/collection"
+	"git.zc0901.com/go/god/lib/prometheus"
+	"git.zc0901.com/go/god/lib/prometheus/metric"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRetryBackoff    = 50 * time.Millisecond
+	defaultRetryMaxBackoff = time.Second
+	defaultHedgingDelay    = 100 * time.Millisecond
+	defaultBudgetRatio     = 0.1
+
+	// 重试预算按10秒的滚动窗口统计，窗口内至少允许 minRetriesPerWindow 次重试
+	budgetBuckets       = 10
+	budgetBucketTime    = time.Second
+	minRetriesPerWindow = 10
+
+	retryKind     = "retry"
+	hedgeKind     = "hedge"
+	throttledKind = "throttled"
+)
+
+var (
+	defaultRetryCodes = map[codes.Code]bool{codes.Unavailable: true}
+
+	metricClientRetryTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: clientNamespace,
+		Subsystem: "requests",
+		Name:      "retry_total",
+		Help:      "RPC客户端重试计数器，kind 为 retry、hedge 或 throttled（超出重试预算）。",
+		Labels:    []string{"method", "kind"},
+	})
+)
+
+type (
+	// RetryConf 客户端重试配置，MaxAttempts <= 1 且未配置方法时不重试
+	RetryConf struct {
+		MaxAttempts int               `json:",optional"` // 最大尝试次数（含首次调用）
+		Backoff     int64             `json:",optional"` // 首次重试前的退避时长（毫秒），默认50，之后逐次翻倍并加随机抖动
+		MaxBackoff  int64             `json:",optional"` // 最大退避时长（毫秒），默认1000
+		Codes       []string          `json:",optional"` // 可重试的状态码，如 Unavailable，默认仅 Unavailable
+		BudgetRatio float64           `json:",optional"` // 重试预算，即重试数与请求数的最大比例，默认0.1，<0则不限制
+		Methods     []MethodRetryConf `json:",optional"` // 按方法覆盖的重试配置
+	}
+
+	// MethodRetryConf 按方法覆盖的重试配置，未设置的字段沿用 RetryConf
+	MethodRetryConf struct {
+		Method       string   // 完整方法名，如 /user.User/GetUser，或服务名，如 /user.User，匹配其全部方法
+		MaxAttempts  int      `json:",optional"` // 最大尝试次数（含首次调用）
+		Codes        []string `json:",optional"` // 可重试的状态码
+		Hedging      bool     `json:",optional"` // 是否对冲请求，仅用于幂等方法
+		HedgingDelay int64    `json:",optional"` // 对冲请求的发送间隔（毫秒），默认100
+	}
+
+	retryPolicy struct {
+		maxAttempts  int
+		codes        map[codes.Code]bool
+		hedging      bool
+		hedgingDelay time.Duration
+	}
+
+	retrier struct {
+		backoff    time.Duration
+		maxBackoff time.Duration
+		policy     retryPolicy
+		methods    map[string]retryPolicy
+		budget     *retryBudget
+	}
+
+	// retryBudget 重试预算，限制滚动窗口内的重试数不超过请求数的一定比例，以免重试风暴
+	retryBudget struct {
+		ratio    float64
+		requests *collection.RollingWindow
+		retries  *collection.RollingWindow
+	}
+
+	attemptResult struct {
+		reply interface{}
+		err   error
+	}
+)
+
+// Validate 验证重试配置中的状态码是否有效
+func (c RetryConf) Validate() error {
+	if _, err := parseCodes(c.Codes); err != nil {
+		return err
+	}
+
+	for _, m := range c.Methods {
+		if len(m.Method) == 0 {
+			return errors.New("重试配置缺少方法名")
+		}
+		if _, err := parseCodes(m.Codes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RetryInterceptor 重试拦截器，对可重试的错误按指数退避加随机抖动重试，
+// 配置了对冲的方法在间隔内未返回时并发发送对冲请求，以先返回的结果为准。
+func RetryInterceptor(c RetryConf) grpc.UnaryClientInterceptor {
+	r := newRetrier(c)
+	if r == nil {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy := r.policyOf(method)
+		if policy.maxAttempts <= 1 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		r.budget.addRequest()
+		if _, ok := reply.(proto.Message); ok && policy.hedging {
+			return r.hedge(ctx, policy, method, req, reply, cc, invoker, opts...)
+		}
+
+		return r.retry(ctx, policy, method, req, reply, cc, invoker, opts...)
+	}
+}
+
+func newRetrier(c RetryConf) *retrier {
+	if c.MaxAttempts <= 1 && len(c.Methods) == 0 {
+		return nil
+	}
+
+	retryCodes, err := parseCodes(c.Codes)
+	if err != nil || len(retryCodes) == 0 {
+		retryCodes = defaultRetryCodes
+	}
+
+	r := &retrier{
+		backoff:    durationOf(c.Backoff, defaultRetryBackoff),
+		maxBackoff: durationOf(c.MaxBackoff, defaultRetryMaxBackoff),
+		policy: retryPolicy{
+			maxAttempts: c.MaxAttempts,
+			codes:       retryCodes,
+		},
+		methods: make(map[string]retryPolicy),
+		budget:  newRetryBudget(c.BudgetRatio),
+	}
+	for _, m := range c.Methods {
+		policy := r.policy
+		if m.MaxAttempts > 0 {
+			policy.maxAttempts = m.MaxAttempts
+		}
+		if methodCodes, err := parseCodes(m.Codes); err == nil && len(methodCodes) > 0 {
+			policy.codes = methodCodes
+		}
+		policy.hedging = m.Hedging
+		policy.hedgingDelay = durationOf(m.HedgingDelay, defaultHedgingDelay)
+		r.methods[m.Method] = policy
+	}
+
+	return r
+}
+
+// policyOf 返回方法的重试策略，方法配置优先于服务配置
+func (r *retrier) policyOf(method string) retryPolicy {
+	if policy, ok := r.methods[method]; ok {
+		return policy
+	}
+
+	if pos := strings.LastIndexByte(method, '/'); pos > 0 {
+		if policy, ok := r.methods[method[:pos]]; ok {
+			return policy
+		}
+	}
+
+	return r.policy
+}
+
+func (r *retrier) retry(ctx context.Context, policy retryPolicy, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil || attempt >= policy.maxAttempts || !policy.retryable(err) {
+			return err
+		}
+		if !r.budget.allow() {
+			reportRetry(method, throttledKind)
+			return err
+		}
+
+		select {
+		case <-time.After(r.backoffOf(attempt)):
+		case <-ctx.Done():
+			return err
+		}
+
+		r.budget.addRetry()
+		reportRetry(method, retryKind)
+	}
+}
+
+// hedge 每隔 hedgingDelay 发送一次请求，直到有请求成功或返回不可重试的错误，
+// 或全部请求都已失败。每个请求使用独立的 reply，最终结果合并到 reply 中。
+func (r *retrier) hedge(ctx context.Context, policy retryPolicy, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan attemptResult, policy.maxAttempts)
+	send := func() {
+		attemptReply := proto.Clone(reply.(proto.Message))
+		attemptReply.Reset()
+		go func() {
+			err := invoker(ctx, method, req, attemptReply, cc, opts...)
+			results <- attemptResult{reply: attemptReply, err: err}
+		}()
+	}
+
+	send()
+	sent, done := 1, 0
+	timer := time.NewTimer(policy.hedgingDelay)
+	defer timer.Stop()
+
+	var err error
+	for {
+		select {
+		case <-timer.C:
+			if sent >= policy.maxAttempts {
+				continue
+			}
+			if !r.budget.allow() {
+				reportRetry(method, throttledKind)
+				continue
+			}
+
+			r.budget.addRetry()
+			reportRetry(method, hedgeKind)
+			send()
+			sent++
+			timer.Reset(policy.hedgingDelay)
+		case result := <-results:
+			done++
+			err = result.err
+			if err == nil || !policy.retryable(err) {
+				if err == nil {
+					reply.(proto.Message).Reset()
+					proto.Merge(reply.(proto.Message), result.reply.(proto.Message))
+				}
+				return err
+			}
+			if done >= policy.maxAttempts {
+				return err
+			}
+			// 有请求失败时立即发送下一个对冲请求
+			if done >= sent {
+				if !r.budget.allow() {
+					reportRetry(method, throttledKind)
+					return err
+				}
+
+				r.budget.addRetry()
+				reportRetry(method, hedgeKind)
+				send()
+				sent++
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(policy.hedgingDelay)
+			}
+		case <-ctx.Done():
+			if err == nil {
+				err = status.FromContextError(ctx.Err()).Err()
+			}
+			return err
+		}
+	}
+}
+
+// backoffOf 返回第 attempt 次调用失败后的退避时长，在指数退避时长的 1/2 到 1 倍之间随机取值
+func (r *retrier) backoffOf(attempt int) time.Duration {
+	backoff := float64(r.backoff) * math.Pow(2, float64(attempt-1))
+	if backoff > float64(r.maxBackoff) {
+		backoff = float64(r.maxBackoff)
+	}
+
+	return time.Duration(backoff/2 + rand.Float64()*backoff/2)
+}
+
+func (p retryPolicy) retryable(err error) bool {
+	return p.codes[status.Code(err)]
+}
+
+func newRetryBudget(ratio float64) *retryBudget {
+	if ratio < 0 {
+		return nil
+	}
+	if ratio == 0 {
+		ratio = defaultBudgetRatio
+	}
+
+	return &retryBudget{
+		ratio:    ratio,
+		requests: collection.NewRollingWindow(budgetBuckets, budgetBucketTime),
+		retries:  collection.NewRollingWindow(budgetBuckets, budgetBucketTime),
+	}
+}
+
+func (b *retryBudget) addRequest() {
+	if b != nil {
+		b.requests.Add(1)
+	}
+}
+
+func (b *retryBudget) addRetry() {
+	if b != nil {
+		b.retries.Add(1)
+	}
+}
+
+// allow 判断是否还有重试预算
+func (b *retryBudget) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	var requests, retries int64
+	b.requests.Reduce(func(bucket *collection.Bucket) {
+		requests += bucket.Requests
+	})
+	b.retries.Reduce(func(bucket *collection.Bucket) {
+		retries += bucket.Requests
+	})
+
+	return float64(retries) < math.Max(minRetriesPerWindow, b.ratio*float64(requests))
+}
+
+var codeNames struct {
+	once  sync.Once
+	codes map[string]codes.Code
+}
+
+// parseCodes 解析状态码名称，不区分大小写和下划线，如 Unavailable、UNAVAILABLE、deadline_exceeded
+func parseCodes(names []string) (map[codes.Code]bool, error) {
+	codeNames.once.Do(func() {
+		codeNames.codes = make(map[string]codes.Code)
+		for c := codes.OK; c <= codes.Unauthenticated; c++ {
+			codeNames.codes[normalizeCode(c.String())] = c
+		}
+	})
+
+	result := make(map[codes.Code]bool)
+	for _, name := range names {
+		c, ok := codeNames.codes[normalizeCode(name)]
+		if !ok {
+			return nil, fmt.Errorf("无效的重试状态码: %s", name)
+		}
+		result[c] = true
+	}
+
+	return result, nil
+}
+
+func normalizeCode(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+func durationOf(ms int64, defaultDuration time.Duration) time.Duration {
+	if ms <= 0 {
+		return defaultDuration
+	}
+
+	return time.Duration(ms) * time.Millisecond
+}
+
+func reportRetry(method, kind string) {
+	if prometheus.Enabled() {
+		metricClientRetryTotal.Inc(method, kind)
+	}
+}
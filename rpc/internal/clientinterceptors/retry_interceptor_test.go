@@ -0,0 +1,196 @@
+package clientinterceptors
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.zc0901.com/go/god/rpc/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   RetryConf
+		method string
+		errs   []error
+		expect codes.Code
+		calls  int32
+	}{
+		{
+			name:   "disabled",
+			errs:   []error{status.Error(codes.Unavailable, "mock")},
+			expect: codes.Unavailable,
+			calls:  1,
+		},
+		{
+			name:   "success after retry",
+			conf:   RetryConf{MaxAttempts: 3, Backoff: 1},
+			errs:   []error{status.Error(codes.Unavailable, "mock"), nil},
+			expect: codes.OK,
+			calls:  2,
+		},
+		{
+			name: "exhausted",
+			conf: RetryConf{MaxAttempts: 3, Backoff: 1},
+			errs: []error{
+				status.Error(codes.Unavailable, "mock"),
+				status.Error(codes.Unavailable, "mock"),
+				status.Error(codes.Unavailable, "mock"),
+			},
+			expect: codes.Unavailable,
+			calls:  3,
+		},
+		{
+			name:   "not retryable",
+			conf:   RetryConf{MaxAttempts: 3, Backoff: 1},
+			errs:   []error{status.Error(codes.InvalidArgument, "mock"), nil},
+			expect: codes.InvalidArgument,
+			calls:  1,
+		},
+		{
+			name:   "custom codes",
+			conf:   RetryConf{MaxAttempts: 3, Backoff: 1, Codes: []string{"RESOURCE_EXHAUSTED"}},
+			errs:   []error{status.Error(codes.ResourceExhausted, "mock"), nil},
+			expect: codes.OK,
+			calls:  2,
+		},
+		{
+			name: "method override",
+			conf: RetryConf{MaxAttempts: 3, Backoff: 1, Methods: []MethodRetryConf{
+				{Method: "/foo.Foo/Bar", MaxAttempts: 1},
+			}},
+			method: "/foo.Foo/Bar",
+			errs:   []error{status.Error(codes.Unavailable, "mock"), nil},
+			expect: codes.Unavailable,
+			calls:  1,
+		},
+		{
+			name: "service override",
+			conf: RetryConf{Backoff: 1, Methods: []MethodRetryConf{
+				{Method: "/foo.Foo", MaxAttempts: 2},
+			}},
+			method: "/foo.Foo/Bar",
+			errs:   []error{status.Error(codes.Unavailable, "mock"), nil},
+			expect: codes.OK,
+			calls:  2,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			method := test.method
+			if len(method) == 0 {
+				method = "/foo"
+			}
+
+			var calls int32
+			interceptor := RetryInterceptor(test.conf)
+			err := interceptor(context.Background(), method, nil, nil, new(grpc.ClientConn),
+				func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+					opts ...grpc.CallOption) error {
+					call := atomic.AddInt32(&calls, 1)
+					return test.errs[call-1]
+				})
+			assert.Equal(t, test.expect, status.Code(err))
+			assert.Equal(t, test.calls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestRetryInterceptor_Budget(t *testing.T) {
+	interceptor := RetryInterceptor(RetryConf{MaxAttempts: 2, Backoff: 1})
+	var calls int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		opts ...grpc.CallOption) error {
+		atomic.AddInt32(&calls, 1)
+		return status.Error(codes.Unavailable, "mock")
+	}
+
+	for i := 0; i < minRetriesPerWindow*2; i++ {
+		_ = interceptor(context.Background(), "/foo", nil, nil, new(grpc.ClientConn), invoker)
+	}
+	// 请求数较少时，窗口内最多允许 minRetriesPerWindow 次重试
+	assert.Equal(t, int32(minRetriesPerWindow*3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryInterceptor_ContextDone(t *testing.T) {
+	interceptor := RetryInterceptor(RetryConf{MaxAttempts: 3, Backoff: 1000})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	var calls int32
+	err := interceptor(ctx, "/foo", nil, nil, new(grpc.ClientConn),
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.Unavailable, "mock")
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryInterceptor_Hedging(t *testing.T) {
+	interceptor := RetryInterceptor(RetryConf{Methods: []MethodRetryConf{
+		{Method: "/foo", MaxAttempts: 3, Hedging: true, HedgingDelay: 10},
+	}})
+
+	var calls int32
+	reply := new(mock.DepositResponse)
+	err := interceptor(context.Background(), "/foo", nil, reply, new(grpc.ClientConn),
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			// 首个请求阻塞，由对冲请求返回结果
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-ctx.Done()
+				return status.FromContextError(ctx.Err()).Err()
+			}
+
+			reply.(*mock.DepositResponse).Ok = true
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.True(t, reply.Ok)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRetryInterceptor_HedgingFailed(t *testing.T) {
+	interceptor := RetryInterceptor(RetryConf{Methods: []MethodRetryConf{
+		{Method: "/foo", MaxAttempts: 3, Hedging: true, HedgingDelay: 1000},
+	}})
+
+	var calls int32
+	start := time.Now()
+	err := interceptor(context.Background(), "/foo", nil, new(mock.DepositResponse), new(grpc.ClientConn),
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.Unavailable, "mock")
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	// 请求失败时立即发送下一个对冲请求，无需等待对冲间隔
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestRetryConf_Validate(t *testing.T) {
+	assert.Nil(t, RetryConf{Codes: []string{"Unavailable", "deadline_exceeded"}}.Validate())
+	assert.NotNil(t, RetryConf{Codes: []string{"Unknown code"}}.Validate())
+	assert.NotNil(t, RetryConf{Methods: []MethodRetryConf{{MaxAttempts: 2}}}.Validate())
+	assert.NotNil(t, RetryConf{Methods: []MethodRetryConf{{Method: "/foo", Codes: []string{"bad"}}}}.Validate())
+}
+
+func TestRetrier_Backoff(t *testing.T) {
+	r := newRetrier(RetryConf{MaxAttempts: 5, Backoff: 100, MaxBackoff: 300})
+	for attempt, max := range []time.Duration{100, 200, 300, 300} {
+		backoff := r.backoffOf(attempt + 1)
+		assert.True(t, backoff >= max*time.Millisecond/2)
+		assert.True(t, backoff <= max*time.Millisecond)
+	}
+}
@@ -0,0 +1,32 @@
+package clientinterceptors
+
+import (
+	"context"
+
+	"git.zc0901.com/go/god/rpc/internal/balancer/p2c"
+	"google.golang.org/grpc"
+)
+
+// RouteInterceptor 路由拦截器，把客户端配置的路由条件加入请求上下文，请求中已设置的条件优先
+func RouteInterceptor(route p2c.Route) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withRoute(ctx, route), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamRouteInterceptor 流式路由拦截器，把客户端配置的路由条件加入请求上下文，请求中已设置的条件优先
+func StreamRouteInterceptor(route p2c.Route) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withRoute(ctx, route), desc, cc, method, opts...)
+	}
+}
+
+func withRoute(ctx context.Context, route p2c.Route) context.Context {
+	if current, ok := p2c.RouteFromContext(ctx); ok {
+		route = current.Merge(route)
+	}
+
+	return p2c.WithRoute(ctx, route)
+}
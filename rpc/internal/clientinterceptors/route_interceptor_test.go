@@ -0,0 +1,37 @@
+package clientinterceptors
+
+import (
+	"context"
+	"testing"
+
+	"git.zc0901.com/go/god/rpc/internal/balancer/p2c"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestRouteInterceptor(t *testing.T) {
+	interceptor := RouteInterceptor(p2c.Route{Zone: "az1", Version: "v1"})
+	ctx := p2c.WithRoute(context.Background(), p2c.Route{Version: "v2"})
+	err := interceptor(ctx, "/foo", nil, nil, new(grpc.ClientConn),
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			route, ok := p2c.RouteFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, p2c.Route{Zone: "az1", Version: "v2"}, route)
+			return nil
+		})
+	assert.Nil(t, err)
+}
+
+func TestStreamRouteInterceptor(t *testing.T) {
+	interceptor := StreamRouteInterceptor(p2c.Route{Zone: "az1"})
+	_, err := interceptor(context.Background(), &grpc.StreamDesc{}, new(grpc.ClientConn), "/foo",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+			opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			route, ok := p2c.RouteFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, "az1", route.Zone)
+			return nil, nil
+		})
+	assert.Nil(t, err)
+}
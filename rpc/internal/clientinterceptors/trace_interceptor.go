@@ -25,3 +25,26 @@ func UnaryTraceInterceptor(ctx context.Context, method string, req, reply interf
 
 	return invoker(ctx, method, req, reply, cc, opts...)
 }
+
+// StreamTraceInterceptor rpc客户端流式链路追踪拦截器，流结束时完成跟踪操作
+func StreamTraceInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, span := trace.StartClientSpan(ctx, cc.Target(), method)
+
+	var pairs []string
+	span.Visit(func(key, value string) bool {
+		pairs = append(pairs, key, value)
+		return true
+	})
+	ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	return newFinishStream(ctx, stream, desc, func(error) {
+		span.Finish()
+	}), nil
+}
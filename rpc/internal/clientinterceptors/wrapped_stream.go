@@ -0,0 +1,65 @@
+package clientinterceptors
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// finishStream 在流结束时回调 finish 的客户端流。
+// RecvMsg 返回错误即表示流结束，io.EOF 表示流正常结束，回调时错误为 nil；
+// 服务端非流式时（如客户端流），成功收到唯一的响应也表示流结束；
+// 调用方取消 ctx 或 ctx 超时而未读完流时，以 ctx 的错误结束。
+type finishStream struct {
+	grpc.ClientStream
+	desc     *grpc.StreamDesc
+	once     sync.Once
+	finish   func(err error)
+	finished chan struct{}
+}
+
+// newFinishStream 返回在流结束时回调 finish 的客户端流，ctx 为建流时调用方的上下文。
+// 流正常结束后 grpc 也会取消 stream.Context()，因此监听调用方的 ctx，以免把正常结束的流记为取消。
+func newFinishStream(ctx context.Context, stream grpc.ClientStream, desc *grpc.StreamDesc,
+	finish func(err error)) *finishStream {
+	s := &finishStream{
+		ClientStream: stream,
+		desc:         desc,
+		finish:       finish,
+		finished:     make(chan struct{}),
+	}
+
+	if done := ctx.Done(); done != nil {
+		go func() {
+			select {
+			case <-done:
+				s.done(status.FromContextError(ctx.Err()).Err())
+			case <-s.finished:
+			}
+		}()
+	}
+
+	return s
+}
+
+func (s *finishStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.desc.ServerStreams {
+		s.done(err)
+	}
+	return err
+}
+
+func (s *finishStream) done(err error) {
+	if err == io.EOF {
+		err = nil
+	}
+
+	s.once.Do(func() {
+		close(s.finished)
+		s.finish(err)
+	})
+}
@@ -0,0 +1,134 @@
+package clientinterceptors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestFinishStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect error
+	}{
+		{
+			name: "eof",
+			err:  io.EOF,
+		},
+		{
+			name:   "error",
+			err:    errors.New("mock"),
+			expect: errors.New("mock"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var finished int
+			var finishErr error
+			desc := &grpc.StreamDesc{ServerStreams: true}
+			stream := newFinishStream(context.Background(), &mockedStream{recvErr: test.err}, desc, func(err error) {
+				finished++
+				finishErr = err
+			})
+			assert.Equal(t, test.err, stream.RecvMsg(nil))
+			assert.Equal(t, test.err, stream.RecvMsg(nil))
+			assert.Equal(t, 1, finished)
+			assert.Equal(t, test.expect, finishErr)
+		})
+	}
+}
+
+func TestFinishStream_ClientStreams(t *testing.T) {
+	var finished int
+	var finishErr error
+	desc := &grpc.StreamDesc{ClientStreams: true}
+	stream := newFinishStream(context.Background(), &mockedStream{}, desc, func(err error) {
+		finished++
+		finishErr = err
+	})
+	assert.Nil(t, stream.SendMsg(nil))
+	assert.Equal(t, 0, finished)
+	// 客户端流 CloseAndRecv 成功收到响应即结束
+	assert.Nil(t, stream.RecvMsg(nil))
+	assert.Equal(t, 1, finished)
+	assert.Nil(t, finishErr)
+}
+
+func TestFinishStream_ContextDone(t *testing.T) {
+	var finished int32
+	errCh := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	desc := &grpc.StreamDesc{ServerStreams: true}
+	stream := newFinishStream(ctx, &mockedStream{}, desc, func(err error) {
+		atomic.AddInt32(&finished, 1)
+		errCh <- err
+	})
+	// 未读完流即取消，也要结束
+	assert.Nil(t, stream.RecvMsg(nil))
+	cancel()
+	select {
+	case err := <-errCh:
+		assert.Equal(t, codes.Canceled, status.Code(err))
+	case <-time.After(time.Second):
+		t.Fatal("流未结束")
+	}
+
+	stream.done(io.EOF)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+}
+
+func TestFinishStream_CancelAfterFinish(t *testing.T) {
+	var finished int32
+	ctx, cancel := context.WithCancel(context.Background())
+	desc := &grpc.StreamDesc{ServerStreams: true}
+	stream := newFinishStream(ctx, &mockedStream{recvErr: io.EOF}, desc, func(err error) {
+		atomic.AddInt32(&finished, 1)
+		assert.Nil(t, err)
+	})
+	assert.Equal(t, io.EOF, stream.RecvMsg(nil))
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+}
+
+type mockedStream struct {
+	ctx     context.Context
+	recvErr error
+	sent    int
+}
+
+func (m *mockedStream) Header() (metadata.MD, error) {
+	return nil, nil
+}
+
+func (m *mockedStream) Trailer() metadata.MD {
+	return nil
+}
+
+func (m *mockedStream) CloseSend() error {
+	return nil
+}
+
+func (m *mockedStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockedStream) SendMsg(v interface{}) error {
+	m.sent++
+	return nil
+}
+
+func (m *mockedStream) RecvMsg(v interface{}) error {
+	return m.recvErr
+}
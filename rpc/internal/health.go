@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"git.zc0901.com/go/god/lib/lang"
+	"git.zc0901.com/go/god/lib/proc"
+	"git.zc0901.com/go/god/lib/threading"
+	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// healthCheckInterval 检查过载状态的间隔
+const healthCheckInterval = time.Second
+
+type healthServer struct {
+	*health.Server
+	services   []string
+	overloaded func() bool
+	serving    bool
+	once       sync.Once
+	done       chan lang.PlaceholderType
+}
+
+// RegisterHealth 在 server 上注册 grpc.health.v1 健康检查服务，整体服务（空服务名）和已注册的各服务均报告 SERVING。
+// overloaded 不为 nil 且返回 true 期间报告 NOT_SERVING，优雅停机开始后始终报告 NOT_SERVING。
+// 需在注册完业务服务后调用。
+func RegisterHealth(server *grpc.Server, overloaded func() bool) {
+	hs := newHealthServer(server, overloaded)
+	healthpb.RegisterHealthServer(server, hs)
+
+	proc.AddWrapUpListener(hs.shutdown)
+	if overloaded != nil {
+		threading.GoSafe(hs.watch)
+	}
+}
+
+// RegisterReflection 在 server 上注册服务反射，便于 grpcurl 等工具调试
+func RegisterReflection(server *grpc.Server) {
+	reflection.Register(server)
+}
+
+// RegisterChannelz 在 server 上注册 channelz 服务，用于查看连接和调用的运行时状态
+func RegisterChannelz(server *grpc.Server) {
+	channelz.RegisterChannelzServiceToServer(server)
+}
+
+func newHealthServer(server *grpc.Server, overloaded func() bool) *healthServer {
+	hs := &healthServer{
+		Server:     health.NewServer(),
+		services:   []string{""},
+		overloaded: overloaded,
+		serving:    true,
+		done:       make(chan lang.PlaceholderType),
+	}
+	for name := range server.GetServiceInfo() {
+		hs.services = append(hs.services, name)
+	}
+	hs.setServing(true)
+
+	return hs
+}
+
+func (hs *healthServer) watch() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			hs.check()
+		case <-hs.done:
+			return
+		}
+	}
+}
+
+// check 根据过载状态切换服务状态
+func (hs *healthServer) check() {
+	serving := !hs.overloaded()
+	if serving != hs.serving {
+		hs.serving = serving
+		hs.setServing(serving)
+	}
+}
+
+func (hs *healthServer) setServing(serving bool) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if !serving {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	for _, service := range hs.services {
+		hs.SetServingStatus(service, status)
+	}
+}
+
+// shutdown 将所有服务置为 NOT_SERVING，并忽略此后的状态变更
+func (hs *healthServer) shutdown() {
+	hs.once.Do(func() {
+		close(hs.done)
+		hs.Shutdown()
+	})
+}
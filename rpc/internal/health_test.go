@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"git.zc0901.com/go/god/lib/syncx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthServer(t *testing.T) {
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "mock.Greeter",
+		HandlerType: (*interface{})(nil),
+	}, nil)
+
+	overloaded := syncx.NewAtomicBool()
+	hs := newHealthServer(server, overloaded.True)
+	assertStatus(t, hs, "", healthpb.HealthCheckResponse_SERVING)
+	assertStatus(t, hs, "mock.Greeter", healthpb.HealthCheckResponse_SERVING)
+
+	overloaded.Set(true)
+	hs.check()
+	assertStatus(t, hs, "", healthpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus(t, hs, "mock.Greeter", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	overloaded.Set(false)
+	hs.check()
+	assertStatus(t, hs, "mock.Greeter", healthpb.HealthCheckResponse_SERVING)
+
+	hs.shutdown()
+	hs.shutdown()
+	hs.check()
+	assertStatus(t, hs, "", healthpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus(t, hs, "mock.Greeter", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	_, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.NotNil(t, err)
+}
+
+func assertStatus(t *testing.T, hs *healthServer, service string, expect healthpb.HealthCheckResponse_ServingStatus) {
+	resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	assert.Nil(t, err)
+	assert.Equal(t, expect, resp.Status)
+}
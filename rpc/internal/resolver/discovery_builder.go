@@ -22,8 +22,8 @@ func (b *discoveryBuilder) Build(target resolver.Target, conn resolver.ClientCon
 
 	update := func() {
 		var addrs []resolver.Address
-		for _, addr := range subset(subscriber.Values(), subsetSize) {
-			addrs = append(addrs, resolver.Address{Addr: addr})
+		for _, value := range subset(subscriber.Values(), subsetSize) {
+			addrs = append(addrs, newAddress(value))
 		}
 		conn.UpdateState(resolver.State{Addresses: addrs})
 	}
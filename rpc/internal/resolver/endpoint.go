@@ -0,0 +1,31 @@
+package resolver
+
+import (
+	"git.zc0901.com/go/god/lib/discovery"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+)
+
+// endpointKey 服务节点元数据在 resolver.Address 属性中的键
+type endpointKey struct{}
+
+// newAddress 把发布的服务节点转为 resolver.Address，节点元数据存放在属性中
+func newAddress(value string) resolver.Address {
+	endpoint := discovery.ParseEndpoint(value)
+	addr := resolver.Address{Addr: endpoint.Addr}
+	if !endpoint.IsZero() {
+		addr.Attributes = attributes.New(endpointKey{}, endpoint.EndpointMeta)
+	}
+
+	return addr
+}
+
+// EndpointMeta 返回 resolver.Address 中的服务节点元数据
+func EndpointMeta(addr resolver.Address) (discovery.EndpointMeta, bool) {
+	if addr.Attributes == nil {
+		return discovery.EndpointMeta{}, false
+	}
+
+	meta, ok := addr.Attributes.Value(endpointKey{}).(discovery.EndpointMeta)
+	return meta, ok
+}
@@ -0,0 +1,21 @@
+package resolver
+
+import (
+	"testing"
+
+	"git.zc0901.com/go/god/lib/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAddress(t *testing.T) {
+	addr := newAddress("10.0.0.1:8080")
+	assert.Equal(t, "10.0.0.1:8080", addr.Addr)
+	_, ok := EndpointMeta(addr)
+	assert.False(t, ok)
+
+	addr = newAddress(`{"Addr":"10.0.0.2:8080","Zone":"az1","Weight":50}`)
+	assert.Equal(t, "10.0.0.2:8080", addr.Addr)
+	meta, ok := EndpointMeta(addr)
+	assert.True(t, ok)
+	assert.Equal(t, discovery.EndpointMeta{Zone: "az1", Weight: 50}, meta)
+}
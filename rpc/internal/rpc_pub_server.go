@@ -13,9 +13,17 @@ const (
 )
 
 func NewPubServer(etcdEndpoints []string, etcdKey, listenOn string, opts ...ServerOption) (Server, error) {
+	var options serverOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	registerEtcd := func() error {
-		listenOn := figureOutListenOn(listenOn)
-		pubClient := discovery.NewPublisher(etcdEndpoints, etcdKey, listenOn)
+		endpoint := discovery.Endpoint{
+			Addr:         figureOutListenOn(listenOn),
+			EndpointMeta: options.meta,
+		}
+		pubClient := discovery.NewPublisher(etcdEndpoints, etcdKey, endpoint.String())
 		return pubClient.KeepAlive()
 	}
 	server := keepAliveServer{
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"git.zc0901.com/go/god/lib/discovery"
 	"git.zc0901.com/go/god/lib/proc"
 	"git.zc0901.com/go/god/lib/stat"
 	"git.zc0901.com/go/god/rpc/internal/serverinterceptors"
@@ -11,6 +12,7 @@ import (
 type (
 	serverOptions struct {
 		metrics *stat.Metrics
+		meta    discovery.EndpointMeta
 	}
 
 	ServerOption func(options *serverOptions)
@@ -63,7 +65,10 @@ func (s *server) Start(register RegisterFn) error {
 
 	// 流式拦截器
 	streamInterceptors := []grpc.StreamServerInterceptor{
-		serverinterceptors.StreamCrashInterceptor,
+		serverinterceptors.StreamTraceInterceptor(s.name), // 链路跟踪
+		serverinterceptors.StreamCrashInterceptor,         // 异常捕获
+		serverinterceptors.StreamStatInterceptor(),        // 数据统计
+		serverinterceptors.StreamPrometheusInterceptor(),  // 监控报警
 	}
 	streamInterceptors = append(streamInterceptors, s.streamInterceptors...)
 
@@ -92,3 +97,11 @@ func WithMetrics(metrics *stat.Metrics) ServerOption {
 		options.metrics = metrics
 	}
 }
+
+// WithEndpointMeta 携带发布到服务发现的节点元数据。
+// 带元数据的节点以 JSON 格式发布，需先升级所有客户端再为服务端设置元数据。
+func WithEndpointMeta(meta discovery.EndpointMeta) ServerOption {
+	return func(options *serverOptions) {
+		options.meta = meta
+	}
+}
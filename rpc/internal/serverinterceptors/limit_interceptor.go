@@ -0,0 +1,50 @@
+package serverinterceptors
+
+import (
+	"context"
+
+	"git.zc0901.com/go/god/lib/limit"
+	"git.zc0901.com/go/god/lib/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LimitKeyFunc 从请求上下文中提取限流键，fullMethod 为被调用的完整方法名
+type LimitKeyFunc func(ctx context.Context, fullMethod string) string
+
+// UnaryPeriodLimitInterceptor 一元周期限流拦截器，超出配额返回 ResourceExhausted。
+// keyFunc 为空时按方法名限流，限流器出错时放行。
+func UnaryPeriodLimitInterceptor(limiter *limit.PeriodLimit, keyFunc LimitKeyFunc) grpc.UnaryServerInterceptor {
+	if keyFunc == nil {
+		keyFunc = methodKey
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		code, err := limiter.Take(keyFunc(ctx, info.FullMethod))
+		if err != nil {
+			logx.WithContext(ctx).Errorf("[RPC] 周期限流器出错，放行请求：%s", err)
+		} else if code == limit.OverQuota {
+			return nil, status.Error(codes.ResourceExhausted, "超出限流配额")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// UnaryTokenLimitInterceptor 一元令牌桶限流拦截器，令牌不足返回 ResourceExhausted。
+func UnaryTokenLimitInterceptor(limiter *limit.TokenLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if !limiter.Allow() {
+			return nil, status.Error(codes.ResourceExhausted, "令牌桶限流")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func methodKey(_ context.Context, fullMethod string) string {
+	return fullMethod
+}
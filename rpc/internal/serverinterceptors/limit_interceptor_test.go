@@ -0,0 +1,53 @@
+package serverinterceptors
+
+import (
+	"context"
+	"testing"
+
+	"git.zc0901.com/go/god/lib/limit"
+	"git.zc0901.com/go/god/lib/store/redis/redistest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryPeriodLimitInterceptor(t *testing.T) {
+	store, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	interceptor := UnaryPeriodLimitInterceptor(limit.NewPeriodLimit(60, 1, store, "rpc:limit:"), nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/foo"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := interceptor(context.Background(), nil, info, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	resp, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/bar"}, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestUnaryTokenLimitInterceptor(t *testing.T) {
+	store, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	interceptor := UnaryTokenLimitInterceptor(limit.NewTokenLimiter(1, 1, store, "rpc:token"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/foo"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Nil(t, err)
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
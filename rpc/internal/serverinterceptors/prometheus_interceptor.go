@@ -30,6 +30,36 @@ var (
 		Help:      "RPC服务端请求响应码计数器。",
 		Labels:    []string{"method", "code"},
 	})
+
+	metricServerStreamDur = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "duration_ms",
+		Help:      "RPC服务端流持续时长（毫秒）。",
+		Labels:    []string{"method"},
+		Buckets:   []float64{100, 1000, 10000, 60000, 300000, 1800000, 3600000},
+	})
+
+	metricServerStreamCodeTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "code_total",
+		Help:      "RPC服务端流响应码计数器。",
+		Labels:    []string{"method", "code"},
+	})
+
+	metricServerStreamMsgTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "msg_total",
+		Help:      "RPC服务端流消息计数器。",
+		Labels:    []string{"method", "direction"},
+	})
+)
+
+const (
+	directionRecv = "recv"
+	directionSend = "send"
 )
 
 // UnaryPrometheusInterceptor 统计rpc服务端请求时长和状态代码
@@ -46,3 +76,41 @@ func UnaryPrometheusInterceptor() grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+// StreamPrometheusInterceptor 统计rpc服务端流的持续时长、状态代码和收发消息数
+func StreamPrometheusInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		if !prometheus.Enabled() {
+			return handler(srv, stream)
+		}
+
+		startTime := timex.Now()
+		err := handler(srv, &metricStream{ServerStream: stream, method: info.FullMethod})
+		metricServerStreamDur.Observe(int64(timex.Since(startTime)/time.Millisecond), info.FullMethod)
+		metricServerStreamCodeTotal.Inc(info.FullMethod, strconv.Itoa(int(status.Code(err))))
+		return err
+	}
+}
+
+// metricStream 统计收发消息数的服务端流
+type metricStream struct {
+	grpc.ServerStream
+	method string
+}
+
+func (s *metricStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		metricServerStreamMsgTotal.Inc(s.method, directionRecv)
+	}
+	return err
+}
+
+func (s *metricStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		metricServerStreamMsgTotal.Inc(s.method, directionSend)
+	}
+	return err
+}
@@ -41,3 +41,18 @@ func TestUnaryPromMetricInterceptor_Enabled(t *testing.T) {
 	})
 	assert.Nil(t, err)
 }
+
+func TestStreamPromMetricInterceptor(t *testing.T) {
+	prometheus.StartAgent(prometheus.Config{
+		Host: "localhost",
+		Path: "/",
+	})
+	interceptor := StreamPrometheusInterceptor()
+	err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		assert.Nil(t, stream.RecvMsg(nil))
+		return stream.SendMsg(nil)
+	})
+	assert.Nil(t, err)
+}
@@ -45,6 +45,29 @@ func UnaryShedderInterceptor(shedder load.Shedder, metrics *stat.Metrics) grpc.U
 	}
 }
 
+// StreamShedderInterceptor 流式泄流拦截器，负载过高时拒绝建立新流。
+// 只在建立流时检查负载，流的存续时间不计入正在处理的请求数和响应时间，以免长连接的流导致一元请求被丢弃。
+func StreamShedderInterceptor(shedder load.Shedder, metrics *stat.Metrics) grpc.StreamServerInterceptor {
+	ensureShedderStat()
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		shedderStat.IncrTotal()
+
+		promise, err := shedder.Allow()
+		if err != nil {
+			metrics.AddDrop()
+			shedderStat.IncrDrop()
+			return err
+		}
+
+		shedderStat.IncrPass()
+		promise.Pass()
+
+		return handler(srv, stream)
+	}
+}
+
 func ensureShedderStat() {
 	lock.Lock()
 	if shedderStat == nil {
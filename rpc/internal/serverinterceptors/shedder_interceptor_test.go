@@ -8,6 +8,8 @@ import (
 	"git.zc0901.com/go/god/lib/stat"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestUnarySheddingInterceptor(t *testing.T) {
@@ -55,6 +57,86 @@ func TestUnarySheddingInterceptor(t *testing.T) {
 	}
 }
 
+func TestStreamSheddingInterceptor(t *testing.T) {
+	deadline := status.Error(codes.DeadlineExceeded, "deadline")
+	tests := []struct {
+		name      string
+		allow     bool
+		handleErr error
+		expect    error
+	}{
+		{
+			name:   "allow",
+			allow:  true,
+			expect: nil,
+		},
+		{
+			name:      "deadline",
+			allow:     true,
+			handleErr: deadline,
+			expect:    deadline,
+		},
+		{
+			name:   "reject",
+			allow:  false,
+			expect: load.ErrServiceOverloaded,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			shedder := mockedShedder{allow: test.allow}
+			metrics := stat.NewMetrics("mock")
+			interceptor := StreamShedderInterceptor(shedder, metrics)
+			err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+				FullMethod: "/",
+			}, func(srv interface{}, stream grpc.ServerStream) error {
+				return test.handleErr
+			})
+			assert.Equal(t, test.expect, err)
+		})
+	}
+}
+
+func TestStreamSheddingInterceptor_PassOnOpen(t *testing.T) {
+	promise := new(countedPromise)
+	interceptor := StreamShedderInterceptor(countedShedder{promise: promise}, stat.NewMetrics("mock"))
+	err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		// 建立流后立即结束负载统计，流的存续时间不计入
+		assert.Equal(t, 1, promise.passed)
+		return status.Error(codes.DeadlineExceeded, "deadline")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, promise.passed)
+	assert.Equal(t, 0, promise.failed)
+}
+
+type countedShedder struct {
+	promise *countedPromise
+}
+
+func (s countedShedder) Allow() (load.Promise, error) {
+	return s.promise, nil
+}
+
+type countedPromise struct {
+	passed int
+	failed int
+}
+
+func (p *countedPromise) Pass() {
+	p.passed++
+}
+
+func (p *countedPromise) Fail() {
+	p.failed++
+}
+
 type mockedShedder struct {
 	allow bool
 }
@@ -8,6 +8,7 @@ import (
 	"git.zc0901.com/go/god/lib/timex"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/peer"
+	"sync/atomic"
 	"time"
 )
 
@@ -32,6 +33,48 @@ func UnaryStatInterceptor(metrics *stat.Metrics) grpc.UnaryServerInterceptor {
 	}
 }
 
+// StreamStatInterceptor 流式统计拦截器（统计流的地址-方法、收发消息数和持续时长等信息）。
+// 流的持续时长通常远长于一元请求，只记录日志，不计入一元请求的统计指标。
+func StreamStatInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) (err error) {
+		defer handleCrash(func(r interface{}) {
+			err = toPanicError(r)
+		})
+
+		counted := &countedStream{ServerStream: stream}
+		startTime := timex.Now()
+		defer func() {
+			logStreamDuration(stream.Context(), info.FullMethod, counted, timex.Since(startTime), err)
+		}()
+
+		return handler(srv, counted)
+	}
+}
+
+// countedStream 统计收发消息数的服务端流
+type countedStream struct {
+	grpc.ServerStream
+	received int64
+	sent     int64
+}
+
+func (s *countedStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.received, 1)
+	}
+	return err
+}
+
+func (s *countedStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.sent, 1)
+	}
+	return err
+}
+
 func logDuration(ctx context.Context, method string, req interface{}, duration time.Duration) {
 	var addr string
 	client, ok := peer.FromContext(ctx)
@@ -48,3 +91,19 @@ func logDuration(ctx context.Context, method string, req interface{}, duration t
 		logx.WithContext(ctx).WithDuration(duration).Infof("%s - %s - %s", addr, method, string(content))
 	}
 }
+
+func logStreamDuration(ctx context.Context, method string, stream *countedStream, duration time.Duration, err error) {
+	var addr string
+	client, ok := peer.FromContext(ctx)
+	if ok {
+		addr = client.Addr.String()
+	}
+	received, sent := atomic.LoadInt64(&stream.received), atomic.LoadInt64(&stream.sent)
+	if err != nil {
+		logx.WithContext(ctx).WithDuration(duration).Errorf("[RPC] 流失败 - %s - %s - 收 %d 发 %d - %s",
+			addr, method, received, sent, err.Error())
+	} else {
+		logx.WithContext(ctx).WithDuration(duration).Infof("%s - %s - 收 %d 发 %d",
+			addr, method, received, sent)
+	}
+}
@@ -33,3 +33,29 @@ func TestUnaryStatInterceptor_crash(t *testing.T) {
 	assert.NotNil(t, err)
 	fmt.Println(err)
 }
+
+func TestStreamStatInterceptor(t *testing.T) {
+	interceptor := StreamStatInterceptor()
+	err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		assert.Nil(t, stream.RecvMsg(nil))
+		assert.Nil(t, stream.SendMsg(nil))
+		assert.Nil(t, stream.SendMsg(nil))
+		counted := stream.(*countedStream)
+		assert.Equal(t, int64(1), counted.received)
+		assert.Equal(t, int64(2), counted.sent)
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func TestStreamStatInterceptor_crash(t *testing.T) {
+	interceptor := StreamStatInterceptor()
+	err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		panic("error")
+	})
+	assert.NotNil(t, err)
+}
@@ -59,3 +59,111 @@ func UnaryTimeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor
 		}
 	}
 }
+
+// StreamTimeoutInterceptor 流式超时拦截器，timeout 为流的总时长上限，
+// idleTimeout 为两次收发消息之间的最长间隔，为 0 时不做对应限制。
+func StreamTimeoutInterceptor(timeout, idleTimeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		if timeout <= 0 && idleTimeout <= 0 {
+			return handler(srv, stream)
+		}
+
+		ctx, cancel := context.WithCancel(stream.Context())
+		defer cancel()
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		idle := newIdleStream(stream, ctx, idleTimeout)
+		defer idle.stop()
+
+		done := make(chan error, 1)
+		// 创建缓存长度为1的通道以防协程泄露
+		panicChan := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					// 添加堆栈信息以防迷失在 goroutine 中
+					panicChan <- fmt.Sprintf("%+v\n\n%s", p, strings.TrimSpace(string(debug.Stack())))
+				}
+			}()
+
+			done <- handler(srv, idle)
+		}()
+
+		select {
+		case p := <-panicChan:
+			panic(p)
+		case err := <-done:
+			return err
+		case <-idle.Context().Done():
+			err := idle.Context().Err()
+			if err == context.Canceled && ctx.Err() == nil {
+				// 空闲超时
+				return status.Error(codes.DeadlineExceeded, "流空闲超时")
+			}
+
+			if err == context.Canceled {
+				err = status.Error(codes.Canceled, err.Error())
+			} else if err == context.DeadlineExceeded {
+				err = status.Error(codes.DeadlineExceeded, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// idleStream 收发消息时重置空闲计时器的服务端流，超过空闲时长未收发消息则取消上下文
+type idleStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	timer *time.Timer
+	idle  time.Duration
+}
+
+func newIdleStream(stream grpc.ServerStream, ctx context.Context, idle time.Duration) *idleStream {
+	s := &idleStream{
+		ServerStream: stream,
+		idle:         idle,
+	}
+
+	if idle <= 0 {
+		s.ctx = ctx
+		return s
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.ctx = ctx
+	s.timer = time.AfterFunc(idle, cancel)
+	return s
+}
+
+func (s *idleStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *idleStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	s.touch()
+	return err
+}
+
+func (s *idleStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	s.touch()
+	return err
+}
+
+func (s *idleStream) touch() {
+	if s.timer != nil && s.ctx.Err() == nil {
+		s.timer.Reset(s.idle)
+	}
+}
+
+func (s *idleStream) stop() {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
@@ -3,6 +3,7 @@ package serverinterceptors
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -91,3 +92,74 @@ func TestUnaryTimeoutInterceptor_cancel(t *testing.T) {
 	wg.Wait()
 	assert.EqualValues(t, status.Error(codes.Canceled, context.Canceled.Error()), err)
 }
+
+func TestStreamTimeoutInterceptor(t *testing.T) {
+	interceptor := StreamTimeoutInterceptor(time.Second, time.Second)
+	err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		return stream.RecvMsg(nil)
+	})
+	assert.Nil(t, err)
+}
+
+func TestStreamTimeoutInterceptor_panic(t *testing.T) {
+	interceptor := StreamTimeoutInterceptor(time.Second, 0)
+	assert.Panics(t, func() {
+		_ = interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+			FullMethod: "/",
+		}, func(srv interface{}, stream grpc.ServerStream) error {
+			panic("any")
+		})
+	})
+}
+
+func TestStreamTimeoutInterceptor_timeout(t *testing.T) {
+	interceptor := StreamTimeoutInterceptor(time.Millisecond*50, 0)
+	err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		for {
+			if err := stream.SendMsg(nil); err != nil {
+				return err
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestStreamTimeoutInterceptor_idle(t *testing.T) {
+	interceptor := StreamTimeoutInterceptor(0, time.Millisecond*50)
+	var sent int32
+	err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		// 活跃期间不触发空闲超时
+		for i := 0; i < 10; i++ {
+			time.Sleep(time.Millisecond * 10)
+			if err := stream.SendMsg(nil); err != nil {
+				return err
+			}
+			atomic.AddInt32(&sent, 1)
+		}
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	})
+	assert.Equal(t, int32(10), atomic.LoadInt32(&sent))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestStreamTimeoutInterceptor_cancel(t *testing.T) {
+	interceptor := StreamTimeoutInterceptor(time.Second, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := interceptor(nil, mockedStream{ctx: ctx}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		<-stream.Context().Done()
+		time.Sleep(time.Millisecond * 50)
+		return nil
+	})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
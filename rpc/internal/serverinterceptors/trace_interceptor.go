@@ -26,3 +26,24 @@ func UnaryTraceInterceptor(serviceName string) grpc.UnaryServerInterceptor {
 		return handler(ctx, req)
 	}
 }
+
+// StreamTraceInterceptor 流式链路追踪拦截器，整个流对应一个服务端跟踪操作
+func StreamTraceInterceptor(serviceName string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return handler(srv, stream)
+		}
+
+		payload, err := trace.Extract(trace.GrpcFormat, md)
+		if err != nil {
+			return handler(srv, stream)
+		}
+
+		ctx, span := trace.StartServerSpan(ctx, payload, serviceName, info.FullMethod)
+		defer span.Finish()
+		return handler(srv, wrapStream(stream, ctx))
+	}
+}
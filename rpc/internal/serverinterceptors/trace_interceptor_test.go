@@ -45,3 +45,31 @@ func TestUnaryTracingInterceptor_GrpcFormat(t *testing.T) {
 	wg.Wait()
 	assert.Nil(t, err)
 }
+
+func TestStreamTracingInterceptor(t *testing.T) {
+	interceptor := StreamTraceInterceptor("foo")
+	var run int32
+	err := interceptor(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		atomic.AddInt32(&run, 1)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&run))
+}
+
+func TestStreamTracingInterceptor_GrpcFormat(t *testing.T) {
+	interceptor := StreamTraceInterceptor("foo")
+	var md metadata.MD
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	err := interceptor(nil, mockedStream{ctx: ctx}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		ctx := stream.Context()
+		assert.True(t, len(ctx.Value(trace.TracingKey).(trace.Trace).TraceId()) > 0)
+		assert.True(t, len(ctx.Value(trace.TracingKey).(trace.Trace).SpanId()) > 0)
+		return nil
+	})
+	assert.Nil(t, err)
+}
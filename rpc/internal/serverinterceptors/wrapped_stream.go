@@ -0,0 +1,24 @@
+package serverinterceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// wrappedStream 可替换上下文的服务端流，用于把拦截器生成的上下文传给流处理器
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+func wrapStream(stream grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &wrappedStream{
+		ServerStream: stream,
+		ctx:          ctx,
+	}
+}
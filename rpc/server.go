@@ -13,6 +13,16 @@ import (
 	"google.golang.org/grpc"
 )
 
+var (
+	// UnaryPeriodLimitInterceptor 一元周期限流拦截器，通过 AddUnaryInterceptors 添加
+	UnaryPeriodLimitInterceptor = serverinterceptors.UnaryPeriodLimitInterceptor
+	// UnaryTokenLimitInterceptor 一元令牌桶限流拦截器，通过 AddUnaryInterceptors 添加
+	UnaryTokenLimitInterceptor = serverinterceptors.UnaryTokenLimitInterceptor
+)
+
+// LimitKeyFunc 从请求上下文中提取限流键
+type LimitKeyFunc = serverinterceptors.LimitKeyFunc
+
 type RpcServer struct {
 	server   internal.Server
 	register internal.RegisterFn
@@ -41,7 +51,8 @@ func NewServer(c ServerConf, register internal.RegisterFn) (*RpcServer, error) {
 	// 新建内部RPC服务器
 	var server internal.Server
 	if c.HasEtcd() {
-		server, err = internal.NewPubServer(c.Etcd.Hosts, c.Etcd.Key, c.ListenOn, internal.WithMetrics(metrics))
+		server, err = internal.NewPubServer(c.Etcd.Hosts, c.Etcd.Key, c.ListenOn, internal.WithMetrics(metrics),
+			internal.WithEndpointMeta(c.Meta))
 		if err != nil {
 			return nil, err
 		}
@@ -50,12 +61,16 @@ func NewServer(c ServerConf, register internal.RegisterFn) (*RpcServer, error) {
 	}
 
 	server.SetName(c.Name)
-	if err = setupInterceptors(server, c, metrics); err != nil {
+	var shedder load.Shedder
+	if c.CpuThreshold > 0 {
+		shedder = load.NewAdaptiveShedder(load.WithCpuThreshold(c.CpuThreshold))
+	}
+	if err = setupInterceptors(server, c, shedder, metrics); err != nil {
 		return nil, err
 	}
 
 	// 新建对外RPC服务器
-	rpcServer := &RpcServer{server: server, register: register}
+	rpcServer := &RpcServer{server: server, register: setupServices(c, shedder, register)}
 	if err = c.Setup(); err != nil {
 		return nil, err
 	}
@@ -87,11 +102,11 @@ func (rs *RpcServer) Stop() {
 	logx.Close()
 }
 
-func setupInterceptors(server internal.Server, c ServerConf, metrics *stat.Metrics) error {
+func setupInterceptors(server internal.Server, c ServerConf, shedder load.Shedder, metrics *stat.Metrics) error {
 	// 自动降载（负载泄流拦截器）
-	if c.CpuThreshold > 0 {
-		shedder := load.NewAdaptiveShedder(load.WithCpuThreshold(c.CpuThreshold))
+	if shedder != nil {
 		server.AddUnaryInterceptors(serverinterceptors.UnaryShedderInterceptor(shedder, metrics))
+		server.AddStreamInterceptors(serverinterceptors.StreamShedderInterceptor(shedder, metrics))
 	}
 
 	// 超时控制（超时拦截器）
@@ -99,6 +114,10 @@ func setupInterceptors(server internal.Server, c ServerConf, metrics *stat.Metri
 		server.AddUnaryInterceptors(serverinterceptors.UnaryTimeoutInterceptor(
 			time.Duration(c.Timeout) * time.Millisecond))
 	}
+	if c.StreamTimeout > 0 || c.StreamIdleTimeout > 0 {
+		server.AddStreamInterceptors(serverinterceptors.StreamTimeoutInterceptor(
+			time.Duration(c.StreamTimeout)*time.Millisecond, time.Duration(c.StreamIdleTimeout)*time.Millisecond))
+	}
 
 	// 调用鉴权（鉴权拦截器）
 	if c.Auth {
@@ -113,3 +132,30 @@ func setupInterceptors(server internal.Server, c ServerConf, metrics *stat.Metri
 
 	return nil
 }
+
+// setupServices 在业务服务之后注册健康检查、服务反射和 channelz 服务
+func setupServices(c ServerConf, shedder load.Shedder, register internal.RegisterFn) internal.RegisterFn {
+	if !c.Health && !c.Reflection && !c.Channelz {
+		return register
+	}
+
+	return func(server *grpc.Server) {
+		register(server)
+
+		if c.Health {
+			var overloaded func() bool
+			if shedder != nil {
+				overloaded = func() bool {
+					return load.Overloaded(shedder)
+				}
+			}
+			internal.RegisterHealth(server, overloaded)
+		}
+		if c.Reflection {
+			internal.RegisterReflection(server)
+		}
+		if c.Channelz {
+			internal.RegisterChannelz(server)
+		}
+	}
+}
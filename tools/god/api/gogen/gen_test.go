@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"git.zc0901.com/go/god/tools/god/api/parser"
 	"git.zc0901.com/go/god/tools/god/rpc/execx"
@@ -169,6 +170,54 @@ service A-api {
 }
 `
 
+const apiPrefix = `
+type Request struct {
+  Name string ` + "`" + `path:"name,options=you|me"` + "`" + `
+}
+
+type Response struct {
+  Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+@server(
+	jwt: Auth
+	group: greet
+	prefix: /api/v1/
+)
+service A-api {
+  @handler GreetHandler
+  get /greet/from/:name(Request) returns (Response)
+}
+
+@server(
+	group: ping
+)
+service A-api {
+  @handler PingHandler
+  get /ping
+}
+`
+
+const apiRouteOverrides = `
+type Request struct {
+  Name string ` + "`" + `path:"name,options=you|me"` + "`" + `
+}
+
+type Response struct {
+  Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+@server(
+	timeout: 1m30s
+	maxBytes: 10485760
+	shedding: false
+)
+service A-api {
+  @handler GreetHandler
+  get /greet/from/:name(Request) returns (Response)
+}
+`
+
 const apiJwtWithMiddleware = `
 type Request struct {
   Name string ` + "`" + `path:"name,options=you|me"` + "`" + `
@@ -434,6 +483,51 @@ func TestApiHasJwt(t *testing.T) {
 	validate(t, filename)
 }
 
+func TestApiHasPrefix(t *testing.T) {
+	filename := "prefix.api"
+	err := ioutil.WriteFile(filename, []byte(apiPrefix), os.ModePerm)
+	assert.Nil(t, err)
+	defer os.Remove(filename)
+
+	dir := "_go_prefix"
+	defer os.RemoveAll(dir)
+	assert.Nil(t, DoGenProject(filename, dir, "gozero"))
+	routes, err := ioutil.ReadFile(filepath.Join(dir, handlerDir, "routes.go"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(routes), `engine.Group("/api/v1").AddRoutes(`)
+	assert.Contains(t, string(routes), `Path:    "/greet/from/:name"`)
+	assert.Contains(t, string(routes), `Handler: greet.GreetHandler(serverCtx)`)
+	assert.Contains(t, string(routes), `engine.AddRoutes(`)
+
+	validate(t, filename)
+}
+
+func TestApiHasRouteOverrides(t *testing.T) {
+	filename := "overrides.api"
+	err := ioutil.WriteFile(filename, []byte(apiRouteOverrides), os.ModePerm)
+	assert.Nil(t, err)
+	defer os.Remove(filename)
+
+	dir := "_go_overrides"
+	defer os.RemoveAll(dir)
+	assert.Nil(t, DoGenProject(filename, dir, "gozero"))
+	routes, err := ioutil.ReadFile(filepath.Join(dir, handlerDir, "routes.go"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(routes), `"time"`)
+	assert.Contains(t, string(routes), `api.WithTimeout(90*time.Second)`)
+	assert.Contains(t, string(routes), `api.WithMaxBytes(10485760)`)
+	assert.Contains(t, string(routes), `api.WithoutShedding()`)
+
+	validate(t, filename)
+}
+
+func TestDurationCode(t *testing.T) {
+	assert.Equal(t, "2*time.Hour", durationCode(2*time.Hour))
+	assert.Equal(t, "90*time.Second", durationCode(90*time.Second))
+	assert.Equal(t, "1500*time.Millisecond", durationCode(1500*time.Millisecond))
+	assert.Equal(t, "time.Duration(0)", durationCode(0))
+}
+
 func TestApiHasJwtAndMiddleware(t *testing.T) {
 	filename := "jwt.api"
 	err := ioutil.WriteFile(filename, []byte(apiJwtWithMiddleware), os.ModePerm)
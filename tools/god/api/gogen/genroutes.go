@@ -6,8 +6,10 @@ import (
 	"os"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"git.zc0901.com/go/god/lib/collection"
 	"git.zc0901.com/go/god/tools/god/api/spec"
@@ -24,7 +26,8 @@ const (
 package handler
 
 import (
-	"net/http"
+	"net/http"{{if .importTime}}
+	"time"{{end}}
 
 	{{.importPackages}}
 )
@@ -34,8 +37,8 @@ func RegisterHandlers(engine *api.Server, serverCtx *svc.ServiceContext) {
 }
 `
 	routesAdditionTemplate = `
-	engine.AddRoutes(
-		{{.routes}} {{.jwt}}{{.signature}}
+	{{.engine}}.AddRoutes(
+		{{.routes}} {{.jwt}}{{.signature}}{{.overrides}}
 	)
 `
 )
@@ -56,6 +59,10 @@ type (
 		signatureEnabled bool
 		authName         string
 		middlewares      []string
+		prefix           string
+		timeout          string
+		maxBytes         string
+		noShedding       bool
 	}
 	route struct {
 		method  string
@@ -71,6 +78,8 @@ func genRoutes(dir string, cfg *config.Config, api *spec.ApiSpec) error {
 		return err
 	}
 
+	var importTime bool
+
 	gt := template.Must(template.New("groupTemplate").Parse(routesAdditionTemplate))
 	for _, g := range groups {
 		var gbuilder strings.Builder
@@ -109,10 +118,29 @@ func genRoutes(dir string, cfg *config.Config, api *spec.ApiSpec) error {
 			routes = strings.TrimSpace(gbuilder.String())
 		}
 
+		var overrides string
+		if len(g.timeout) > 0 {
+			overrides += fmt.Sprintf("\n api.WithTimeout(%s),", g.timeout)
+			importTime = true
+		}
+		if len(g.maxBytes) > 0 {
+			overrides += fmt.Sprintf("\n api.WithMaxBytes(%s),", g.maxBytes)
+		}
+		if g.noShedding {
+			overrides += "\n api.WithoutShedding(),"
+		}
+
+		engine := "engine"
+		if len(g.prefix) > 0 {
+			engine = fmt.Sprintf("engine.Group(%q)", g.prefix)
+		}
+
 		if err := gt.Execute(&builder, map[string]string{
+			"engine":    engine,
 			"routes":    routes,
 			"jwt":       jwt,
 			"signature": signature,
+			"overrides": overrides,
 		}); err != nil {
 			return err
 		}
@@ -143,7 +171,8 @@ func genRoutes(dir string, cfg *config.Config, api *spec.ApiSpec) error {
 
 	t := template.Must(template.New("routesTemplate").Parse(routesTemplate))
 	buffer := new(bytes.Buffer)
-	err = t.Execute(buffer, map[string]string{
+	err = t.Execute(buffer, map[string]interface{}{
+		"importTime":      importTime,
 		"importPackages":  genRouteImports(parentPkg, api),
 		"routesAdditions": strings.TrimSpace(builder.String()),
 	})
@@ -206,6 +235,30 @@ func getRoutes(api *spec.ApiSpec) ([]group, error) {
 			groupedRoutes.authName = value
 			groupedRoutes.jwtEnabled = true
 		}
+		if value, ok := apiutil.GetAnnotationValue(g.Annotations, "server", prefixProperty); ok {
+			groupedRoutes.prefix = "/" + strings.Trim(value, "/")
+		}
+		if value, ok := apiutil.GetAnnotationValue(g.Annotations, "server", timeoutProperty); ok {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < 0 {
+				return nil, fmt.Errorf("无效的 %s：%s", timeoutProperty, value)
+			}
+			groupedRoutes.timeout = durationCode(timeout)
+		}
+		if value, ok := apiutil.GetAnnotationValue(g.Annotations, "server", maxBytesProperty); ok {
+			maxBytes, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || maxBytes < 0 {
+				return nil, fmt.Errorf("无效的 %s：%s", maxBytesProperty, value)
+			}
+			groupedRoutes.maxBytes = strconv.FormatInt(maxBytes, 10)
+		}
+		if value, ok := apiutil.GetAnnotationValue(g.Annotations, "server", sheddingProperty); ok {
+			shedding, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("无效的 %s：%s", sheddingProperty, value)
+			}
+			groupedRoutes.noShedding = !shedding
+		}
 		if value, ok := apiutil.GetAnnotationValue(g.Annotations, "server", "middleware"); ok {
 			for _, item := range strings.Split(value, ",") {
 				groupedRoutes.middlewares = append(groupedRoutes.middlewares, item)
@@ -217,6 +270,26 @@ func getRoutes(api *spec.ApiSpec) ([]group, error) {
 	return routes, nil
 }
 
+// durationCode 将时长转为 Go 代码，如 30*time.Second
+func durationCode(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	}
+	for _, u := range units {
+		if d >= u.unit && d%u.unit == 0 {
+			return fmt.Sprintf("%d*%s", d/u.unit, u.name)
+		}
+	}
+
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
+
 func toPrefix(folder string) string {
 	return strings.ReplaceAll(folder, "/", "")
 }
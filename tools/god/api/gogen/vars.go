@@ -1,13 +1,18 @@
 package gogen
 
 const (
-	interval      = "internal/"
-	typesPacket   = "types"
-	configDir     = interval + "config"
-	contextDir    = interval + "svc"
-	handlerDir    = interval + "handler"
-	logicDir      = interval + "logic"
-	middlewareDir = interval + "middleware"
-	typesDir      = interval + typesPacket
-	groupProperty = "group"
+	interval       = "internal/"
+	typesPacket    = "types"
+	configDir      = interval + "config"
+	contextDir     = interval + "svc"
+	handlerDir     = interval + "handler"
+	logicDir       = interval + "logic"
+	middlewareDir  = interval + "middleware"
+	typesDir       = interval + typesPacket
+	groupProperty  = "group"
+	prefixProperty = "prefix"
+	// 路由级配置，覆盖 api.Conf 中的全局配置
+	timeoutProperty  = "timeout"
+	maxBytesProperty = "maxBytes"
+	sheddingProperty = "shedding"
 )
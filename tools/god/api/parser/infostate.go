@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strings"
 
 	"git.zc0901.com/go/god/tools/god/api/spec"
@@ -44,19 +43,32 @@ func (s *infoState) writeInfo(api *spec.ApiSpec, attrs map[string]string) error
 	for k, v := range attrs {
 		switch k {
 		case titleTag:
-			api.Info.Title = strings.TrimSpace(v)
+			api.Info.Title = infoValue(v)
 		case descTag:
-			api.Info.Desc = strings.TrimSpace(v)
+			api.Info.Desc = infoValue(v)
 		case versionTag:
-			api.Info.Version = strings.TrimSpace(v)
+			api.Info.Version = infoValue(v)
 		case authorTag:
-			api.Info.Author = strings.TrimSpace(v)
+			api.Info.Author = infoValue(v)
 		case emailTag:
-			api.Info.Email = strings.TrimSpace(v)
+			api.Info.Email = infoValue(v)
 		default:
-			return fmt.Errorf("unknown directive %q in %q section", k, infoDirective)
+			// 其他键（如 date）不影响生成，原样保留
+			if api.Info.Properties == nil {
+				api.Info.Properties = make(map[string]string)
+			}
+			api.Info.Properties[k] = infoValue(v)
 		}
 	}
 
 	return nil
 }
+
+// infoValue 去掉首尾空白和可选的双引号，如 author: "god"
+func infoValue(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) > 1 && strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`) {
+		v = v[1 : len(v)-1]
+	}
+	return v
+}
@@ -86,6 +86,10 @@ func (p *Parser) Parse() (api *spec.ApiSpec, err error) {
 	}
 
 	api.Types = types
+	if err := parseInfo(api, p.api.Info); err != nil {
+		return nil, err
+	}
+
 	var lineNumber = p.api.serviceBeginLine
 	st := newRootState(p.r, &lineNumber)
 	for {
@@ -101,3 +105,25 @@ func (p *Parser) Parse() (api *spec.ApiSpec, err error) {
 		}
 	}
 }
+
+// parseInfo 解析 info 块，填充 API 的标题、描述、版本等信息
+func parseInfo(api *spec.ApiSpec, info string) error {
+	if len(strings.TrimSpace(info)) == 0 {
+		return nil
+	}
+
+	var lineNumber int
+	var st state = newRootState(bufio.NewReader(strings.NewReader(info)), &lineNumber)
+	for st != nil {
+		var err error
+		st, err = st.process(api)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("info: %s", err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const infoApi = `
+info(
+    title: "user api"
+    version: 1.0
+    date: "2021-01-01"
+)
+
+type Request struct {
+  Name string ` + "`" + `path:"name"` + "`" + `
+}
+
+service user-api {
+  @handler GetUser
+  get /users/:name(Request)
+}
+`
+
+func TestParser_ParseInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parser")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "user.api")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(infoApi), 0644))
+	p, err := NewParser(file)
+	assert.Nil(t, err)
+	api, err := p.Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, "user api", api.Info.Title)
+	assert.Equal(t, "1.0", api.Info.Version)
+	// 未知的 info 键不影响解析
+	assert.Equal(t, "2021-01-01", api.Info.Properties["date"])
+}
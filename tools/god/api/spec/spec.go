@@ -24,6 +24,8 @@ type (
 		Version string
 		Author  string
 		Email   string
+		// 其他 info 键值对
+		Properties map[string]string
 	}
 
 	Member struct {
@@ -0,0 +1,436 @@
+package swaggergen
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"git.zc0901.com/go/god/tools/god/api/spec"
+	"git.zc0901.com/go/god/tools/god/api/util"
+)
+
+const (
+	defaultVersion  = "1.0"
+	jwtSecurity     = "jwt"
+	schemaRefPrefix = "#/components/schemas/"
+	jsonContentType = "application/json"
+	formContentType = "application/x-www-form-urlencoded"
+	multipartType   = "multipart/form-data"
+	okStatus        = "200"
+	okDescription   = "OK"
+	fileOption      = "file"
+
+	inPath   = "path"
+	inQuery  = "query"
+	inHeader = "header"
+	inBody   = "body"
+)
+
+type generator struct {
+	api     *spec.ApiSpec
+	types   map[string]spec.Type
+	schemas map[string]*Schema
+}
+
+// Generate 把 API 描述转换为 OpenAPI 3 文档
+func Generate(api *spec.ApiSpec) *OpenAPI {
+	g := generator{
+		api:     api,
+		types:   make(map[string]spec.Type),
+		schemas: make(map[string]*Schema),
+	}
+	for _, tp := range api.Types {
+		g.types[tp.Name] = tp
+	}
+
+	return g.generate()
+}
+
+func (g *generator) generate() *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: openapiVersion,
+		Info: Info{
+			Title:       g.api.Info.Title,
+			Description: g.api.Info.Desc,
+			Version:     g.api.Info.Version,
+		},
+		Paths: make(map[string]PathItem),
+	}
+	if len(doc.Info.Title) == 0 {
+		doc.Info.Title = g.api.Service.Name
+	}
+	if len(doc.Info.Version) == 0 {
+		doc.Info.Version = defaultVersion
+	}
+	if len(g.api.Info.Author) > 0 || len(g.api.Info.Email) > 0 {
+		doc.Info.Contact = &Contact{
+			Name:  g.api.Info.Author,
+			Email: g.api.Info.Email,
+		}
+	}
+
+	tags := make(map[string]struct{})
+	for _, group := range g.api.Service.Groups {
+		prefix, _ := util.GetAnnotationValue(group.Annotations, "server", "prefix")
+		_, jwt := util.GetAnnotationValue(group.Annotations, "server", "jwt")
+		if jwt {
+			doc.Components.SecuritySchemes = map[string]SecurityScheme{
+				jwtSecurity: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+				},
+			}
+		}
+
+		for _, route := range group.Routes {
+			op := g.operation(group, route)
+			if jwt {
+				op.Security = []map[string][]string{{jwtSecurity: {}}}
+			}
+			for _, tag := range op.Tags {
+				tags[tag] = struct{}{}
+			}
+
+			p := openapiPath(path.Join("/", prefix, route.Path))
+			item, ok := doc.Paths[p]
+			if !ok {
+				item = make(PathItem)
+				doc.Paths[p] = item
+			}
+			item[strings.ToLower(route.Method)] = op
+		}
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool {
+		return doc.Tags[i].Name < doc.Tags[j].Name
+	})
+	if len(g.schemas) > 0 {
+		doc.Components.Schemas = g.schemas
+	}
+
+	return doc
+}
+
+func (g *generator) operation(group spec.Group, route spec.Route) *Operation {
+	op := &Operation{
+		Summary:   docSummary(route.Annotations),
+		Responses: map[string]Response{okStatus: {Description: okDescription}},
+	}
+	op.OperationId, _ = util.GetAnnotationValue(route.Annotations, "server", "handler")
+	if tag, ok := util.GetAnnotationValue(group.Annotations, "server", "group"); ok && len(tag) > 0 {
+		op.Tags = []string{tag}
+	} else if len(g.api.Service.Name) > 0 {
+		op.Tags = []string{g.api.Service.Name}
+	}
+
+	g.request(op, strings.ToUpper(route.Method), route.RequestType)
+
+	if len(route.ResponseType.Name) > 0 {
+		op.Responses[okStatus] = Response{
+			Description: okDescription,
+			Content: map[string]MediaType{
+				jsonContentType: {Schema: g.typeSchema(route.ResponseType.Name)},
+			},
+		}
+	}
+
+	return op
+}
+
+// request 按成员标签把请求类型拆分为路径、查询、请求头参数和请求体
+func (g *generator) request(op *Operation, method string, tp spec.Type) {
+	if len(tp.Name) == 0 {
+		return
+	}
+	if t, ok := g.types[tp.Name]; ok {
+		tp = t
+	}
+
+	var body, forms []field
+	var hasFile bool
+	for _, f := range g.fields(tp.Members) {
+		switch f.in {
+		case inPath:
+			op.Parameters = append(op.Parameters, f.parameter(inPath, true))
+		case inHeader:
+			op.Parameters = append(op.Parameters, f.parameter(inHeader, f.required))
+		case inQuery:
+			forms = append(forms, f)
+			hasFile = hasFile || f.file
+		case inBody:
+			body = append(body, f)
+		}
+	}
+
+	// GET 等没有请求体的方法，以及同时有 json 请求体时，form 成员从查询参数中解析
+	if !hasFile && (len(body) > 0 || !hasBody(method)) {
+		for _, f := range forms {
+			op.Parameters = append(op.Parameters, f.parameter(inQuery, f.required))
+		}
+		forms = nil
+	}
+
+	switch {
+	case len(forms) > 0:
+		contentType := formContentType
+		if hasFile {
+			contentType = multipartType
+		}
+		op.RequestBody = requestBody(contentType, objectSchema(forms))
+	case len(body) > 0 && len(body) == countBody(tp.Members):
+		op.RequestBody = requestBody(jsonContentType, g.typeSchema(tp.Name))
+	case len(body) > 0:
+		op.RequestBody = requestBody(jsonContentType, objectSchema(body))
+	}
+}
+
+type field struct {
+	name     string
+	in       string
+	file     bool
+	required bool
+	doc      string
+	schema   *Schema
+}
+
+func (f field) parameter(in string, required bool) Parameter {
+	return Parameter{
+		Name:        f.name,
+		In:          in,
+		Description: f.doc,
+		Required:    required,
+		Schema:      f.schema,
+	}
+}
+
+// fields 展开内嵌类型的成员，按标签确定每个成员的位置
+func (g *generator) fields(members []spec.Member) []field {
+	var fields []field
+	for _, member := range members {
+		if member.IsInline {
+			if t, ok := g.types[strings.TrimPrefix(member.Type, "*")]; ok {
+				fields = append(fields, g.fields(t.Members)...)
+			}
+			continue
+		}
+
+		f, ok := g.field(member)
+		if ok {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+func (g *generator) field(member spec.Member) (field, bool) {
+	for _, tag := range []struct {
+		key string
+		in  string
+	}{
+		{"json", inBody},
+		{"path", inPath},
+		{"form", inQuery},
+		{"header", inHeader},
+	} {
+		value, ok := util.TagLookup(member.Tag, tag.key)
+		if !ok {
+			continue
+		}
+
+		segments := strings.Split(value, ",")
+		name, options := strings.TrimSpace(segments[0]), segments[1:]
+		if name == "-" {
+			return field{}, false
+		}
+		if len(name) == 0 {
+			name = member.Name
+		}
+
+		f := field{
+			name:     name,
+			in:       tag.in,
+			required: true,
+			doc:      memberDoc(member),
+			schema:   g.schemaOf(member.Expr),
+		}
+		for _, option := range options {
+			switch {
+			case option == "optional", option == "omitempty", strings.HasPrefix(option, "default="):
+				f.required = false
+			case option == fileOption:
+				f.file = true
+			}
+		}
+		if f.file {
+			f.schema = &Schema{Type: "string", Format: "binary"}
+			if _, ok := member.Expr.(*spec.ArrayType); ok {
+				f.schema = &Schema{Type: "array", Items: f.schema}
+			}
+		}
+
+		applyOptions(f.schema, options)
+		if applyRules(f.schema, parseRules(member.Tag)) {
+			f.required = true
+		}
+		if len(f.schema.Ref) == 0 && len(f.doc) > 0 && tag.in == inBody {
+			f.schema.Description = f.doc
+		}
+
+		return f, true
+	}
+
+	return field{}, false
+}
+
+// typeSchema 返回命名类型的引用，首次引用时生成其 schema
+func (g *generator) typeSchema(name string) *Schema {
+	ref := &Schema{Ref: schemaRefPrefix + name}
+	if _, ok := g.schemas[name]; ok {
+		return ref
+	}
+
+	tp, ok := g.types[name]
+	if !ok {
+		return &Schema{Type: "object"}
+	}
+
+	// 先占位，防止自引用类型无限递归
+	schema := &Schema{Type: "object"}
+	g.schemas[name] = schema
+	var fields []field
+	for _, f := range g.fields(tp.Members) {
+		if f.in == inBody {
+			fields = append(fields, f)
+		}
+	}
+	*schema = *objectSchema(fields)
+
+	return ref
+}
+
+func (g *generator) schemaOf(expr interface{}) *Schema {
+	switch v := expr.(type) {
+	case *spec.BasicType:
+		return basicSchema(v.Name)
+	case *spec.PointerType:
+		return g.schemaOf(v.Star)
+	case *spec.ArrayType:
+		if basic, ok := v.ArrayType.(*spec.BasicType); ok && (basic.Name == "byte" || basic.Name == "uint8") {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(v.ArrayType)}
+	case *spec.MapType:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(v.Value)}
+	case *spec.TimeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case *spec.InterfaceType:
+		return &Schema{}
+	case *spec.Type:
+		return g.typeSchema(v.Name)
+	case *spec.StructType:
+		return g.typeSchema(strings.TrimPrefix(v.StringExpr, "*"))
+	default:
+		return &Schema{}
+	}
+}
+
+func basicSchema(name string) *Schema {
+	switch name {
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32", "byte", "rune":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "int", "int64", "uint", "uint64", "uintptr":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	default:
+		return &Schema{Type: "string"}
+	}
+}
+
+func objectSchema(fields []field) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+	for _, f := range fields {
+		schema.Properties[f.name] = f.schema
+		if f.required {
+			schema.Required = append(schema.Required, f.name)
+		}
+	}
+
+	return schema
+}
+
+func requestBody(contentType string, schema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]MediaType{
+			contentType: {Schema: schema},
+		},
+	}
+}
+
+// countBody 统计直接声明的 json 成员数，含内嵌类型的请求不能直接引用类型 schema
+func countBody(members []spec.Member) int {
+	var count int
+	for _, member := range members {
+		if member.IsInline {
+			return -1
+		}
+		if _, ok := util.TagLookup(member.Tag, "json"); ok {
+			count++
+		}
+	}
+	return count
+}
+
+func hasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// openapiPath 把 :name 形式的路径参数转为 {name}
+func openapiPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func docSummary(annos []spec.Annotation) string {
+	if summary, ok := util.GetAnnotationValue(annos, "doc", "summary"); ok && len(summary) > 0 {
+		return summary
+	}
+	for _, anno := range annos {
+		if anno.Name == "doc" {
+			return strings.TrimSpace(anno.Value)
+		}
+	}
+	return ""
+}
+
+func memberDoc(member spec.Member) string {
+	docs := append(append([]string(nil), member.Docs...), member.Comments...)
+	for i, doc := range docs {
+		docs[i] = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(doc), "//"))
+	}
+	return strings.TrimSpace(strings.Join(docs, " "))
+}
@@ -0,0 +1,631 @@
+package swaggergen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"git.zc0901.com/go/god/tools/god/api/format"
+	"git.zc0901.com/go/god/tools/god/api/parser"
+	"git.zc0901.com/go/god/tools/god/util/console"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	orderKey     = "x-order"
+	jwtName      = "Auth"
+	handlerExt   = "Handler"
+	requestExt   = "Request"
+	responseExt  = "Response"
+	schemaPrefix = "#/components/schemas/"
+	paramPrefix  = "#/components/parameters/"
+	bodyPrefix   = "#/components/requestBodies/"
+	replyPrefix  = "#/components/responses/"
+)
+
+// importMethods .api 文件支持的请求方法，按输出顺序排列
+var importMethods = []string{"get", "head", "post", "put", "patch", "delete"}
+
+type (
+	importer struct {
+		doc      *OpenAPI
+		types    []*apiType
+		typeMap  map[string]*apiType
+		refs     map[string]string
+		visiting map[string]bool
+		groups   []*apiGroup
+		handlers map[string]bool
+		prefix   string
+		warnings []string
+	}
+
+	apiGroup struct {
+		tag    string
+		jwt    bool
+		routes []apiRoute
+	}
+
+	apiRoute struct {
+		summary  string
+		handler  string
+		method   string
+		path     string
+		request  string
+		response string
+	}
+)
+
+// ImportCommand 把 OpenAPI 3.0 文档转换为 .api 文件，无法表示的结构输出为警告
+func ImportCommand(c *cli.Context) error {
+	file := c.String("openapi")
+	if len(file) == 0 {
+		return errors.New("missing -openapi")
+	}
+	output := c.String("o")
+	if len(output) == 0 {
+		output = strings.TrimSuffix(file, filepath.Ext(file)) + ".api"
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	content, warnings, err := Import(data)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(output, []byte(content), 0644); err != nil {
+		return err
+	}
+	if err := format.ApiFormatByPath(output); err != nil {
+		return err
+	}
+	if err := validateApi(output); err != nil {
+		return fmt.Errorf("generated file %s is invalid: %s", output, err.Error())
+	}
+
+	log := console.NewColorConsole()
+	for _, warning := range warnings {
+		log.Warning("unsupported: %s", warning)
+	}
+	log.MarkDone()
+	return nil
+}
+
+// validateApi 完整解析生成的 .api 文件，包括类型引用等语义检查
+func validateApi(file string) error {
+	p, err := parser.NewParser(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.Parse()
+	return err
+}
+
+// Import 把 JSON 或 YAML 格式的 OpenAPI 3.0 文档转换为 .api 文件内容，
+// warnings 为无法表示而被忽略或降级的结构。
+func Import(data []byte) (api string, warnings []string, err error) {
+	im := &importer{
+		typeMap:  make(map[string]*apiType),
+		refs:     make(map[string]string),
+		visiting: make(map[string]bool),
+		handlers: make(map[string]bool),
+	}
+	if err := im.load(data); err != nil {
+		return "", nil, err
+	}
+
+	im.convert()
+	return im.String(), im.warnings, nil
+}
+
+// load 解析文档，保留属性的声明顺序，并把路径级别的参数合并到各个操作中
+func (im *importer) load(data []byte) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	doc, ok := nodeValue(&node, false).(map[string]interface{})
+	if !ok {
+		return errors.New("invalid OpenAPI document")
+	}
+	if _, ok := doc["swagger"]; ok {
+		return errors.New("swagger 2.0 documents are not supported, convert to OpenAPI 3.0 first")
+	}
+	if version := fmt.Sprint(doc["openapi"]); !strings.HasPrefix(version, "3.0") {
+		return fmt.Errorf("unsupported OpenAPI version %q, only 3.0.x is supported", version)
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for p, v := range paths {
+		item, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		params, _ := item["parameters"].([]interface{})
+		for key, value := range item {
+			op, ok := value.(map[string]interface{})
+			if !ok || !isMethod(key) {
+				if key == "$ref" {
+					im.warn("%s: path item $ref", p)
+				}
+				delete(item, key)
+				continue
+			}
+			if len(params) > 0 {
+				own, _ := op["parameters"].([]interface{})
+				op["parameters"] = append(append([]interface{}(nil), params...), own...)
+			}
+		}
+	}
+
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	im.doc = new(OpenAPI)
+	return json.Unmarshal(content, im.doc)
+}
+
+func (im *importer) convert() {
+	if len(im.doc.Servers) > 0 {
+		if u, err := url.Parse(im.doc.Servers[0].URL); err == nil && !strings.Contains(u.Path, "{") {
+			im.prefix = strings.TrimRight(u.Path, "/")
+		}
+	}
+
+	var names []string
+	for name := range im.doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		im.refType(schemaPrefix+name, "components.schemas."+name)
+	}
+
+	var paths []string
+	for p := range im.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		item := im.doc.Paths[p]
+		for method := range item {
+			if !contains(importMethods, method) {
+				im.warn("%s %s: method %s", strings.ToUpper(method), p, method)
+			}
+		}
+		for _, method := range importMethods {
+			if op, ok := item[method]; ok && op != nil {
+				im.route(method, p, op)
+			}
+		}
+	}
+}
+
+func (im *importer) route(method, p string, op *Operation) {
+	where := strings.ToUpper(method) + " " + p
+	routePath, ok := apiPath(p)
+	if !ok {
+		im.warn("%s: path parameters must be whole path segments, route skipped", where)
+		return
+	}
+
+	route := apiRoute{
+		summary: op.Summary,
+		method:  method,
+		path:    routePath,
+	}
+	if len(route.summary) == 0 {
+		route.summary = op.Description
+	}
+
+	base := identifier(op.OperationId)
+	if len(base) == 0 {
+		base = identifier(method + " " + p)
+	}
+	base = strings.TrimSuffix(base, handlerExt)
+	for i := 2; im.handlers[base]; i++ {
+		base = fmt.Sprintf("%s%d", strings.TrimRight(base, "0123456789"), i)
+	}
+	im.handlers[base] = true
+	route.handler = base + handlerExt
+	route.request = im.request(base+requestExt, op, where)
+	route.response = im.response(base+responseExt, op, where)
+
+	var tag string
+	if len(op.Tags) > 0 {
+		tag = op.Tags[0]
+	}
+	g := im.group(groupName(tag), im.isJwt(op, where))
+	g.routes = append(g.routes, route)
+}
+
+// request 把参数和请求体合并为请求类型，请求体引用了命名类型且没有参数时直接使用该类型
+func (im *importer) request(name string, op *Operation, where string) string {
+	var params []Parameter
+	for _, param := range op.Parameters {
+		if len(param.Ref) > 0 {
+			resolved, ok := im.doc.Components.Parameters[strings.TrimPrefix(param.Ref, paramPrefix)]
+			if !ok || !strings.HasPrefix(param.Ref, paramPrefix) {
+				im.warn("%s: parameter $ref %s", where, param.Ref)
+				continue
+			}
+			param = *resolved
+		}
+		params = append(params, param)
+	}
+
+	var bodyKey string
+	var body *Schema
+	if rb := op.RequestBody; rb != nil && len(rb.Ref) > 0 {
+		resolved, ok := im.doc.Components.RequestBodies[strings.TrimPrefix(rb.Ref, bodyPrefix)]
+		if !ok || !strings.HasPrefix(rb.Ref, bodyPrefix) {
+			im.warn("%s: requestBody $ref %s", where, rb.Ref)
+			op.RequestBody = nil
+		} else {
+			op.RequestBody = resolved
+		}
+	}
+	if rb := op.RequestBody; rb != nil {
+		for _, contentType := range []string{jsonContentType, formContentType, multipartType} {
+			if media, ok := rb.Content[contentType]; ok {
+				bodyKey, body = "json", media.Schema
+				if contentType != jsonContentType {
+					bodyKey = "form"
+				}
+				break
+			}
+		}
+		if body == nil {
+			im.warn("%s: request content type %s", where, strings.Join(contentTypes(rb.Content), ", "))
+		}
+	}
+
+	if len(params) == 0 && body != nil && bodyKey == "json" && len(body.Ref) > 0 {
+		if tp := im.refType(body.Ref, where); im.typeMap[tp] != nil {
+			return tp
+		}
+	}
+
+	t := im.newType(name)
+	for _, param := range params {
+		key := param.In
+		switch param.In {
+		case inPath, inHeader:
+		case inQuery:
+			key = "form"
+		default:
+			im.warn("%s: %s parameter %s", where, param.In, param.Name)
+			continue
+		}
+		if param.Schema == nil {
+			im.warn("%s: parameter %s without schema, mapped to string", where, param.Name)
+			param.Schema = &Schema{Type: "string"}
+		}
+		im.addMember(t, param.Name, param.Schema, key, param.Required || param.In == inPath,
+			param.Description, where)
+	}
+	if body != nil {
+		im.addBody(t, body, bodyKey, where)
+	}
+
+	if len(t.members) == 0 {
+		im.dropType(t)
+		return ""
+	}
+	return t.name
+}
+
+// addBody 把请求体的属性展开到请求类型中，引用的命名类型以内嵌方式加入
+func (im *importer) addBody(t *apiType, body *Schema, key, where string) {
+	if len(body.Ref) > 0 && key == "json" {
+		tp := im.refType(body.Ref, where)
+		if im.typeMap[tp] == nil {
+			im.warn("%s: request body of non-object type", where)
+			return
+		}
+		t.members = append(t.members, apiMember{tp: tp, inline: true})
+		return
+	}
+
+	schema := im.resolve(body)
+	if schema == nil || !isObject(schema) {
+		im.warn("%s: request body of non-object type", where)
+		return
+	}
+	im.addProperties(t, schema, key, where)
+}
+
+func (im *importer) response(name string, op *Operation, where string) string {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) == 0 {
+		if _, ok := op.Responses["default"]; !ok {
+			return ""
+		}
+		codes = append(codes, "default")
+	}
+
+	reply := op.Responses[codes[0]]
+	if len(reply.Ref) > 0 {
+		resolved, ok := im.doc.Components.Responses[strings.TrimPrefix(reply.Ref, replyPrefix)]
+		if !ok || !strings.HasPrefix(reply.Ref, replyPrefix) {
+			im.warn("%s: response $ref %s", where, reply.Ref)
+			return ""
+		}
+		reply = *resolved
+	}
+	if len(reply.Content) == 0 {
+		return ""
+	}
+
+	media, ok := reply.Content[jsonContentType]
+	if !ok || media.Schema == nil {
+		im.warn("%s: response content type %s", where, strings.Join(contentTypes(reply.Content), ", "))
+		return ""
+	}
+
+	tp := im.typeOf(media.Schema, name, where)
+	if im.typeMap[strings.TrimPrefix(tp, "*")] == nil {
+		im.warn("%s: response body of type %s, .api only returns struct types", where, tp)
+		return ""
+	}
+	return strings.TrimPrefix(tp, "*")
+}
+
+// isJwt 判断操作是否使用 JWT 认证，未设置时使用文档级别的认证要求
+func (im *importer) isJwt(op *Operation, where string) bool {
+	security := op.Security
+	if security == nil {
+		security = im.doc.Security
+	}
+
+	var jwt bool
+	for _, requirement := range security {
+		for name := range requirement {
+			scheme, ok := im.doc.Components.SecuritySchemes[name]
+			if ok && scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer") {
+				jwt = true
+			} else {
+				im.warn("%s: security scheme %s", where, name)
+			}
+		}
+	}
+
+	return jwt
+}
+
+func (im *importer) group(tag string, jwt bool) *apiGroup {
+	for _, g := range im.groups {
+		if g.tag == tag && g.jwt == jwt {
+			return g
+		}
+	}
+
+	g := &apiGroup{tag: tag, jwt: jwt}
+	im.groups = append(im.groups, g)
+	return g
+}
+
+func (im *importer) warn(format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	for _, w := range im.warnings {
+		if w == warning {
+			return
+		}
+	}
+	im.warnings = append(im.warnings, warning)
+}
+
+// String 输出 .api 文件内容
+func (im *importer) String() string {
+	var builder strings.Builder
+	info := im.doc.Info
+	builder.WriteString("info(\n")
+	writeInfo(&builder, "title", info.Title)
+	writeInfo(&builder, "desc", info.Description)
+	if info.Contact != nil {
+		writeInfo(&builder, "author", info.Contact.Name)
+		writeInfo(&builder, "email", info.Contact.Email)
+	}
+	writeInfo(&builder, "version", info.Version)
+	builder.WriteString(")\n")
+
+	for _, t := range im.types {
+		builder.WriteString("\n")
+		t.write(&builder)
+	}
+
+	service := serviceName(info.Title)
+	for _, g := range im.groups {
+		builder.WriteString("\n")
+		if len(g.tag) > 0 || g.jwt || len(im.prefix) > 0 {
+			builder.WriteString("@server(\n")
+			if g.jwt {
+				fmt.Fprintf(&builder, "\tjwt: %s\n", jwtName)
+			}
+			if len(g.tag) > 0 {
+				fmt.Fprintf(&builder, "\tgroup: %s\n", g.tag)
+			}
+			if len(im.prefix) > 0 {
+				fmt.Fprintf(&builder, "\tprefix: %s\n", im.prefix)
+			}
+			builder.WriteString(")\n")
+		}
+
+		fmt.Fprintf(&builder, "service %s {\n", service)
+		for i, r := range g.routes {
+			if i > 0 {
+				builder.WriteString("\n")
+			}
+			if summary := annotationValue(r.summary); len(summary) > 0 {
+				fmt.Fprintf(&builder, "\t@doc(\n\t\tsummary: %s\n\t)\n", summary)
+			}
+			fmt.Fprintf(&builder, "\t@handler %s\n\t%s %s", r.handler, r.method, r.path)
+			if len(r.request) > 0 {
+				fmt.Fprintf(&builder, " (%s)", r.request)
+			}
+			if len(r.response) > 0 {
+				fmt.Fprintf(&builder, " returns (%s)", r.response)
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString("}\n")
+	}
+
+	return builder.String()
+}
+
+// nodeValue 把 YAML 节点转为通用值，schema 的 properties 顺序记录在 x-order 中，
+// additionalProperties: true 转为空 schema，false 则忽略
+func nodeValue(node *yaml.Node, propertyMap bool) interface{} {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return nodeValue(node.Content[0], false)
+	case yaml.AliasNode:
+		return nodeValue(node.Alias, propertyMap)
+	case yaml.SequenceNode:
+		values := make([]interface{}, 0, len(node.Content))
+		for _, child := range node.Content {
+			values = append(values, nodeValue(child, false))
+		}
+		return values
+	case yaml.MappingNode:
+		m := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			isProperties := !propertyMap && key == "properties" && value.Kind == yaml.MappingNode
+			if key == "additionalProperties" && value.Kind == yaml.ScalarNode {
+				if value.Value == "true" {
+					m[key] = map[string]interface{}{}
+				}
+				continue
+			}
+
+			m[key] = nodeValue(value, isProperties)
+			if isProperties {
+				var order []interface{}
+				for j := 0; j < len(value.Content); j += 2 {
+					order = append(order, value.Content[j].Value)
+				}
+				m[orderKey] = order
+			}
+		}
+		return m
+	default:
+		var v interface{}
+		if err := node.Decode(&v); err != nil {
+			return node.Value
+		}
+		return v
+	}
+}
+
+// apiPath 把 {name} 形式的路径参数转为 :name，参数必须占据整个路径段
+func apiPath(p string) (string, bool) {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if !strings.ContainsAny(segment, "{}") {
+			continue
+		}
+		if !strings.HasPrefix(segment, "{") || strings.Index(segment, "}") != len(segment)-1 {
+			return "", false
+		}
+		segments[i] = ":" + segment[1:len(segment)-1]
+	}
+
+	return strings.Join(segments, "/"), true
+}
+
+// groupName 把标签转为可用作目录和包名的分组名
+func groupName(tag string) string {
+	var builder strings.Builder
+	for _, c := range strings.ToLower(tag) {
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' {
+			builder.WriteRune(c)
+		}
+	}
+	return builder.String()
+}
+
+func serviceName(title string) string {
+	var builder strings.Builder
+	for _, c := range strings.ToLower(title) {
+		switch {
+		case c >= 'a' && c <= 'z' || c >= '0' && c <= '9':
+			builder.WriteRune(c)
+		case builder.Len() > 0 && !strings.HasSuffix(builder.String(), "-"):
+			builder.WriteRune('-')
+		}
+	}
+
+	name := strings.Trim(builder.String(), "-")
+	if len(name) == 0 {
+		name = "openapi"
+	}
+	if !strings.HasSuffix(name, "-api") {
+		name += "-api"
+	}
+	return name
+}
+
+func writeInfo(builder *strings.Builder, key, value string) {
+	if value = annotationValue(value); len(value) > 0 {
+		fmt.Fprintf(builder, "\t%s: %s\n", key, value)
+	}
+}
+
+// annotationValue 去掉换行、括号和注释符号，使其可以作为注解的属性值
+func annotationValue(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	value = strings.NewReplacer("(", "", ")", "", "//", "/", "/*", "/").Replace(value)
+	return strings.TrimLeft(value, "><")
+}
+
+func contentTypes(content map[string]MediaType) []string {
+	var types []string
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func isMethod(key string) bool {
+	switch key {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	default:
+		return false
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,359 @@
+package swaggergen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"git.zc0901.com/go/god/tools/god/api/parser"
+	"git.zc0901.com/go/god/tools/god/api/spec"
+	"git.zc0901.com/go/god/tools/god/api/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOpenAPI = `
+openapi: 3.0.1
+info:
+  title: Pet Store
+  description: |
+    pets (and owners)
+  version: 1.0.0
+  contact:
+    name: god
+servers:
+  - url: https://example.com/v1/
+security:
+  - bearer: []
+paths:
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      tags: [pets]
+      summary: Get a pet
+      operationId: getPet
+      parameters:
+        - name: X-Trace
+          in: header
+          schema:
+            type: string
+        - name: session
+          in: cookie
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+    put:
+      tags: [pets]
+      operationId: updatePet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        "204":
+          description: no content
+  /pets:
+    get:
+      tags: [pets]
+      operationId: listPets
+      security: []
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            format: int32
+            minimum: 1
+            maximum: 100
+            default: 20
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      tags: [pets]
+      requestBody:
+        $ref: '#/components/requestBodies/NewPet'
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: integer
+  /pets/{petId}/photo:
+    post:
+      tags: [photos]
+      operationId: uploadPhoto
+      parameters:
+        - $ref: '#/components/parameters/PetId'
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                title:
+                  type: string
+                file:
+                  type: string
+                  format: binary
+      responses:
+        default:
+          description: OK
+    options:
+      responses:
+        "200":
+          description: OK
+  /files/{name}.json:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      required: true
+      schema:
+        type: integer
+  requestBodies:
+    NewPet:
+      content:
+        application/json:
+          schema:
+            allOf:
+              - $ref: '#/components/schemas/Audit'
+              - type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string
+  schemas:
+    Audit:
+      type: object
+      properties:
+        created:
+          type: string
+          format: date-time
+    Status:
+      type: string
+      enum: [available, sold]
+    Pet:
+      type: object
+      required: [name, status]
+      properties:
+        name:
+          type: string
+          description: pet name
+          minLength: 1
+          maxLength: 32
+        status:
+          $ref: '#/components/schemas/Status'
+        owner:
+          type: object
+          properties:
+            email:
+              type: string
+              format: email
+        parent:
+          $ref: '#/components/schemas/Pet'
+        tags:
+          type: array
+          items:
+            type: string
+        attrs:
+          type: object
+          additionalProperties: true
+        kind:
+          oneOf:
+            - type: string
+            - type: integer
+        code:
+          type: string
+          pattern: ^[a-z]+$
+`
+
+func TestImport(t *testing.T) {
+	content, warnings, err := Import([]byte(testOpenAPI))
+	assert.Nil(t, err)
+
+	api := parseApi(t, content)
+	assert.Equal(t, "Pet Store", api.Info.Title)
+	assert.Equal(t, "pets and owners", api.Info.Desc)
+	assert.Equal(t, "god", api.Info.Author)
+	assert.Equal(t, "pet-store-api", api.Service.Name)
+
+	pet := findType(api, "Pet")
+	assert.Equal(t, []string{"Name", "Status", "Owner", "Parent", "Tags", "Attrs", "Kind", "Code"}, memberNames(pet))
+	assert.Equal(t, "`json:\"name\" v:\"length:1,32\"`", pet.Members[0].Tag)
+	assert.Equal(t, "string", pet.Members[1].Type)
+	assert.Equal(t, "`json:\"status,options=available|sold\"`", pet.Members[1].Tag)
+	assert.Equal(t, "PetOwner", pet.Members[2].Type)
+	assert.Equal(t, "*Pet", pet.Members[3].Type)
+	assert.Equal(t, "[]string", pet.Members[4].Type)
+	assert.Equal(t, "map[string]interface{}", pet.Members[5].Type)
+	assert.Equal(t, "interface{}", pet.Members[6].Type)
+	assert.Equal(t, "`json:\"code,optional\" v:\"regex:^[a-z]+$\"`", pet.Members[7].Tag)
+	assert.Equal(t, "`json:\"email,optional\" v:\"email\"`", findType(api, "PetOwner").Members[0].Tag)
+
+	routes := make(map[string]spec.Route)
+	groups := make(map[string]spec.Group)
+	for _, group := range api.Service.Groups {
+		for _, route := range group.Routes {
+			handler, _ := util.GetAnnotationValue(route.Annotations, "server", "handler")
+			routes[handler] = route
+			groups[handler] = group
+		}
+	}
+	assert.Len(t, routes, 5)
+
+	get := routes["GetPetHandler"]
+	assert.Equal(t, "/pets/:petId", get.Path)
+	assert.Equal(t, "GetPetRequest", get.RequestType.Name)
+	assert.Equal(t, "Pet", get.ResponseType.Name)
+	summary, _ := util.GetAnnotationValue(get.Annotations, "doc", "summary")
+	assert.Equal(t, "Get a pet", summary)
+	getReq := findType(api, "GetPetRequest")
+	assert.Equal(t, "`path:\"petId\"`", getReq.Members[0].Tag)
+	assert.Equal(t, "`header:\"X-Trace,optional\"`", getReq.Members[1].Tag)
+	jwt, _ := util.GetAnnotationValue(groups["GetPetHandler"].Annotations, "server", "jwt")
+	assert.Equal(t, jwtName, jwt)
+	group, _ := util.GetAnnotationValue(groups["GetPetHandler"].Annotations, "server", "group")
+	assert.Equal(t, "pets", group)
+	prefix, _ := util.GetAnnotationValue(groups["GetPetHandler"].Annotations, "server", "prefix")
+	assert.Equal(t, "/v1", prefix)
+
+	update := findType(api, "UpdatePetRequest")
+	assert.True(t, update.Members[1].IsInline)
+	assert.Equal(t, "Pet", update.Members[1].Type)
+	assert.Empty(t, routes["UpdatePetHandler"].ResponseType.Name)
+
+	list := routes["ListPetsHandler"]
+	assert.Empty(t, list.ResponseType.Name)
+	assert.Equal(t, "`form:\"limit,default=20,range=[1:100]\"`", findType(api, "ListPetsRequest").Members[0].Tag)
+	_, jwtOk := util.GetAnnotationValue(groups["ListPetsHandler"].Annotations, "server", "jwt")
+	assert.False(t, jwtOk)
+
+	create := routes["PostPetsHandler"]
+	assert.Equal(t, "PostPetsRequest", create.RequestType.Name)
+	assert.Equal(t, "PostPetsResponse", create.ResponseType.Name)
+	createReq := findType(api, "PostPetsRequest")
+	assert.True(t, createReq.Members[0].IsInline)
+	assert.Equal(t, "`json:\"name\"`", createReq.Members[1].Tag)
+
+	upload := findType(api, "UploadPhotoRequest")
+	assert.Equal(t, []string{"PetId", "Title"}, memberNames(upload))
+	assert.Equal(t, "`form:\"title,optional\"`", upload.Members[1].Tag)
+
+	assert.ElementsMatch(t, []string{
+		"GET /pets/{petId}: cookie parameter session",
+		"components.schemas.Pet.kind: oneOf/anyOf/not, mapped to interface{}",
+		"GET /pets: response body of type []Pet, .api only returns struct types",
+		"POST /pets/{petId}/photo: file field file, parse it with httpx.ParseFiles",
+		"OPTIONS /pets/{petId}/photo: method options",
+		"GET /files/{name}.json: path parameters must be whole path segments, route skipped",
+	}, warnings)
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "user.api")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(testApi), 0644))
+	p, err := parser.NewParser(file)
+	assert.Nil(t, err)
+	api, err := p.Parse()
+	assert.Nil(t, err)
+
+	doc, err := Marshal(api, true)
+	assert.Nil(t, err)
+	content, _, err := Import(doc)
+	assert.Nil(t, err)
+
+	// 解析器按名称缓存类型，同名类型只检查生成的文本
+	imported := parseApi(t, content)
+	assert.Equal(t, api.Info.Title, imported.Info.Title)
+	assert.Len(t, imported.Service.Routes(), len(api.Service.Routes()))
+	assert.Contains(t, content, "type UserReply struct {\n"+
+		"\tExtra map[string]interface{} `json:\"extra,optional\"`\n"+
+		"\tFriends []Friend `json:\"friends\"`\n"+
+		"\tId int64 `json:\"id\"`\n"+
+		"\tTags []string `json:\"tags\"`\n}")
+	assert.Contains(t, content, "Name string `json:\"name\" v:\"length:2,20\"`")
+}
+
+func TestImportUnsupportedVersion(t *testing.T) {
+	_, _, err := Import([]byte(`{"swagger": "2.0", "paths": {}}`))
+	assert.NotNil(t, err)
+	_, _, err = Import([]byte(`{"openapi": "3.1.0", "paths": {}}`))
+	assert.NotNil(t, err)
+}
+
+func parseApi(t *testing.T, content string) *spec.ApiSpec {
+	file := filepath.Join(t.TempDir(), "imported.api")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0644))
+	p, err := parser.NewParser(file)
+	if !assert.Nil(t, err, content) {
+		t.FailNow()
+	}
+	api, err := p.Parse()
+	if !assert.Nil(t, err, content) {
+		t.FailNow()
+	}
+	return api
+}
+
+func findType(api *spec.ApiSpec, name string) spec.Type {
+	for _, tp := range api.Types {
+		if tp.Name == name {
+			return tp
+		}
+	}
+	return spec.Type{}
+}
+
+func memberNames(tp spec.Type) []string {
+	var names []string
+	for _, member := range tp.Members {
+		names = append(names, member.Name)
+	}
+	return names
+}
+
+func TestValidateApi(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "user.api")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(testApi), 0644))
+	assert.Nil(t, validateApi(file))
+
+	// 语法正确但处理器重名，只有完整解析才能发现
+	assert.Nil(t, ioutil.WriteFile(file, []byte(`service user-api {
+	@handler getUser
+	get /users/a
+
+	@handler getUser
+	get /users/b
+}
+`), 0644))
+	_, err := parser.NewParser(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, validateApi(file))
+}
@@ -0,0 +1,359 @@
+package swaggergen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// formatRules 可转为 gvalid 规则的 OpenAPI format
+var formatRules = map[string]string{
+	"email": "email",
+	"uri":   "url",
+	"url":   "url",
+	"date":  "date",
+	"ipv4":  "ipv4",
+	"ipv6":  "ipv6",
+}
+
+type (
+	apiType struct {
+		name    string
+		members []apiMember
+	}
+
+	apiMember struct {
+		name    string
+		tp      string
+		tag     string
+		comment string
+		inline  bool
+	}
+)
+
+func (t *apiType) write(builder *strings.Builder) {
+	fmt.Fprintf(builder, "type %s struct {\n", t.name)
+	for _, m := range t.members {
+		if m.inline {
+			fmt.Fprintf(builder, "\t%s\n", m.tp)
+			continue
+		}
+
+		fmt.Fprintf(builder, "\t%s %s `%s`", m.name, m.tp, m.tag)
+		if len(m.comment) > 0 {
+			fmt.Fprintf(builder, " // %s", m.comment)
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("}\n")
+}
+
+// newType 新建命名类型，名称冲突时追加序号
+func (im *importer) newType(name string) *apiType {
+	unique := name
+	for i := 2; im.typeMap[unique] != nil; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	t := &apiType{name: unique}
+	im.types = append(im.types, t)
+	im.typeMap[unique] = t
+	return t
+}
+
+func (im *importer) dropType(t *apiType) {
+	delete(im.typeMap, t.name)
+	for i, v := range im.types {
+		if v == t {
+			im.types = append(im.types[:i], im.types[i+1:]...)
+			break
+		}
+	}
+}
+
+// refType 返回 $ref 引用的类型，对象类型的 schema 转为命名类型，其余类型直接展开
+func (im *importer) refType(ref, where string) string {
+	if tp, ok := im.refs[ref]; ok {
+		return tp
+	}
+
+	name := strings.TrimPrefix(ref, schemaPrefix)
+	schema, ok := im.doc.Components.Schemas[name]
+	if !ok || !strings.HasPrefix(ref, schemaPrefix) {
+		im.warn("%s: $ref %s, mapped to interface{}", where, ref)
+		return "interface{}"
+	}
+	if im.visiting[ref] {
+		im.warn("%s: recursive non-object schema %s, mapped to interface{}", where, ref)
+		return "interface{}"
+	}
+
+	im.visiting[ref] = true
+	defer delete(im.visiting, ref)
+
+	if !isObject(schema) || len(schema.Properties) == 0 && len(schema.AllOf) == 0 {
+		tp := im.typeOf(schema, identifier(name), "components.schemas."+name)
+		im.refs[ref] = tp
+		return tp
+	}
+
+	t := im.newType(identifier(name))
+	im.refs[ref] = t.name
+	im.addProperties(t, schema, "json", "components.schemas."+name)
+	return t.name
+}
+
+// resolve 返回 $ref 引用的 schema
+func (im *importer) resolve(schema *Schema) *Schema {
+	for i := 0; schema != nil && len(schema.Ref) > 0 && i < 8; i++ {
+		schema = im.doc.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaPrefix)]
+	}
+	return schema
+}
+
+// typeOf 返回 schema 对应的 Go 类型，内联的对象转为以 hint 命名的类型
+func (im *importer) typeOf(schema *Schema, hint, where string) string {
+	if schema == nil {
+		return "interface{}"
+	}
+	if len(schema.Ref) > 0 {
+		return im.refType(schema.Ref, where)
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 || schema.Not != nil {
+		im.warn("%s: oneOf/anyOf/not, mapped to interface{}", where)
+		return "interface{}"
+	}
+
+	switch schema.Type {
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "string":
+		return "string"
+	case "array":
+		if schema.Items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + im.typeOf(schema.Items, hint+"Item", where)
+	}
+
+	switch {
+	case len(schema.Properties) > 0 || len(schema.AllOf) > 0:
+		t := im.newType(hint)
+		im.addProperties(t, schema, "json", where)
+		return t.name
+	case schema.AdditionalProperties != nil:
+		return "map[string]" + im.typeOf(schema.AdditionalProperties, hint+"Value", where)
+	case schema.Type == "object":
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+// addProperties 把对象的属性加入类型，allOf 中引用的命名类型以内嵌方式加入
+func (im *importer) addProperties(t *apiType, schema *Schema, key, where string) {
+	for _, sub := range schema.AllOf {
+		if len(sub.Ref) > 0 && key == "json" {
+			tp := im.refType(sub.Ref, where)
+			if im.typeMap[tp] != nil {
+				t.members = append(t.members, apiMember{tp: tp, inline: true})
+				continue
+			}
+		}
+
+		resolved := im.resolve(sub)
+		if resolved == nil || !isObject(resolved) {
+			im.warn("%s: allOf with non-object schema", where)
+			continue
+		}
+		im.addProperties(t, resolved, key, where)
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		im.warn("%s: oneOf/anyOf of object properties", where)
+	}
+
+	names := schema.Order
+	if len(names) != len(schema.Properties) {
+		names = names[:0]
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		prop, ok := schema.Properties[name]
+		if !ok {
+			continue
+		}
+		if key == "form" && im.isFile(prop) {
+			im.warn("%s: file field %s, parse it with httpx.ParseFiles", where, name)
+			continue
+		}
+		im.addMember(t, name, prop, key, contains(schema.Required, name), prop.Description, where)
+	}
+}
+
+func (im *importer) addMember(t *apiType, name string, schema *Schema, key string, required bool,
+	comment, where string) {
+	field := identifier(name)
+	for i, unique := 2, field; ; i++ {
+		if !t.hasMember(unique) {
+			field = unique
+			break
+		}
+		unique = fmt.Sprintf("%s%d", field, i)
+	}
+
+	tp := im.typeOf(schema, t.name+field, where+"."+name)
+	if tp == t.name {
+		tp = "*" + tp
+	}
+
+	t.members = append(t.members, apiMember{
+		name:    field,
+		tp:      tp,
+		tag:     im.tag(name, key, im.resolve(schema), required, where),
+		comment: strings.Join(strings.Fields(comment), " "),
+	})
+}
+
+// tag 生成成员标签，enum、default、minimum/maximum 转为 mapping 选项，长度、正则和格式转为 gvalid 规则
+func (im *importer) tag(name, key string, schema *Schema, required bool, where string) string {
+	options := []string{name}
+	if !required && (schema == nil || schema.Default == nil) {
+		options = append(options, "optional")
+	}
+
+	var rules []string
+	if schema != nil {
+		if len(schema.Enum) > 0 {
+			values := make([]string, 0, len(schema.Enum))
+			for _, v := range schema.Enum {
+				values = append(values, fmt.Sprint(v))
+			}
+			if value := strings.Join(values, "|"); strings.ContainsAny(value, ",\"`") {
+				im.warn("%s.%s: enum values containing , \" or `", where, name)
+			} else {
+				options = append(options, "options="+value)
+			}
+		}
+		if schema.Default != nil {
+			if value := fmt.Sprint(schema.Default); strings.ContainsAny(value, ",\"`") {
+				im.warn("%s.%s: default value %s", where, name, value)
+			} else {
+				options = append(options, "default="+value)
+			}
+		}
+		if schema.Minimum != nil || schema.Maximum != nil {
+			options = append(options, "range="+interval(schema))
+		}
+
+		switch {
+		case schema.MinLength != nil && schema.MaxLength != nil:
+			rules = append(rules, fmt.Sprintf("length:%d,%d", *schema.MinLength, *schema.MaxLength))
+		case schema.MinLength != nil:
+			rules = append(rules, fmt.Sprintf("min-length:%d", *schema.MinLength))
+		case schema.MaxLength != nil:
+			rules = append(rules, fmt.Sprintf("max-length:%d", *schema.MaxLength))
+		}
+		if rule, ok := formatRules[schema.Format]; ok {
+			rules = append(rules, rule)
+		}
+		if len(schema.Pattern) > 0 {
+			if strings.ContainsAny(schema.Pattern, "#\"`") {
+				im.warn("%s.%s: pattern %s", where, name, schema.Pattern)
+			} else {
+				rules = append(rules, "regex:"+schema.Pattern)
+			}
+		}
+	}
+
+	tag := fmt.Sprintf(`%s:"%s"`, key, strings.Join(options, ","))
+	if len(rules) > 0 {
+		tag += fmt.Sprintf(` v:"%s"`, strings.Join(rules, "|"))
+	}
+	return tag
+}
+
+func (im *importer) isFile(schema *Schema) bool {
+	schema = im.resolve(schema)
+	if schema == nil {
+		return false
+	}
+	if schema.Type == "array" {
+		return im.isFile(schema.Items)
+	}
+	return schema.Type == "string" && schema.Format == "binary"
+}
+
+func (t *apiType) hasMember(name string) bool {
+	for _, m := range t.members {
+		if m.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isObject(schema *Schema) bool {
+	return schema.Type == "object" || len(schema.Type) == 0 && (len(schema.Properties) > 0 || len(schema.AllOf) > 0)
+}
+
+// interval 把 minimum、maximum 转为 mapping 的区间格式，如 [0:100]、(0:]
+func interval(schema *Schema) string {
+	var builder strings.Builder
+	if schema.ExclusiveMinimum {
+		builder.WriteByte('(')
+	} else {
+		builder.WriteByte('[')
+	}
+	if schema.Minimum != nil {
+		builder.WriteString(strconv.FormatFloat(*schema.Minimum, 'f', -1, 64))
+	}
+	builder.WriteByte(':')
+	if schema.Maximum != nil {
+		builder.WriteString(strconv.FormatFloat(*schema.Maximum, 'f', -1, 64))
+	}
+	if schema.ExclusiveMaximum {
+		builder.WriteByte(')')
+	} else {
+		builder.WriteByte(']')
+	}
+	return builder.String()
+}
+
+// identifier 把名称转为导出的 Go 标识符，如 user_id 转为 UserId，get /users/{id} 转为 GetUsersId
+func identifier(name string) string {
+	var builder strings.Builder
+	upper := true
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		builder.WriteRune(c)
+	}
+
+	id := builder.String()
+	if len(id) > 0 && unicode.IsDigit([]rune(id)[0]) {
+		id = "X" + id
+	}
+	return id
+}
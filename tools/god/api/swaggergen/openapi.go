@@ -0,0 +1,117 @@
+package swaggergen
+
+const openapiVersion = "3.0.3"
+
+type (
+	// OpenAPI OpenAPI 3 文档，只包含生成和导入 .api 文件用到的部分
+	OpenAPI struct {
+		OpenAPI    string                `json:"openapi"`
+		Info       Info                  `json:"info"`
+		Servers    []Server              `json:"servers,omitempty"`
+		Tags       []Tag                 `json:"tags,omitempty"`
+		Paths      map[string]PathItem   `json:"paths"`
+		Components Components            `json:"components,omitempty"`
+		Security   []map[string][]string `json:"security,omitempty"`
+	}
+
+	Info struct {
+		Title       string   `json:"title"`
+		Description string   `json:"description,omitempty"`
+		Version     string   `json:"version"`
+		Contact     *Contact `json:"contact,omitempty"`
+	}
+
+	Contact struct {
+		Name  string `json:"name,omitempty"`
+		Email string `json:"email,omitempty"`
+	}
+
+	Server struct {
+		URL         string `json:"url"`
+		Description string `json:"description,omitempty"`
+	}
+
+	Tag struct {
+		Name string `json:"name"`
+	}
+
+	// PathItem 按小写请求方法索引的操作
+	PathItem map[string]*Operation
+
+	Operation struct {
+		Tags        []string              `json:"tags,omitempty"`
+		Summary     string                `json:"summary,omitempty"`
+		Description string                `json:"description,omitempty"`
+		OperationId string                `json:"operationId,omitempty"`
+		Parameters  []Parameter           `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]Response   `json:"responses"`
+		Security    []map[string][]string `json:"security,omitempty"`
+	}
+
+	Parameter struct {
+		Ref         string  `json:"$ref,omitempty"`
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	RequestBody struct {
+		Ref      string               `json:"$ref,omitempty"`
+		Required bool                 `json:"required,omitempty"`
+		Content  map[string]MediaType `json:"content"`
+	}
+
+	Response struct {
+		Ref         string               `json:"$ref,omitempty"`
+		Description string               `json:"description"`
+		Content     map[string]MediaType `json:"content,omitempty"`
+	}
+
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	Components struct {
+		Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+		Parameters      map[string]*Parameter     `json:"parameters,omitempty"`
+		RequestBodies   map[string]*RequestBody   `json:"requestBodies,omitempty"`
+		Responses       map[string]*Response      `json:"responses,omitempty"`
+		SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	SecurityScheme struct {
+		Type         string `json:"type"`
+		Scheme       string `json:"scheme,omitempty"`
+		BearerFormat string `json:"bearerFormat,omitempty"`
+	}
+
+	// Schema JSON Schema 的 OpenAPI 子集
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+		AllOf                []*Schema          `json:"allOf,omitempty"`
+		OneOf                []*Schema          `json:"oneOf,omitempty"`
+		AnyOf                []*Schema          `json:"anyOf,omitempty"`
+		Not                  *Schema            `json:"not,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty"`
+		Default              interface{}        `json:"default,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty"`
+		ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+		MinLength            *int64             `json:"minLength,omitempty"`
+		MaxLength            *int64             `json:"maxLength,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+		// Order 导入时记录的属性声明顺序，生成文档时不输出
+		Order []string `json:"x-order,omitempty"`
+	}
+)
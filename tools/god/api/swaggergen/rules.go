@@ -0,0 +1,231 @@
+package swaggergen
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"git.zc0901.com/go/god/tools/god/api/util"
+)
+
+// validTags 校验规则的标签，优先级与 gvalid 一致
+var validTags = []string{"gvalid", "valid", "v"}
+
+// sequenceRe 校验规则格式：[alias@]rule1|rule2:args[#msg1|msg2]
+var sequenceRe = regexp.MustCompile(`^\s*(?:\w+\s*@)?\s*([^#]*)`)
+
+// ruleFormats 可直接表示为 OpenAPI format 的 gvalid 规则
+var ruleFormats = map[string]string{
+	"email": "email",
+	"url":   "uri",
+	"date":  "date",
+	"ip":    "ip",
+	"ipv4":  "ipv4",
+	"ipv6":  "ipv6",
+	"json":  "json",
+	"phone": "phone",
+}
+
+// ruleNames gvalid 支持的全部规则名
+var ruleNames = map[string]struct{}{}
+
+func init() {
+	for _, name := range strings.Fields(`required required-strict required-if required-unless required-with
+		required-with-all required-without required-without-all date date-format email phone telephone
+		passport password password2 password3 postcode resident-id bank-card qq ip ipv4 ipv6 mac url
+		domain length min-length max-length between min max json integer float boolean same different
+		in not-in regex`) {
+		ruleNames[name] = struct{}{}
+	}
+}
+
+// parseRules 解析成员的 gvalid 校验规则，返回规则名和参数的列表
+func parseRules(tag string) [][2]string {
+	var value string
+	for _, key := range validTags {
+		if v, ok := util.TagLookup(tag, key); ok {
+			value = v
+			break
+		}
+	}
+	if len(value) == 0 {
+		return nil
+	}
+
+	match := sequenceRe.FindStringSubmatch(value)
+	if len(match) < 2 || len(strings.TrimSpace(match[1])) == 0 {
+		return nil
+	}
+
+	var rules [][2]string
+	for _, item := range strings.Split(strings.TrimSpace(match[1]), "|") {
+		name, args := item, ""
+		if i := strings.Index(item, ":"); i >= 0 {
+			name, args = item[:i], item[i+1:]
+		}
+		name = strings.TrimSpace(name)
+
+		// 正则中的 | 被误拆成了多条规则，拼回去
+		if n := len(rules); n > 0 && rules[n-1][0] == "regex" && !isRuleName(name) {
+			rules[n-1][1] += "|" + item
+			continue
+		}
+		rules = append(rules, [2]string{name, args})
+	}
+
+	return rules
+}
+
+// applyRules 把校验规则转为 schema 约束，返回是否必填
+func applyRules(schema *Schema, rules [][2]string) (required bool) {
+	for _, rule := range rules {
+		name, args := rule[0], rule[1]
+		switch name {
+		case "required", "required-strict":
+			required = true
+		case "length":
+			if min, max, ok := parseRange(args); ok {
+				schema.MinLength, schema.MaxLength = intPtr(min), intPtr(max)
+			}
+		case "min-length":
+			if n, err := strconv.ParseInt(args, 10, 64); err == nil {
+				schema.MinLength = &n
+			}
+		case "max-length":
+			if n, err := strconv.ParseInt(args, 10, 64); err == nil {
+				schema.MaxLength = &n
+			}
+		case "between":
+			if min, max, ok := parseRange(args); ok {
+				schema.Minimum, schema.Maximum = &min, &max
+			}
+		case "min":
+			if n, err := strconv.ParseFloat(args, 64); err == nil {
+				schema.Minimum = &n
+			}
+		case "max":
+			if n, err := strconv.ParseFloat(args, 64); err == nil {
+				schema.Maximum = &n
+			}
+		case "in":
+			schema.Enum = enumOf(schema, strings.Split(args, ","))
+		case "regex":
+			schema.Pattern = args
+		case "integer":
+			if len(schema.Type) == 0 || schema.Type == "string" {
+				schema.Type, schema.Format = "integer", ""
+			}
+		case "float":
+			if len(schema.Type) == 0 || schema.Type == "string" {
+				schema.Type, schema.Format = "number", ""
+			}
+		case "boolean":
+			if len(schema.Type) == 0 {
+				schema.Type = "boolean"
+			}
+		default:
+			if format, ok := ruleFormats[name]; ok && schema.Type == "string" {
+				schema.Format = format
+			}
+		}
+	}
+
+	return
+}
+
+// applyOptions 把 mapping 的标签选项（options、range、default）转为 schema 约束
+func applyOptions(schema *Schema, options []string) {
+	for _, option := range options {
+		switch {
+		case strings.HasPrefix(option, "options="):
+			schema.Enum = enumOf(schema, strings.Split(strings.TrimPrefix(option, "options="), "|"))
+		case strings.HasPrefix(option, "default="):
+			schema.Default = valueOf(schema, strings.TrimPrefix(option, "default="))
+		case strings.HasPrefix(option, "range="):
+			applyInterval(schema, strings.TrimPrefix(option, "range="))
+		}
+	}
+}
+
+// applyInterval 解析 [min:max]、(min:max] 等形式的区间，边界可省略
+func applyInterval(schema *Schema, interval string) {
+	if len(interval) < 3 {
+		return
+	}
+
+	left, right := interval[0], interval[len(interval)-1]
+	if left != '[' && left != '(' || right != ']' && right != ')' {
+		return
+	}
+
+	bounds := strings.Split(interval[1:len(interval)-1], ":")
+	if len(bounds) != 2 {
+		return
+	}
+
+	if n, err := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64); err == nil {
+		schema.Minimum = &n
+		schema.ExclusiveMinimum = left == '('
+	}
+	if n, err := strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64); err == nil {
+		schema.Maximum = &n
+		schema.ExclusiveMaximum = right == ')'
+	}
+}
+
+func parseRange(args string) (min, max float64, ok bool) {
+	values := strings.Split(args, ",")
+	if len(values) != 2 {
+		return 0, 0, false
+	}
+
+	min, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	max, err = strconv.ParseFloat(strings.TrimSpace(values[1]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return min, max, true
+}
+
+func enumOf(schema *Schema, values []string) []interface{} {
+	enum := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		enum = append(enum, valueOf(schema, strings.TrimSpace(value)))
+	}
+	return enum
+}
+
+// valueOf 按 schema 类型转换标签中的字面值，转换失败时保留字符串
+func valueOf(schema *Schema, value string) interface{} {
+	switch schema.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+
+	return value
+}
+
+func intPtr(f float64) *int64 {
+	n := int64(f)
+	return &n
+}
+
+// isRuleName 判断是否为 gvalid 规则名，用于拼回正则中被拆开的部分
+func isRuleName(name string) bool {
+	_, ok := ruleNames[name]
+	return ok
+}
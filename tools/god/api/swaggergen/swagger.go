@@ -0,0 +1,98 @@
+package swaggergen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"git.zc0901.com/go/god/tools/god/api/parser"
+	"git.zc0901.com/go/god/tools/god/api/spec"
+	"git.zc0901.com/go/god/tools/god/util"
+	"git.zc0901.com/go/god/tools/god/util/console"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultOutput = "openapi.json"
+
+// SwaggerCommand 根据 API 文件生成 OpenAPI 3 文档，输出文件以 .yaml 或 .yml 结尾时为 YAML 格式，否则为 JSON 格式
+func SwaggerCommand(c *cli.Context) error {
+	apiFile := c.String("api")
+	if len(apiFile) == 0 {
+		return errors.New("missing -api")
+	}
+	output := c.String("o")
+	if len(output) == 0 {
+		output = filepath.Join(filepath.Dir(apiFile), defaultOutput)
+	}
+
+	p, err := parser.NewParser(apiFile)
+	if err != nil {
+		return err
+	}
+	api, err := p.Parse()
+	if err != nil {
+		return err
+	}
+
+	content, err := Marshal(api, isYaml(output))
+	if err != nil {
+		return err
+	}
+
+	if err := util.MkdirIfNotExist(filepath.Dir(output)); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(output, content, 0644); err != nil {
+		return err
+	}
+
+	console.NewColorConsole().MarkDone()
+	return nil
+}
+
+// Marshal 生成 API 描述对应的 OpenAPI 3 文档，asYaml 为 true 时输出 YAML 格式
+func Marshal(api *spec.ApiSpec, asYaml bool) ([]byte, error) {
+	content, err := json.MarshalIndent(Generate(api), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if !asYaml {
+		return content, nil
+	}
+
+	// 经由 yaml.Node 转换，保持与 JSON 相同的字段顺序
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resetStyle 去掉从 JSON 继承的流式和引号风格，输出块风格的 YAML
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+func isYaml(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,189 @@
+package swaggergen
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"git.zc0901.com/go/god/tools/god/api/parser"
+	"git.zc0901.com/go/god/tools/god/api/spec"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const testApi = `
+info(
+    title: user api
+    desc: user service
+    author: god
+    email: god@example.com
+    version: 2.0
+)
+
+type Base struct {
+  Tenant string ` + "`" + `header:"X-Tenant,optional"` + "`" + `
+}
+
+type UserReq struct {
+  Base
+  Id int64 ` + "`" + `path:"id"` + "`" + `
+  Name string ` + "`" + `json:"name" v:"required|length:2,20"` + "`" + `
+  Email string ` + "`" + `json:"email,optional" v:"email"` + "`" + `
+  Age int ` + "`" + `json:"age,range=[0:150]" v:"between:0,150"` + "`" + `
+  Gender string ` + "`" + `json:"gender,options=male|female,default=male"` + "`" + `
+  Code string ` + "`" + `json:"code" v:"regex:^a|b$"` + "`" + `
+}
+
+type Friend struct {
+  Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+type UserReply struct {
+  Id int64 ` + "`" + `json:"id"` + "`" + `
+  Tags []string ` + "`" + `json:"tags"` + "`" + `
+  Friends []*Friend ` + "`" + `json:"friends"` + "`" + `
+  Extra map[string]interface{} ` + "`" + `json:"extra,omitempty"` + "`" + `
+}
+
+type ListReq struct {
+  Page int ` + "`" + `form:"page,default=1" v:"min:1"` + "`" + `
+  Size int ` + "`" + `form:"size,optional" v:"max:100"` + "`" + `
+}
+
+type UploadReq struct {
+  Title string ` + "`" + `form:"title"` + "`" + `
+  Avatar File ` + "`" + `form:"avatar,file"` + "`" + `
+}
+
+type File struct {
+  Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+@server(
+  jwt: Auth
+  group: user
+  prefix: /v1
+)
+service user-api {
+  @doc(
+    summary: 更新用户
+  )
+  @handler UpdateUser
+  post /users/:id (UserReq) returns (UserReply)
+
+  @handler ListUsers
+  get /users (ListReq) returns (UserReply)
+
+  @handler Upload
+  post /upload (UploadReq)
+}
+`
+
+func generate(t *testing.T) *OpenAPI {
+	file := filepath.Join(t.TempDir(), "user.api")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(testApi), 0644))
+	p, err := parser.NewParser(file)
+	assert.Nil(t, err)
+	api, err := p.Parse()
+	assert.Nil(t, err)
+
+	return Generate(api)
+}
+
+func TestGenerate(t *testing.T) {
+	doc := generate(t)
+	assert.Equal(t, openapiVersion, doc.OpenAPI)
+	assert.Equal(t, "user api", doc.Info.Title)
+	assert.Equal(t, "2.0", doc.Info.Version)
+	assert.Equal(t, "god@example.com", doc.Info.Contact.Email)
+	assert.Equal(t, []Tag{{Name: "user"}}, doc.Tags)
+	assert.Equal(t, "bearer", doc.Components.SecuritySchemes[jwtSecurity].Scheme)
+
+	update := doc.Paths["/v1/users/{id}"]["post"]
+	assert.NotNil(t, update)
+	assert.Equal(t, "UpdateUser", update.OperationId)
+	assert.Equal(t, "更新用户", update.Summary)
+	assert.Equal(t, []string{"user"}, update.Tags)
+	assert.Equal(t, []map[string][]string{{jwtSecurity: {}}}, update.Security)
+	assert.Equal(t, []Parameter{
+		{Name: "X-Tenant", In: inHeader, Schema: &Schema{Type: "string"}},
+		{Name: "id", In: inPath, Required: true, Schema: &Schema{Type: "integer", Format: "int64"}},
+	}, update.Parameters)
+
+	body := update.RequestBody.Content[jsonContentType].Schema
+	assert.Equal(t, []string{"name", "age", "code"}, body.Required)
+	name := body.Properties["name"]
+	assert.Equal(t, int64(2), *name.MinLength)
+	assert.Equal(t, int64(20), *name.MaxLength)
+	assert.Equal(t, "email", body.Properties["email"].Format)
+	age := body.Properties["age"]
+	assert.Equal(t, float64(0), *age.Minimum)
+	assert.Equal(t, float64(150), *age.Maximum)
+	gender := body.Properties["gender"]
+	assert.Equal(t, []interface{}{"male", "female"}, gender.Enum)
+	assert.Equal(t, "male", gender.Default)
+	assert.Equal(t, "^a|b$", body.Properties["code"].Pattern)
+
+	assert.Equal(t, schemaRefPrefix+"UserReply", update.Responses[okStatus].Content[jsonContentType].Schema.Ref)
+	reply := doc.Components.Schemas["UserReply"]
+	assert.Equal(t, []string{"id", "tags", "friends"}, reply.Required)
+	assert.Equal(t, "string", reply.Properties["tags"].Items.Type)
+	assert.Equal(t, schemaRefPrefix+"Friend", reply.Properties["friends"].Items.Ref)
+	assert.Equal(t, &Schema{}, reply.Properties["extra"].AdditionalProperties)
+	assert.NotNil(t, doc.Components.Schemas["Friend"])
+
+	list := doc.Paths["/v1/users"]["get"]
+	assert.Nil(t, list.RequestBody)
+	assert.Len(t, list.Parameters, 2)
+	page := list.Parameters[0]
+	assert.Equal(t, inQuery, page.In)
+	assert.False(t, page.Required)
+	assert.Equal(t, int64(1), page.Schema.Default)
+	assert.Equal(t, float64(1), *page.Schema.Minimum)
+	assert.Equal(t, float64(100), *list.Parameters[1].Schema.Maximum)
+
+	upload := doc.Paths["/v1/upload"]["post"]
+	assert.Equal(t, okDescription, upload.Responses[okStatus].Description)
+	form := upload.RequestBody.Content[multipartType].Schema
+	assert.Equal(t, "binary", form.Properties["avatar"].Format)
+	assert.Equal(t, []string{"title", "avatar"}, form.Required)
+}
+
+func TestMarshal(t *testing.T) {
+	api := &spec.ApiSpec{
+		Service: spec.Service{
+			Name: "demo-api",
+			Groups: []spec.Group{{
+				Routes: []spec.Route{{Method: "get", Path: "/ping"}},
+			}},
+		},
+	}
+
+	content, err := Marshal(api, false)
+	assert.Nil(t, err)
+	var doc OpenAPI
+	assert.Nil(t, json.Unmarshal(content, &doc))
+	assert.Equal(t, "demo-api", doc.Info.Title)
+	assert.Equal(t, defaultVersion, doc.Info.Version)
+	assert.Equal(t, []string{"demo-api"}, doc.Paths["/ping"]["get"].Tags)
+
+	content, err = Marshal(api, true)
+	assert.Nil(t, err)
+	var m map[string]interface{}
+	assert.Nil(t, yaml.Unmarshal(content, &m))
+	assert.Equal(t, openapiVersion, m["openapi"])
+	assert.Contains(t, string(content), `"200":`)
+}
+
+func TestParseRules(t *testing.T) {
+	assert.Nil(t, parseRules("`json:\"name\"`"))
+	assert.Equal(t, [][2]string{{"required", ""}, {"regex", `^\d+|abc$`}, {"max-length", "10"}},
+		parseRules("`json:\"name\" valid:\"name@required|regex:^\\\\d+|abc$|max-length:10#名称不能为空\"`"))
+
+	schema := &Schema{Type: "integer"}
+	applyOptions(schema, []string{"range=(0:10]", "options=1|2"})
+	assert.True(t, schema.ExclusiveMinimum)
+	assert.False(t, schema.ExclusiveMaximum)
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, schema.Enum)
+}
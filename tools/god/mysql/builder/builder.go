@@ -48,6 +48,15 @@ func ToMap(in interface{}) map[string]interface{} {
 
 // FieldList 返回表的字段列表
 func FieldList(table interface{}) []string {
+	return quotedFieldList(table, "`")
+}
+
+// PgFieldList 返回表的字段列表，字段名使用 PostgreSQL 的双引号包裹
+func PgFieldList(table interface{}) []string {
+	return quotedFieldList(table, `"`)
+}
+
+func quotedFieldList(table interface{}, quote string) []string {
 	out := make([]string, 0)
 	v := reflect.ValueOf(table)
 	if v.Kind() == reflect.Ptr {
@@ -62,9 +71,9 @@ func FieldList(table interface{}) []string {
 		// gets us a StructField
 		fi := typ.Field(i)
 		if tagv := fi.Tag.Get(dbTag); tagv != "" {
-			out = append(out, fmt.Sprintf("`%v`", tagv))
+			out = append(out, quote+tagv+quote)
 		} else {
-			out = append(out, fmt.Sprintf("`%v`", fi.Name))
+			out = append(out, quote+fi.Name+quote)
 		}
 	}
 	return out
@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PostgreSQL 的 udt_name 与 Go 类型的对应关系
+var pgTypes = map[string]string{
+	// number
+	"bool":    "bool",
+	"int2":    "int64",
+	"int4":    "int64",
+	"int8":    "int64",
+	"float4":  "float64",
+	"float8":  "float64",
+	"numeric": "float64",
+	"money":   "string",
+
+	// date&time
+	"date":        "time.Time",
+	"timestamp":   "time.Time",
+	"timestamptz": "time.Time",
+	"time":        "string",
+	"timetz":      "string",
+	"interval":    "string",
+
+	// string
+	"bpchar":  "string",
+	"char":    "string",
+	"varchar": "string",
+	"text":    "string",
+	"citext":  "string",
+	"name":    "string",
+	"uuid":    "string",
+	"json":    "string",
+	"jsonb":   "string",
+	"xml":     "string",
+	"inet":    "string",
+	"cidr":    "string",
+	"macaddr": "string",
+	"bytea":   "string",
+}
+
+// ConvertPgDataType 将 PostgreSQL 的 udt_name 转为 Go 类型，数组类型（以 _ 开头）按文本形式处理
+func ConvertPgDataType(udtName string, isDefaultNull bool) (string, error) {
+	name := strings.ToLower(udtName)
+	if strings.HasPrefix(name, "_") {
+		return mayConvertNullType("string", isDefaultNull), nil
+	}
+
+	tp, ok := pgTypes[name]
+	if !ok {
+		return "", fmt.Errorf("不识别的数据库类型: %s", udtName)
+	}
+	return mayConvertNullType(tp, isDefaultNull), nil
+}
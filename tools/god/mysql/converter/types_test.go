@@ -23,3 +23,24 @@ func TestConvertDataType(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "sql.NullTime", v)
 }
+
+func TestConvertPgDataType(t *testing.T) {
+	v, err := converter.ConvertPgDataType("int8", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "int64", v)
+
+	v, err = converter.ConvertPgDataType("bool", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "sqlx.NullBool", v)
+
+	v, err = converter.ConvertPgDataType("timestamptz", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "sqlx.NullTime", v)
+
+	v, err = converter.ConvertPgDataType("_int4", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "string", v)
+
+	_, err = converter.ConvertPgDataType("tsvector", false)
+	assert.NotNil(t, err)
+}
@@ -22,7 +22,9 @@ type (
 	ModelGenerator struct {
 		database string
 		ddlList  []string
+		tables   []parser.Table
 		dir      string
+		postgres bool
 		util.Console
 	}
 
@@ -31,6 +33,7 @@ type (
 	Table struct {
 		parser.Table
 		CacheKeys map[string]Key
+		Postgres  bool
 	}
 )
 
@@ -48,6 +51,13 @@ func NewModelGenerator(ddlList []string, dir string, opts ...Option) *ModelGener
 	return generator
 }
 
+// NewModelGeneratorFromTables 从已解析的表结构新建模型生成器，用于非 MySQL DDL 的数据源
+func NewModelGeneratorFromTables(tables []parser.Table, dir string, opts ...Option) *ModelGenerator {
+	generator := NewModelGenerator(nil, dir, opts...)
+	generator.tables = tables
+	return generator
+}
+
 func newDefaultOption() Option {
 	return func(gen *ModelGenerator) {
 		gen.Console = util.NewColorConsole()
@@ -66,6 +76,13 @@ func WithDatabaseOption(database string) Option {
 	}
 }
 
+// WithPostgresOption 生成 PostgreSQL 方言的模型代码
+func WithPostgresOption() Option {
+	return func(gen *ModelGenerator) {
+		gen.postgres = true
+	}
+}
+
 func (g *ModelGenerator) Start(withCache bool) error {
 	dir, err := filepath.Abs(g.dir)
 	if err != nil {
@@ -122,6 +139,13 @@ func (g *ModelGenerator) genFromDDL(withCache bool) (map[string]string, error) {
 		}
 		m[table.Name.Source()] = modelCode
 	}
+	for _, table := range g.tables {
+		modelCode, err := g.genModelCode(table, withCache)
+		if err != nil {
+			return nil, err
+		}
+		m[table.Name.Source()] = modelCode
+	}
 	return m, nil
 }
 
@@ -135,9 +159,10 @@ func (g *ModelGenerator) genModelCode(table parser.Table, withCache bool) (strin
 	var tableDTO Table
 	tableDTO.Table = table
 	tableDTO.CacheKeys = cacheKeys
+	tableDTO.Postgres = g.postgres
 
 	// 生成导包代码
-	importsCode, err := genImports(withCache, tableDTO.ContainsTime())
+	importsCode, err := genImports(withCache, tableDTO.ContainsTime(), tableDTO.Postgres)
 	if err != nil {
 		return "", nil
 	}
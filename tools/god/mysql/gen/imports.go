@@ -5,7 +5,8 @@ import (
 	"git.zc0901.com/go/god/tools/god/util"
 )
 
-func genImports(withCache, timeImport bool) (string, error) {
+// genImports 生成导包代码，PostgreSQL 模型的插入方法返回主键，无缓存时不再用到 database/sql
+func genImports(withCache, timeImport, postgres bool) (string, error) {
 	if withCache {
 		buffer, err := util.With("import").Parse(tpl.Imports).Execute(map[string]interface{}{
 			"time": timeImport,
@@ -16,7 +17,8 @@ func genImports(withCache, timeImport bool) (string, error) {
 		return buffer.String(), nil
 	} else {
 		buffer, err := util.With("import").Parse(tpl.ImportsNoCache).Execute(map[string]interface{}{
-			"time": timeImport,
+			"time":     timeImport,
+			"postgres": postgres,
 		})
 		if err != nil {
 			return "", err
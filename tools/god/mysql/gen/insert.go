@@ -25,12 +25,19 @@ func genInsert(table Table, withCache bool) (string, error) {
 		values = append(values, "data."+camelField)
 	}
 	upperTable := table.Name.ToCamel()
-	output, err := util.With("insert").Parse(tpl.Insert).Execute(map[string]interface{}{
-		"withCache":  withCache,
-		"upperTable": upperTable,
-		"lowerTable": stringx.From(upperTable).UnTitle(),
-		"args":       strings.Join(args, ", "),
-		"values":     strings.Join(values, ", "),
+	text := tpl.Insert
+	if table.Postgres {
+		text = tpl.PgInsert
+	}
+	output, err := util.With("insert").Parse(text).Execute(map[string]interface{}{
+		"withCache":                 withCache,
+		"upperTable":                upperTable,
+		"lowerTable":                stringx.From(upperTable).UnTitle(),
+		"args":                      strings.Join(args, ", "),
+		"values":                    strings.Join(values, ", "),
+		"originalPrimaryKey":        table.PrimaryKey.Name.Source(),
+		"lowerStartCamelPrimaryKey": stringx.From(table.PrimaryKey.Name.ToCamel()).UnTitle(),
+		"primaryKeyType":            table.PrimaryKey.DataType,
 	})
 	if err != nil {
 		return "", err
@@ -55,11 +62,18 @@ func genTxInsert(table Table, withCache bool) (string, error) {
 		values = append(values, "data."+camelField)
 	}
 	upperTable := table.Name.ToCamel()
-	output, err := util.With("insert").Parse(tpl.TxInsert).Execute(map[string]interface{}{
-		"upperTable": upperTable,
-		"lowerTable": stringx.From(upperTable).UnTitle(),
-		"args":       strings.Join(args, ", "),
-		"values":     strings.Join(values, ", "),
+	text := tpl.TxInsert
+	if table.Postgres {
+		text = tpl.PgTxInsert
+	}
+	output, err := util.With("insert").Parse(text).Execute(map[string]interface{}{
+		"upperTable":                upperTable,
+		"lowerTable":                stringx.From(upperTable).UnTitle(),
+		"args":                      strings.Join(args, ", "),
+		"values":                    strings.Join(values, ", "),
+		"originalPrimaryKey":        table.PrimaryKey.Name.Source(),
+		"lowerStartCamelPrimaryKey": stringx.From(table.PrimaryKey.Name.ToCamel()).UnTitle(),
+		"primaryKeyType":            table.PrimaryKey.DataType,
 	})
 	if err != nil {
 		return "", err
@@ -6,9 +6,15 @@ import (
 )
 
 func genNew(table Table, withCache bool) (string, error) {
+	// PostgreSQL 表名加双引号，避免与 user 等保留字冲突
+	originTable := table.Name.Source()
+	if table.Postgres {
+		originTable = `\"` + originTable + `\"`
+	}
+
 	output, err := util.With("new").Parse(tpl.New).Execute(map[string]interface{}{
 		"withCache":   withCache,
-		"originTable": table.Name.Source(),
+		"originTable": originTable,
 		"table":       table.Name.ToCamel(),
 	})
 	if err != nil {
@@ -58,6 +58,7 @@ func genUpdatePartial(table Table, withCache bool) (string, error) {
 			"primaryKeyName":     table.CacheKeys[table.PrimaryKey.Name.Source()].KeyName,
 			"lowerTable":         stringx.From(upperTable).UnTitle(),
 			"originalPrimaryKey": table.PrimaryKey.Name.Source(),
+			"extractUpdateArgs":  extractUpdateArgs(table),
 		})
 	if err != nil {
 		return "", nil
@@ -115,9 +116,19 @@ func genTxUpdatePartial(table Table, withCache bool) (string, error) {
 			"primaryKeyName":     table.CacheKeys[table.PrimaryKey.Name.Source()].KeyName,
 			"lowerTable":         stringx.From(upperTable).UnTitle(),
 			"originalPrimaryKey": table.PrimaryKey.Name.Source(),
+			"extractUpdateArgs":  extractUpdateArgs(table),
 		})
 	if err != nil {
 		return "", nil
 	}
 	return output.String(), nil
 }
+
+// extractUpdateArgs 按数据库方言选择局部更新参数的提取方法
+func extractUpdateArgs(table Table) string {
+	if table.Postgres {
+		return "ExtractPgUpdateArgs"
+	}
+
+	return "ExtractUpdateArgs"
+}
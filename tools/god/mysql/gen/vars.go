@@ -14,6 +14,10 @@ func genVars(table Table, withCache bool) (string, error) {
 		keys = append(keys, key.Pattern)
 	}
 	camel := table.Name.ToCamel()
+	fieldList, removeFields := "FieldList", "RemoveDBFields"
+	if table.Postgres {
+		fieldList, removeFields = "PgFieldList", "RemovePgDBFields"
+	}
 	output, err := util.With("var").
 		Parse(tpl.Vars).
 		GoFmt(true).
@@ -24,6 +28,8 @@ func genVars(table Table, withCache bool) (string, error) {
 			"autoIncrement": table.PrimaryKey.AutoIncrement,
 			"primaryKey":    table.PrimaryKey.Name.Source(),
 			"withCache":     withCache,
+			"fieldList":     fieldList,
+			"removeFields":  removeFields,
 		})
 	if err != nil {
 		return "", err
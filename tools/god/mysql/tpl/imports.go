@@ -23,7 +23,7 @@ var (
 `
 
 	ImportsNoCache = `import (
-	"database/sql"
+	{{if not .postgres}}"database/sql"{{end}}
 	"sort"
 	"strings"
 	{{if .time}}"time"{{end}}
@@ -31,6 +31,7 @@ var (
 	"git.zc0901.com/go/god/lib/container/garray"
 	"git.zc0901.com/go/god/lib/gconv"
 	"git.zc0901.com/go/god/lib/gutil"
+	"git.zc0901.com/go/god/lib/logx"
 	"git.zc0901.com/go/god/lib/mathx"
 	"git.zc0901.com/go/god/lib/mr"
 	"git.zc0901.com/go/god/lib/g"
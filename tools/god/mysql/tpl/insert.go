@@ -13,3 +13,22 @@ func (m *{{.upperTable}}Model) TxInsert(tx sqlx.TxSession, data {{.upperTable}})
 	return tx.Exec(query, {{.values}})
 }
 `
+
+// PgInsert PostgreSQL 驱动不支持 LastInsertId，通过 returning 返回主键
+var PgInsert = `
+func (m *{{.upperTable}}Model) Insert(data {{.upperTable}}) ({{.primaryKeyType}}, error) {
+	query := ` + "`" + `insert into ` + "`" + ` + m.table + ` + "` (` + " + `{{.lowerTable}}FieldsAutoSet` + " + `) values ({{.args}}) returning {{.originalPrimaryKey}}` " + `
+	var {{.lowerStartCamelPrimaryKey}} {{.primaryKeyType}}
+	err := m.{{if .withCache}}QueryNoCache{{else}}conn.Query{{end}}(&{{.lowerStartCamelPrimaryKey}}, query, {{.values}})
+	return {{.lowerStartCamelPrimaryKey}}, err
+}
+`
+
+var PgTxInsert = `
+func (m *{{.upperTable}}Model) TxInsert(tx sqlx.TxSession, data {{.upperTable}}) ({{.primaryKeyType}}, error) {
+	query := ` + "`" + `insert into ` + "`" + ` + m.table + ` + "` (` + " + `{{.lowerTable}}FieldsAutoSet` + " + `) values ({{.args}}) returning {{.originalPrimaryKey}}` " + `
+	var {{.lowerStartCamelPrimaryKey}} {{.primaryKeyType}}
+	err := tx.Query(&{{.lowerStartCamelPrimaryKey}}, query, {{.values}})
+	return {{.lowerStartCamelPrimaryKey}}, err
+}
+`
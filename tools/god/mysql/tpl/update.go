@@ -14,7 +14,7 @@ func (m *{{.upperTable}}Model) Update(data {{.upperTable}}) error {
 
 var UpdatePartial = `
 func (m *{{.upperTable}}Model) UpdatePartial(data g.Map) error {
-	updateArgs, err := sqlx.ExtractUpdateArgs({{.lowerTable}}FieldList, data)
+	updateArgs, err := sqlx.{{.extractUpdateArgs}}({{.lowerTable}}FieldList, data)
 	if err != nil {
 		return err
 	}
@@ -43,7 +43,7 @@ func (m *{{.upperTable}}Model) TxUpdate(tx sqlx.TxSession, data {{.upperTable}})
 
 var TxUpdatePartial = `
 func (m *{{.upperTable}}Model) TxUpdatePartial(tx sqlx.TxSession, data g.Map) error {
-	updateArgs, err := sqlx.ExtractUpdateArgs({{.lowerTable}}FieldList, data)
+	updateArgs, err := sqlx.{{.extractUpdateArgs}}({{.lowerTable}}FieldList, data)
 	if err != nil {
 		return err
 	}
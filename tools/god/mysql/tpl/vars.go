@@ -2,10 +2,10 @@ package tpl
 
 var Vars = `
 var (
-	{{.table}}FieldList          = builder.FieldList(&{{.camelTable}}{})
+	{{.table}}FieldList          = builder.{{.fieldList}}(&{{.camelTable}}{})
 	{{.table}}Fields                = strings.Join({{.table}}FieldList, ",")
-	{{.table}}FieldsAutoSet         = strings.Join(stringx.RemoveDBFields({{.table}}FieldList, {{if .autoIncrement}}"{{.primaryKey}}",{{end}} "created_at", "updated_at", "create_time", "update_time"), ",")
-	{{.table}}FieldsWithPlaceHolder = strings.Join(stringx.RemoveDBFields({{.table}}FieldList, "{{.primaryKey}}", "created_at", "updated_at", "create_time", "update_time"), "=?,") + "=?"
+	{{.table}}FieldsAutoSet         = strings.Join(stringx.{{.removeFields}}({{.table}}FieldList, {{if .autoIncrement}}"{{.primaryKey}}",{{end}} "created_at", "updated_at", "create_time", "update_time"), ",")
+	{{.table}}FieldsWithPlaceHolder = strings.Join(stringx.{{.removeFields}}({{.table}}FieldList, "{{.primaryKey}}", "created_at", "updated_at", "create_time", "update_time"), "=?,") + "=?"
 
 	{{if .withCache}}{{.cacheKeys}}{{end}}
 )
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"git.zc0901.com/go/god/lib/store/sqlx"
+	"git.zc0901.com/go/god/lib/stringx"
+	"git.zc0901.com/go/god/tools/god/mysql/converter"
+	"git.zc0901.com/go/god/tools/god/mysql/parser"
+)
+
+const (
+	defaultSchema = "public"
+
+	primaryKey = "PRIMARY KEY"
+	uniqueKey  = "UNIQUE"
+
+	columnsQuery = `select c.column_name, c.udt_name, c.is_nullable, c.column_default, c.is_identity,
+	col_description(format('%I.%I', c.table_schema, c.table_name)::regclass, c.ordinal_position) as column_comment
+from information_schema.columns c
+where c.table_schema = $1 and c.table_name = $2
+order by c.ordinal_position`
+
+	keysQuery = `select tc.constraint_name, tc.constraint_type, kcu.column_name
+from information_schema.table_constraints tc
+join information_schema.key_column_usage kcu
+	on kcu.constraint_schema = tc.constraint_schema and kcu.constraint_name = tc.constraint_name
+where tc.table_schema = $1 and tc.table_name = $2 and tc.constraint_type in ('PRIMARY KEY', 'UNIQUE')
+order by tc.constraint_name, kcu.ordinal_position`
+)
+
+var errPrimaryKey = errors.New("主键异常")
+
+type (
+	Model struct {
+		conn sqlx.Conn
+	}
+
+	// Column information_schema.columns 中的字段信息
+	Column struct {
+		Name       string         `db:"column_name"`
+		UdtName    string         `db:"udt_name"`
+		IsNullable string         `db:"is_nullable"`
+		Default    sql.NullString `db:"column_default"`
+		IsIdentity string         `db:"is_identity"`
+		Comment    sql.NullString `db:"column_comment"`
+	}
+
+	// Constraint information_schema 中的主键和唯一约束
+	Constraint struct {
+		Name   string `db:"constraint_name"`
+		Type   string `db:"constraint_type"`
+		Column string `db:"column_name"`
+	}
+)
+
+func NewModel(conn sqlx.Conn) *Model {
+	return &Model{conn: conn}
+}
+
+// ShowTables 读取表结构，表名可带模式前缀，如 "audit.log"，默认为 public
+func (m *Model) ShowTables(tables ...string) ([]parser.Table, error) {
+	var list []parser.Table
+	for _, table := range tables {
+		schema, name := defaultSchema, table
+		if pos := strings.IndexByte(table, '.'); pos > 0 {
+			schema, name = table[:pos], table[pos+1:]
+		}
+
+		var columns []Column
+		if err := m.conn.Query(&columns, columnsQuery, schema, name); err != nil {
+			return nil, err
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("表 %s 不存在", table)
+		}
+
+		var constraints []Constraint
+		if err := m.conn.Query(&constraints, keysQuery, schema, name); err != nil && err != sqlx.ErrNotFound {
+			return nil, err
+		}
+
+		t, err := BuildTable(name, columns, constraints)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *t)
+	}
+	return list, nil
+}
+
+// BuildTable 将字段和约束信息转为与 MySQL DDL 解析结果一致的表结构
+func BuildTable(name string, columns []Column, constraints []Constraint) (*parser.Table, error) {
+	// 复合约束无法生成按字段查找的方法，与 MySQL 一样跳过复合唯一键
+	columnsOfConstraint := make(map[string][]Constraint)
+	for _, c := range constraints {
+		columnsOfConstraint[c.Name] = append(columnsOfConstraint[c.Name], c)
+	}
+
+	keyMap := make(map[string]string)
+	for _, cs := range columnsOfConstraint {
+		if cs[0].Type == primaryKey {
+			if len(cs) > 1 {
+				return nil, errPrimaryKey
+			}
+			keyMap[cs[0].Column] = primaryKey
+			continue
+		}
+		if len(cs) > 1 {
+			continue
+		}
+
+		camelColumnName := stringx.From(cs[0].Column).ToCamel()
+		// 默认不使用 createdAt|updatedAt
+		if camelColumnName == "CreatedAt" || camelColumnName == "UpdatedAt" || camelColumnName == "CreateTime" || camelColumnName == "UpdateTime" {
+			continue
+		}
+		if _, ok := keyMap[cs[0].Column]; !ok {
+			keyMap[cs[0].Column] = uniqueKey
+		}
+	}
+
+	var fields []parser.Field
+	var primary parser.Primary
+	for _, column := range columns {
+		// 可空且无默认值的字段使用 sqlx.NullXxx 类型
+		isDefaultNull := column.IsNullable == "YES" && !column.Default.Valid
+		dataType, err := converter.ConvertPgDataType(column.UdtName, isDefaultNull)
+		if err != nil {
+			return nil, err
+		}
+
+		var field parser.Field
+		field.Name = stringx.From(column.Name)
+		field.DataBaseType = column.UdtName
+		field.DataType = dataType
+		field.Comment = column.Comment.String
+		if key, ok := keyMap[column.Name]; ok {
+			field.IsKey = true
+			field.IsPrimaryKey = key == primaryKey
+			field.IsUniqueKey = key == uniqueKey
+			if field.IsPrimaryKey {
+				primary.Field = field
+				primary.AutoIncrement = column.IsIdentity == "YES" ||
+					strings.HasPrefix(column.Default.String, "nextval(")
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	return &parser.Table{
+		Name:       stringx.From(name),
+		PrimaryKey: primary,
+		Fields:     fields,
+	}, nil
+}
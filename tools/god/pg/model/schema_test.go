@@ -0,0 +1,86 @@
+package model
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"git.zc0901.com/go/god/tools/god/mysql/gen"
+	"git.zc0901.com/go/god/tools/god/mysql/parser"
+	"git.zc0901.com/go/god/tools/god/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testColumns = []Column{
+		{Name: "id", UdtName: "int8", IsNullable: "NO", IsIdentity: "YES"},
+		{Name: "name", UdtName: "varchar", IsNullable: "NO", Comment: sql.NullString{String: "用户名称", Valid: true}},
+		{Name: "vip", UdtName: "bool", IsNullable: "YES"},
+		{Name: "create_time", UdtName: "timestamptz", IsNullable: "NO", Default: sql.NullString{String: "now()", Valid: true}},
+	}
+	testConstraints = []Constraint{
+		{Name: "user_pkey", Type: "PRIMARY KEY", Column: "id"},
+		{Name: "user_name_key", Type: "UNIQUE", Column: "name"},
+	}
+)
+
+func TestBuildTable(t *testing.T) {
+	table, err := BuildTable("user", testColumns, testConstraints)
+	assert.Nil(t, err)
+	assert.Equal(t, "user", table.Name.Source())
+	assert.Equal(t, "id", table.PrimaryKey.Name.Source())
+	assert.True(t, table.PrimaryKey.AutoIncrement)
+	assert.True(t, table.Fields[1].IsUniqueKey)
+	assert.Equal(t, "用户名称", table.Fields[1].Comment)
+	assert.Equal(t, "sqlx.NullBool", table.Fields[2].DataType)
+	assert.True(t, table.ContainsTime())
+
+	_, err = BuildTable("user", testColumns, []Constraint{
+		{Name: "user_pkey", Type: "PRIMARY KEY", Column: "id"},
+		{Name: "user_pkey", Type: "PRIMARY KEY", Column: "name"},
+	})
+	assert.Equal(t, errPrimaryKey, err)
+}
+
+func TestGenPostgresModel(t *testing.T) {
+	table, err := BuildTable("user", testColumns, testConstraints)
+	assert.Nil(t, err)
+
+	for _, withCache := range []bool{true, false} {
+		// 生成在模块内，以便编译生成的代码
+		dir, err := ioutil.TempDir(".", "god-pg")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		generator := gen.NewModelGeneratorFromTables([]parser.Table{*table}, dir,
+			gen.WithConsoleOption(util.NewConsole(true)), gen.WithPostgresOption())
+		assert.Nil(t, generator.Start(withCache))
+
+		out, err := exec.Command("go", "build", "./"+dir).CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+
+	dir, err := ioutil.TempDir(".", "god-pg")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	generator := gen.NewModelGeneratorFromTables([]parser.Table{*table}, dir,
+		gen.WithConsoleOption(util.NewConsole(true)), gen.WithPostgresOption())
+	assert.Nil(t, generator.Start(true))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "user.go"))
+	assert.Nil(t, err)
+	code := string(content)
+	assert.Contains(t, code, "builder.PgFieldList(&User{})")
+	assert.Contains(t, code, "stringx.RemovePgDBFields(")
+	assert.Contains(t, code, "sqlx.ExtractPgUpdateArgs(")
+	assert.Contains(t, code, `table:      "\"user\""`)
+	// lib/pq 不支持 LastInsertId，插入后通过 returning 返回主键
+	assert.Contains(t, code, "func (m *UserModel) Insert(data User) (int64, error)")
+	assert.Contains(t, code, "values (?, ?) returning id`")
+	assert.Contains(t, code, "err := m.QueryNoCache(&id, query, data.Name, data.Vip)")
+	assert.Contains(t, code, "err := tx.Query(&id, query, data.Name, data.Vip)")
+}